@@ -5,6 +5,7 @@ go 1.25.0
 require (
 	github.com/pdfcpu/pdfcpu v0.11.0
 	github.com/signintech/gopdf v0.33.0
+	golang.org/x/image v0.31.0
 )
 
 require (
@@ -16,7 +17,6 @@ require (
 	github.com/pkg/errors v0.9.1 // indirect
 	github.com/rivo/uniseg v0.4.7 // indirect
 	golang.org/x/crypto v0.42.0 // indirect
-	golang.org/x/image v0.31.0 // indirect
 	golang.org/x/text v0.29.0 // indirect
 	gopkg.in/yaml.v2 v2.4.0 // indirect
 )
@@ -2,7 +2,21 @@ package main
 
 import (
 	"fmt"
+	"image/color"
 	"log"
+	"math"
+	"os"
+
+	"pdf-tutorial/gopdf/barcode"
+	"pdf-tutorial/gopdf/chart"
+	"pdf-tutorial/gopdf/flow"
+	"pdf-tutorial/gopdf/fonts"
+	"pdf-tutorial/gopdf/imaging"
+	"pdf-tutorial/gopdf/page"
+	"pdf-tutorial/gopdf/shape"
+	"pdf-tutorial/gopdf/svg"
+	"pdf-tutorial/gopdf/table"
+	"pdf-tutorial/gopdf/units"
 
 	"github.com/signintech/gopdf"
 )
@@ -20,16 +34,11 @@ func main() {
 	pdf := gopdf.GoPdf{}
 	pdf.Start(gopdf.Config{PageSize: *gopdf.PageSizeA4})
 
-	// Add font with proper fallback
-	fontName := "arial"
-	err := pdf.AddTTFFont(fontName, "./fonts/arial.ttf")
+	// Add font: the embedded Liberation Sans has the same metrics as Arial
+	// and needs no font files on the machine
+	err := fonts.Register(&pdf, "arial", fonts.Sans, "Regular")
 	if err != nil {
-		// Try system font on Windows
-		err = pdf.AddTTFFont(fontName, "C:/Windows/Fonts/arial.ttf")
-		if err != nil {
-			// Use built-in PostScript font as final fallback
-			fontName = "Times-Roman"
-		}
+		log.Fatal(err)
 	}
 
 	// Example 1: Adding Images
@@ -41,10 +50,13 @@ func main() {
 	// Example 3: Creating Tables and Grids
 	createTableExample(&pdf)
 
-	// Example 4: Headers and Footers
+	// Example 4: Charts and Graphs
+	createChartsExample(&pdf)
+
+	// Example 5: Headers and Footers
 	addHeaderFooterExample(&pdf)
 
-	// Example 5: Page Numbering
+	// Example 6: Page Numbering
 	addPageNumberingExample(&pdf)
 }
 
@@ -57,20 +69,61 @@ func addImagesExample(pdf *gopdf.GoPdf) {
 	pdf.Cell(nil, "Example 1: Adding Images")
 	pdf.Br(30)
 
-	// Add PNG image
-	// Parameters: path, x, y, optional rect (width, height)
-	err := pdf.Image("images/photo.jpg", 50, 100, &gopdf.Rect{W: 150, H: 100})
+	// Read the photo: the cache hands out one image for the same content,
+	// which the PDF then embeds once however often it is placed; os.DirFS
+	// may as well be an embed.FS
+	reg := fonts.NewRegistry(pdf)
+	images := imaging.NewCache()
+	photo, err := images.OpenFS(os.DirFS("images"), "photo.jpg")
+	coverBox := page.Box{X: 50, Y: 250, W: 200, H: 150}
+	coverOpts := imaging.Options{
+		Fit:     imaging.Cover,
+		Border:  0.5,
+		Caption: "Cover: the box is filled and the sides are clipped",
+	}
+	if err == nil {
+		// A photo straight from a camera has far more pixels than the page
+		// shows: downsample it to 150 DPI for the largest place it is drawn,
+		// and draw the smaller copy everywhere
+		var report imaging.Report
+		photo, report, err = photo.Preprocess(reg, coverBox, coverOpts, imaging.PreprocessOptions{MaxDPI: 150, Quality: 80})
+		if err == nil {
+			fmt.Println("Photo preprocessed:", report)
+		}
+	}
+
+	// Add an image that keeps its proportions: Contain, the default, fits
+	// all of it in the box, centered
+	if err == nil {
+		_, err = photo.Draw(reg, page.Box{X: 50, Y: 100, W: 150, H: 100}, imaging.Options{})
+	}
 	if err != nil {
-		log.Println("Note: Make sure to place logo.png in 'images/' folder")
+		log.Println("Note: Make sure to place photo.jpg in /images/ folder:", err)
 	}
 
-	// Add JPEG image at different position
-	pdf.SetY(220)
+	// Add the same image filling its box: Cover clips what does not fit,
+	// and the caption goes below it
+	pdf.SetXY(50, 220)
 	pdf.SetFont("arial", "", 12)
 	pdf.Cell(nil, "JPEG image example:")
-	err = pdf.Image("images/photo.jpg", 50, 250, &gopdf.Rect{W: 200, H: 150})
+	if photo != nil {
+		_, err = photo.Draw(reg, coverBox, coverOpts)
+		if err != nil {
+			log.Println(err)
+		}
+	}
+	pdf.SetFont("arial", "", 12)
+
+	// Add an SVG logo: it is drawn as vector content, so it stays sharp at
+	// any zoom. With no height, the box gets the logo's proportions
+	pdf.SetXY(300, 220)
+	pdf.Cell(nil, "SVG logo example:")
+	logo, err := svg.ParseFile("images/logo.svg")
+	if err == nil {
+		err = logo.Draw(pdf, page.Box{X: 300, Y: 250, W: 240})
+	}
 	if err != nil {
-		log.Println("Note: Make sure to place photo.jpg in /images/ folder")
+		log.Println("Note: Make sure to place logo.svg in /images/ folder:", err)
 	}
 
 	// Tips for working with images:
@@ -78,8 +131,10 @@ func addImagesExample(pdf *gopdf.GoPdf) {
 	pdf.SetFont("arial", "", 10)
 	pdf.MultiCell(&gopdf.Rect{W: 500, H: 100},
 		"Tips:\n"+
-			"- Supported formats: PNG, JPEG\n"+
-			"- Use &gopdf.Rect{W: width, H: height} to resize images\n"+
+			"- Supported formats: PNG, JPEG, and SVG with the svg package\n"+
+			"- Use the imaging package to fit images in a box without distorting them\n"+
+			"- The same image placed many times is embedded in the file only once\n"+
+			"- Preprocess large photos to the resolution they are shown at to keep files small\n"+
 			"- Position with x, y coordinates\n"+
 			"- Make sure image files exist in the specified path")
 
@@ -128,22 +183,65 @@ func drawShapesExample(pdf *gopdf.GoPdf) {
 	// Diagonal line
 	pdf.Line(50, 240, 200, 340)
 
-	// Draw a polyline (connected lines)
-	pdf.SetLineWidth(2)
-	pdf.SetStrokeColor(255, 0, 0) // Red
-	// Starting point
-	pdf.SetX(400)
-	pdf.SetY(240)
-	// Draw connected lines
-	pdf.Line(400, 240, 450, 280)
-	pdf.Line(450, 280, 500, 260)
-	pdf.Line(500, 260, 550, 300)
-
-	// Draw a circle (using oval with equal width and height)
-	pdf.SetLineWidth(2)
-	pdf.SetStrokeColor(0, 0, 255)
-	pdf.SetFillColor(200, 200, 255)
-	pdf.Oval(150, 380, 50, 50) // x, y, width, height
+	// Draw a polyline (connected lines) as one path, so the corners are
+	// joined instead of drawn as separate line ends
+	polyline := shape.New()
+	polyline.Polyline(
+		shape.Point{X: 400, Y: 240},
+		shape.Point{X: 450, Y: 280},
+		shape.Point{X: 500, Y: 260},
+		shape.Point{X: 550, Y: 300},
+	)
+	err := polyline.Draw(pdf, shape.Style{
+		Stroke: color.RGBA{255, 0, 0, 255}, // Red
+		Width:  2,
+		Cap:    shape.RoundCap,
+		Join:   shape.RoundJoin,
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	// Draw a circle, filled and outlined
+	circle := shape.New().Circle(125, 420, 50)
+	err = circle.Draw(pdf, shape.Style{
+		Fill:   color.RGBA{200, 200, 255, 255},
+		Stroke: color.RGBA{0, 0, 255, 255},
+		Width:  2,
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	// Draw a rounded rectangle with a dashed outline
+	rounded := shape.New().RoundedRect(230, 370, 140, 100, 15)
+	err = rounded.Draw(pdf, shape.Style{
+		Fill:   color.RGBA{255, 245, 200, 255},
+		Stroke: color.RGBA{200, 120, 0, 255},
+		Width:  1.5,
+		Dash:   []float64{6, 3},
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	// Draw a star that crosses itself: the even-odd rule leaves the middle,
+	// which the outline goes around twice, unfilled
+	star := shape.New()
+	for i := range 5 {
+		a := float64(i*144-90) * math.Pi / 180
+		star.LineTo(475+60*math.Cos(a), 420+60*math.Sin(a))
+	}
+	star.Close()
+	err = star.Draw(pdf, shape.Style{
+		Fill:     color.RGBA{255, 200, 0, 255},
+		Stroke:   color.RGBA{0, 0, 0, 255},
+		FillRule: shape.EvenOdd,
+		Join:     shape.RoundJoin,
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
 
 	// Add labels
 	pdf.SetFont("arial", "", 9)
@@ -154,6 +252,60 @@ func drawShapesExample(pdf *gopdf.GoPdf) {
 	pdf.Cell(nil, "Filled")
 	pdf.SetXY(480, 185)
 	pdf.Cell(nil, "Both")
+	pdf.SetXY(105, 480)
+	pdf.Cell(nil, "Circle")
+	pdf.SetXY(265, 480)
+	pdf.Cell(nil, "Rounded, dashed")
+	pdf.SetXY(435, 490)
+	pdf.Cell(nil, "Even-odd star")
+
+	// Draw barcodes, such as for a packing slip: the bars are vector
+	// rectangles, so they scan at any print size
+	reg := fonts.NewRegistry(pdf)
+	pdf.SetXY(50, 530)
+	pdf.Cell(nil, "Code 128 barcode:")
+	pdf.SetXY(330, 530)
+	pdf.Cell(nil, "EAN-13 barcode (check digit added):")
+	code, err := barcode.New(barcode.Code128, "SHIP-2024-00042", barcode.Options{})
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := code.Draw(reg, page.Box{X: 50, Y: 550, W: 220, H: 60}); err != nil {
+		log.Fatal(err)
+	}
+	ean, err := barcode.New(barcode.EAN13, "400638133393", barcode.Options{})
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := ean.Draw(reg, page.Box{X: 330, Y: 550, W: 160, H: 60}); err != nil {
+		log.Fatal(err)
+	}
+
+	// Draw a QR code for a payment link and a DataMatrix, module by module:
+	// no image files needed
+	pdf.SetFont("arial", "", 9)
+	pdf.SetXY(50, 630)
+	pdf.Cell(nil, "QR code (payment link):")
+	pdf.SetXY(330, 630)
+	pdf.Cell(nil, "DataMatrix:")
+	qr, err := barcode.NewQR("https://example.com/pay?invoice=INV-0042&amount=199.00", barcode.MatrixOptions{
+		Level: barcode.LevelQ,
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := qr.Draw(pdf, page.Box{X: 50, Y: 645, W: 110}); err != nil {
+		log.Fatal(err)
+	}
+	dm, err := barcode.NewDataMatrix("INV-0042 199.00 EUR", barcode.MatrixOptions{
+		Color: color.RGBA{0, 0, 120, 255},
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := dm.Draw(pdf, page.Box{X: 330, Y: 645, W: 80}); err != nil {
+		log.Fatal(err)
+	}
 
 	// Save PDF
 	pdf.WritePdf(goPdfFolder + advancedFeatures + "draw_shapes.pdf")
@@ -169,107 +321,201 @@ func createTableExample(pdf *gopdf.GoPdf) {
 	pdf.Cell(nil, "Example 3: Creating Tables and Grids")
 	pdf.Br(30)
 
-	// Table configuration
-	startX := 50.0
-	startY := 100.0
-	colWidths := []float64{50, 150, 100, 100, 100} // Column widths
-	rowHeight := 25.0
-
-	// Table headers
-	headers := []string{"ID", "Product Name", "Quantity", "Price", "Total"}
-
-	// Table data
-	data := [][]string{
-		{"1", "Laptop", "2", "$800", "$1,600"},
-		{"2", "Mouse", "5", "$25", "$125"},
-		{"3", "Keyboard", "3", "$75", "$225"},
-		{"4", "Monitor", "2", "$300", "$600"},
-		{"5", "USB Cable", "10", "$5", "$50"},
+	// The table package sizes the columns from their content, wraps long
+	// cells and continues the table on new pages, repeating the header.
+	// It draws into a flow, with fonts from a registry.
+	reg := fonts.NewRegistry(pdf)
+	margin := (18 * units.Mm).Units(pdf)
+	f := flow.New(pdf, flow.Options{
+		Margins: flow.Margins{Left: margin, Top: margin, Right: margin, Bottom: margin},
+		Drawer:  reg,
+	})
+	f.SetY((35 * units.Mm).Units(pdf))
+
+	// Table look: white on gray header and striped rows
+	t := table.New(table.Options{
+		Size:        10,
+		HeaderColor: color.White,
+		HeaderFill:  color.RGBA{100, 100, 100, 255},
+		Stripe:      color.RGBA{240, 240, 240, 255},
+		BorderColor: color.Black,
+		TotalLabel:  "Grand Total",
+	})
+
+	// Table headers: ID and Product Name span both header rows, and Order
+	// groups the three columns below it
+	middle := table.Cell{RowSpan: 2, VAlign: table.Middle}
+	id, name := middle, middle
+	id.Text, name.Text = "ID", "Product Name"
+	t.AddHeaderCells(id, name, table.Cell{Text: "Order", ColSpan: 3, Align: table.Center})
+	t.AddHeaderCells(table.Cell{Text: "Quantity"}, table.Cell{Text: "Price"}, table.Cell{Text: "Total"})
+
+	// Column widths come from the content; a column can also have a fixed
+	// width or a minimum and maximum, e.g. to leave room for longer names
+	t.SetColumn(0, table.Column{Width: (12 * units.Mm).Units(pdf)})
+	t.SetColumn(1, table.Column{Min: (50 * units.Mm).Units(pdf)})
+
+	// Table data: a slice of structs, whose tags give the format and
+	// alignment of the columns. Columns of numbers are aligned right, and
+	// the Total column is added up in a row labelled Options.TotalLabel
+	type orderLine struct {
+		ID       int     `table:"ID,center"`
+		Product  string  `table:"Product Name"`
+		Quantity int     `table:"Quantity"`
+		Price    float64 `table:"Price" format:"$#,##0"`
+		Total    float64 `table:"Total,total" format:"$#,##0"`
 	}
-
-	// Draw table header
-	pdf.SetFillColor(100, 100, 100) // Gray background for header
-	pdf.SetTextColor(255, 255, 255) // White text
-	pdf.SetFont("arial", "", 11)
-
-	currentY := startY
-	currentX := startX
-
-	// Draw header cells
-	for i, header := range headers {
-		// Draw cell background
-		pdf.RectFromUpperLeftWithStyle(currentX, currentY, colWidths[i], rowHeight, "F")
-
-		// Draw cell border
-		pdf.SetStrokeColor(0, 0, 0)
-		pdf.RectFromUpperLeftWithStyle(currentX, currentY, colWidths[i], rowHeight, "D")
-
-		// Add text
-		pdf.SetXY(currentX+5, currentY+8)
-		pdf.Cell(nil, header)
-
-		currentX += colWidths[i]
+	orders := []orderLine{
+		{1, "Laptop", 2, 800, 0},
+		{2, "Mouse", 5, 25, 0},
+		{3, "Keyboard", 3, 75, 0},
+		{4, "Monitor", 2, 300, 0},
+		{5, "USB Cable", 10, 5, 0},
+	}
+	for i := range orders {
+		orders[i].Total = float64(orders[i].Quantity) * orders[i].Price
+	}
+	if err := t.LoadStructs(orders); err != nil {
+		log.Println(err)
 	}
 
-	// Draw table rows
-	pdf.SetTextColor(0, 0, 0) // Black text
-	pdf.SetFont("arial", "", 10)
+	if err := t.Draw(f, reg); err != nil {
+		log.Println(err)
+	}
 
-	currentY += rowHeight
+	// Add table summary
+	reg.SetFont(fonts.Sans, fonts.Regular, 12)
+	f.Space((7 * units.Mm).Units(pdf))
+	f.Text(fmt.Sprintf("Total Items: %d", len(orders)), 20)
+
+	// A longer table: the rows do not fit on one page, so the table
+	// continues on the next pages, and the long descriptions wrap
+	f.Space(20)
+	reg.SetFont(fonts.Sans, fonts.Regular, 14)
+	f.Text("Inventory (continues over several pages)", 25)
+
+	inventory := table.New(table.Options{Stripe: color.RGBA{240, 240, 240, 255}})
+	inventory.SetHeader("SKU", "Description", "Location", "Stock")
+	for i := 1; i <= 60; i++ {
+		description := fmt.Sprintf("Spare part %d", i)
+		if i%7 == 0 {
+			description += " - heavy item, stored on the lower shelves and " +
+				"only moved with the pallet truck"
+		}
+		inventory.AddRow(fmt.Sprintf("SKU-%04d", i*37), description,
+			fmt.Sprintf("Aisle %d, shelf %c", i%12+1, 'A'+i%5), fmt.Sprint(i*13%200))
+	}
+	if err := inventory.Draw(f, reg); err != nil {
+		log.Println(err)
+	}
 
-	for _, row := range data {
-		currentX = startX
+	// Save PDF
+	pdf.WritePdf(goPdfFolder + advancedFeatures + "table-create.pdf")
 
-		for i, cell := range row {
-			// Alternate row colors for better readability
-			if int((currentY-startY)/rowHeight)%2 == 0 {
-				pdf.SetFillColor(240, 240, 240) // Light gray
-			} else {
-				pdf.SetFillColor(255, 255, 255) // White
-			}
+	fmt.Println("Created: table_create.pdf to", goPdfFolder+advancedFeatures, "folder")
+}
 
-			// Draw cell background
-			pdf.RectFromUpperLeftWithStyle(currentX, currentY, colWidths[i], rowHeight, "F")
+// Example 4: Charts and Graphs
+func createChartsExample(pdf *gopdf.GoPdf) {
+	pdf.AddPage()
 
-			// Draw cell border
-			pdf.SetStrokeColor(0, 0, 0)
-			pdf.RectFromUpperLeftWithStyle(currentX, currentY, colWidths[i], rowHeight, "D")
+	pdf.SetFont("arial", "", 16)
+	pdf.Cell(nil, "Example 4: Charts and Graphs")
+	pdf.Br(30)
 
-			// Add text
-			pdf.SetXY(currentX+5, currentY+8)
-			pdf.Cell(nil, cell)
+	// The chart package draws a chart into a box on the page with lines,
+	// rectangles and polygons, so the charts stay sharp when zoomed or
+	// printed. Here, six kinds of chart in a grid of two columns
+	reg := fonts.NewRegistry(pdf)
+	content := page.Content(pdf)
+	margin := (18 * units.Mm).Units(pdf)
+	gap := (8 * units.Mm).Units(pdf)
+	w := (page.Size(pdf).W - 2*margin - gap) / 2
+	h := (70 * units.Mm).Units(pdf)
+	top := content.Y + (15 * units.Mm).Units(pdf)
+	box := func(i int) page.Box {
+		return page.Box{X: margin + float64(i%2)*(w+gap), Y: top + float64(i/2)*(h+gap), W: w, H: h}
+	}
 
-			currentX += colWidths[i]
+	quarters := []string{"Q1", "Q2", "Q3", "Q4"}
+	months := []string{"Jan", "Feb", "Mar", "Apr", "May", "Jun", "Jul", "Aug", "Sep", "Oct", "Nov", "Dec"}
+	dollars := func(v float64) string { return table.FormatNumber(v, "$#,##0k") }
+
+	// Bar chart: the series side by side in every category
+	sales := chart.New(chart.Bar, chart.Options{Title: "Sales by Region", Format: dollars})
+	sales.SetLabels(quarters...)
+	sales.AddSeries("North", 120, 150, 90, 180)
+	sales.AddSeries("South", 95, 110, 130, 160)
+	sales.AddSeries("West", 60, 85, 100, 120)
+
+	// Stacked bar chart: the series on top of each other, the legend below
+	costs := chart.New(chart.StackedBar, chart.Options{Title: "Costs", Format: dollars, Legend: chart.LegendBottom})
+	costs.SetLabels(quarters...)
+	costs.AddSeries("Staff", 50, 52, 55, 60)
+	costs.AddSeries("Rent", 20, 20, 22, 22)
+	costs.AddSeries("Marketing", 15, 30, 10, 35)
+
+	// Line chart
+	visitors := chart.New(chart.Line, chart.Options{Title: "Visitors (thousands)"})
+	visitors.SetLabels(months...)
+	visitors.AddSeries("2024", 12, 14, 18, 21, 25, 31, 35, 33, 27, 22, 17, 15)
+	visitors.AddSeries("2025", 15, 17, 22, 26, 30, 36, 41, 38, 31, 26, 20, 18)
+
+	// Area chart: the filled areas are see-through, so both show
+	temperature := chart.New(chart.Area, chart.Options{Title: "Temperature (°C)", Legend: chart.LegendBottom})
+	temperature.SetLabels(months...)
+	temperature.AddSeries("Oslo", -4, -4, 0, 5, 11, 15, 17, 16, 11, 6, 1, -3)
+	temperature.AddSeries("Madrid", 6, 8, 11, 13, 17, 23, 26, 26, 21, 15, 10, 7)
+
+	// Scatter chart: every series has its own X values
+	heights := chart.New(chart.Scatter, chart.Options{Title: "Height and Weight"})
+	heights.Add(chart.Series{
+		Name:   "Players",
+		X:      []float64{168, 172, 175, 178, 180, 182, 185, 188, 190, 193},
+		Values: []float64{62, 68, 70, 74, 77, 79, 83, 86, 90, 95},
+	})
+	heights.Add(chart.Series{
+		Name:   "Coaches",
+		X:      []float64{170, 176, 179, 184},
+		Values: []float64{80, 84, 88, 92},
+	})
+
+	// Pie chart: the slices of the first series, named by the labels
+	share := chart.New(chart.Pie, chart.Options{Title: "Market Share"})
+	share.SetLabels("Product A", "Product B", "Product C", "Other")
+	share.AddSeries("Share", 45, 25, 20, 10)
+
+	for i, c := range []*chart.Chart{sales, costs, visitors, temperature, heights, share} {
+		if err := c.Draw(reg, box(i)); err != nil {
+			log.Println(err)
 		}
-
-		currentY += rowHeight
 	}
 
-	// Add table summary
-	currentY += 20
-	pdf.SetFont("arial", "", 12)
-	pdf.SetXY(startX, currentY)
-	pdf.Cell(nil, "Total Items: 5")
-	pdf.SetXY(startX+300, currentY)
-	pdf.Cell(nil, "Grand Total: $2,600")
-
 	// Save PDF
-	pdf.WritePdf(goPdfFolder + advancedFeatures + "table-create.pdf")
+	pdf.WritePdf(goPdfFolder + advancedFeatures + "charts.pdf")
 
-	fmt.Println("Created: table_create.pdf to", goPdfFolder+advancedFeatures, "folder")
+	fmt.Println("Created: charts.pdf to", goPdfFolder+advancedFeatures, "folder")
 }
 
-// Example 4: Headers and Footers Implementation
+// Example 5: Headers and Footers Implementation
 func addHeaderFooterExample(pdf *gopdf.GoPdf) {
+	// A flow draws the header and footer on every page it adds, and keeps
+	// the content between them
+	f := flow.New(pdf, flow.Options{Margins: flow.Margins{Left: 50, Right: 50}})
+	f.SetHeader(80, func(f *flow.Flow, b flow.Band) error {
+		drawHeader(pdf, "Advanced gopdf Tutorial", b)
+		return nil
+	})
+	f.SetFooter(60, func(f *flow.Flow, b flow.Band) error {
+		return drawFooter(f, b)
+	})
+
 	// Add multiple pages to demonstrate headers and footers
 	for i := 1; i <= 3; i++ {
-		pdf.AddPage()
-
-		// HEADER
-		drawHeader(pdf, "Advanced gopdf Tutorial")
+		f.NewPage()
 
 		// CONTENT
-		pdf.SetY(80) // Start content below header
+		pdf.SetXY(f.X(), f.Y()) // Start content below header
 		pdf.SetFont("arial", "", 12)
 		pdf.Cell(nil, fmt.Sprintf("This is page %d content", i))
 		pdf.Br(20)
@@ -282,71 +528,83 @@ func addHeaderFooterExample(pdf *gopdf.GoPdf) {
 				"- Add page numbers in footers\n"+
 				"- Include date or document metadata\n"+
 				"- Use subtle colors or lines to separate from content")
-
-		// FOOTER
-		drawFooter(pdf, i, 3) // Current page, total pages
 	}
 
-	// Save PDF
-	pdf.WritePdf(goPdfFolder + advancedFeatures + "add_header_footer.pdf")
+	// Save PDF: the flow fills in the total page count of the footers first
+	if err := f.WritePdf(goPdfFolder + advancedFeatures + "add_header_footer.pdf"); err != nil {
+		log.Println(err)
+	}
 
 	fmt.Println("Created: add_header_footer.pdf to", goPdfFolder+advancedFeatures, "folder")
 }
 
 // Helper function to draw header
-func drawHeader(pdf *gopdf.GoPdf, title string) {
-	// Draw header background
-	pdf.SetFillColor(41, 128, 185)                     // Blue background
-	pdf.RectFromUpperLeftWithStyle(0, 0, 595, 50, "F") // A4 width = 595 points
+// b is the header band the flow gives it: it spans the content width, so
+// the header fits the page whatever its size.
+func drawHeader(pdf *gopdf.GoPdf, title string, b flow.Band) {
+	// Draw header background across the whole page width
+	pdf.SetFillColor(41, 128, 185) // Blue background
+	pdf.RectFromUpperLeftWithStyle(0, b.Y, page.Size(pdf).W, 50, "F")
 
 	// Add header text
 	pdf.SetTextColor(255, 255, 255) // White text
 	pdf.SetFont("arial", "", 18)
-	pdf.SetXY(50, 15)
+	pdf.SetXY(b.X, b.Y+15)
 	pdf.Cell(nil, title)
 
 	// Add header line
 	pdf.SetStrokeColor(255, 255, 255)
 	pdf.SetLineWidth(2)
-	pdf.Line(50, 45, 545, 45)
+	pdf.Line(b.X, b.Y+45, b.X+b.W, b.Y+45)
 
 	// Reset text color for content
 	pdf.SetTextColor(0, 0, 0)
 }
 
 // Helper function to draw footer
-func drawFooter(pdf *gopdf.GoPdf, currentPage, totalPages int) {
+// b is the footer band at the bottom of the page. The total number of
+// pages is not known while the pages are being added, so the page number
+// is written with the flow.TotalPages placeholder, which the flow fills in
+// when the document is written.
+func drawFooter(f *flow.Flow, b flow.Band) error {
+	pdf := f.PDF()
+
 	// Draw footer line
+	lineY := b.Y + 10
 	pdf.SetStrokeColor(200, 200, 200)
 	pdf.SetLineWidth(1)
-	pdf.Line(50, 792, 545, 792) // A4 height = 842 points
+	pdf.Line(b.X, lineY, b.X+b.W, lineY)
 
 	// Add footer text
 	pdf.SetTextColor(100, 100, 100) // Gray text
 	pdf.SetFont("arial", "", 10)
 
 	// Left side - document info
-	pdf.SetXY(50, 800)
+	pdf.SetXY(b.X, lineY+8)
 	pdf.Cell(nil, "gopdf Tutorial - Advanced Features")
 
-	// Center - date
-	pdf.SetXY(250, 800)
-	pdf.Cell(nil, "Generated with gopdf")
+	// Center
+	center := "Generated with gopdf"
+	centerWidth, _ := pdf.MeasureTextWidth(center)
+	pdf.SetXY(b.X+(b.W-centerWidth)/2, lineY+8)
+	pdf.Cell(nil, center)
 
-	// Right side - page number
-	pdf.SetXY(500, 800)
-	pdf.Cell(nil, fmt.Sprintf("Page %d of %d", currentPage, totalPages))
+	// Right side - page number, e.g. "Page 2 of 5". PageText takes the
+	// baseline, which is about 9pt below the top of the 10pt cells above.
+	text := fmt.Sprintf("Page %d of %s", f.Page(), flow.TotalPages)
+	err := f.PageText(text, b.X, lineY+17, b.W, gopdf.Right)
 
 	// Reset text color
 	pdf.SetTextColor(0, 0, 0)
+	return err
 }
 
-// Example 5: Page Numbering with gopdf
+// Example 6: Page Numbering with gopdf
 func addPageNumberingExample(pdf *gopdf.GoPdf) {
 	pdf.AddPage()
 
 	pdf.SetFont("arial", "", 16)
-	pdf.Cell(nil, "Example 5: Page Numbering Techniques")
+	pdf.Cell(nil, "Example 6: Page Numbering Techniques")
 	pdf.Br(30)
 
 	pdf.SetFont("arial", "", 12)
@@ -371,8 +629,11 @@ func addPageNumberingExample(pdf *gopdf.GoPdf) {
 
 	// Add a simple page number in the center bottom
 	pdf.SetFont("arial", "", 10)
-	pdf.SetXY(275, 800)
-	pdf.Cell(nil, "- End of Examples -")
+	end := "- End of Examples -"
+	endWidth, _ := pdf.MeasureTextWidth(end)
+	size := page.Size(pdf)
+	pdf.SetXY((size.W-endWidth)/2, size.H-42)
+	pdf.Cell(nil, end)
 
 	// Save PDF
 	pdf.WritePdf(goPdfFolder + advancedFeatures + "page_numbering.pdf")
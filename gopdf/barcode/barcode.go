@@ -0,0 +1,217 @@
+// Package barcode encodes Code 128, Code 39, EAN-13, EAN-8 and UPC-A
+// barcodes, QR codes and DataMatrix symbols, and draws them as vector
+// bars and modules, for packing slips, shipping labels, receipts and
+// payment links on invoices.
+//
+//	b, err := barcode.New(barcode.EAN13, "400638133393", barcode.Options{})
+//	...
+//	err = b.Draw(reg, page.Box{X: 50, Y: 700, W: 150, H: 60})
+//
+// Check digits are added when the data leaves them out, and checked when
+// it has them.
+//
+//	qr, err := barcode.NewQR("https://example.com/pay/42", barcode.MatrixOptions{})
+//	...
+//	err = qr.Draw(&pdf, page.Box{X: 450, Y: 700, W: 90})
+package barcode
+
+import (
+	"errors"
+	"fmt"
+	"image/color"
+	"strings"
+
+	"github.com/signintech/gopdf"
+
+	"pdf-tutorial/gopdf/fonts"
+	"pdf-tutorial/gopdf/page"
+	"pdf-tutorial/gopdf/shape"
+)
+
+// Kind is the symbology of a barcode.
+type Kind int
+
+const (
+	Code128 Kind = iota // Any ASCII text; the shortest mix of subsets A, B and C
+	Code39              // Digits, upper case letters, space and - . $ / + %
+	EAN13               // 12 digits and a check digit
+	EAN8                // 7 digits and a check digit
+	UPCA                // 11 digits and a check digit
+)
+
+var (
+	// ErrInvalid is returned by New for data the symbology cannot encode.
+	ErrInvalid = errors.New("barcode: invalid data")
+
+	// ErrChecksum is returned by New for data whose check digit is wrong.
+	ErrChecksum = errors.New("barcode: wrong check digit")
+)
+
+// Options controls how a barcode looks. Zero fields get the default given
+// in their comment.
+type Options struct {
+	Family string      // Font family of the text (fonts.Mono)
+	Size   float64     // Font size of the text, in points (9)
+	Color  color.Color // Color of the bars and the text (black)
+
+	// HideText leaves out the human-readable text, so the bars fill the
+	// box.
+	HideText bool
+
+	// Checksum adds the optional mod 43 check character to a Code 39
+	// barcode.
+	Checksum bool
+}
+
+func (o *Options) setDefaults() {
+	if o.Family == "" {
+		o.Family = fonts.Mono
+	}
+	if o.Size <= 0 {
+		o.Size = 9
+	}
+	if o.Color == nil {
+		o.Color = color.Black
+	}
+}
+
+// Barcode is an encoded barcode, drawn by Draw.
+type Barcode struct {
+	kind Kind
+	opts Options
+	text string // Human-readable text, check digit included
+
+	// modules are the narrowest bars and spaces of the barcode, true for a
+	// bar, without the quiet zones.
+	modules []bool
+	// guards are the modules of the EAN and UPC guard bars, which go down
+	// between the digits of the text.
+	guards []bool
+	quiet  int // Width of each quiet zone, in modules
+}
+
+// New encodes data as a barcode of the kind given.
+func New(kind Kind, data string, opts Options) (*Barcode, error) {
+	b := &Barcode{kind: kind, opts: opts}
+	var err error
+	switch kind {
+	case Code128:
+		err = b.code128(data)
+	case Code39:
+		err = b.code39(data)
+	case EAN13, EAN8, UPCA:
+		err = b.ean(data)
+	default:
+		err = fmt.Errorf("barcode: unknown kind %d", kind)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+// Text returns the human-readable text of b, with the check digit of an
+// EAN or UPC barcode, or the check character of a Code 39 barcode.
+func (b *Barcode) Text() string {
+	return b.text
+}
+
+// Modules returns the width of b in modules, its narrowest bars, quiet
+// zones included: a barcode is easiest to scan if box is at least this
+// many times 0.25 mm wide.
+func (b *Barcode) Modules() int {
+	return len(b.modules) + 2*b.quiet
+}
+
+// Draw draws b in box, whose coordinates are in the unit of the document:
+// the bars and quiet zones fill its width, and the bars and the text its
+// height.
+func (b *Barcode) Draw(reg *fonts.Registry, box page.Box) error {
+	opts := b.opts
+	opts.setDefaults()
+	pdf := reg.PDF()
+	module := box.W / float64(b.Modules())
+	x0 := box.X + float64(b.quiet)*module
+
+	// The text takes a line of 1.2 times its size below the bars.
+	bars := box.H
+	size := pdf.PointsToUnits(opts.Size)
+	if !opts.HideText {
+		bars -= size * 1.2
+	}
+	if bars <= 0 {
+		return errors.New("barcode: box too low for the text")
+	}
+	guard := bars
+	if b.guards != nil && !opts.HideText {
+		guard += size / 2
+	}
+
+	isGuard := func(i int) bool { return b.guards != nil && b.guards[i] }
+	p := shape.New()
+	for i := 0; i < len(b.modules); {
+		if !b.modules[i] {
+			i++
+			continue
+		}
+		j := i
+		for j < len(b.modules) && b.modules[j] && isGuard(j) == isGuard(i) {
+			j++
+		}
+		h := bars
+		if isGuard(i) {
+			h = guard
+		}
+		p.Rect(x0+float64(i)*module, box.Y, float64(j-i)*module, h)
+		i = j
+	}
+	if err := p.Draw(pdf, shape.Style{Fill: opts.Color}); err != nil {
+		return err
+	}
+	if opts.HideText {
+		return nil
+	}
+
+	d := &texter{reg: reg, opts: opts, y: box.Y + bars + size}
+	if b.guards == nil {
+		return d.text(b.text, box.CenterX(), gopdf.Center)
+	}
+	return b.eanText(d, x0, module)
+}
+
+// texter draws the text of a barcode.
+type texter struct {
+	reg  *fonts.Registry
+	opts Options
+	y    float64 // Baseline of the text
+}
+
+// text draws s at x on the baseline, aligned gopdf.Left, gopdf.Center or
+// gopdf.Right.
+func (t *texter) text(s string, x float64, align int) error {
+	if err := t.reg.SetFont(t.opts.Family, fonts.Regular, t.opts.Size); err != nil {
+		return err
+	}
+	if align != gopdf.Left {
+		w, err := t.reg.MeasureTextWidth(s)
+		if err != nil {
+			return err
+		}
+		if align == gopdf.Center {
+			x -= w / 2
+		} else {
+			x -= w
+		}
+	}
+	r, g, bl, _ := t.opts.Color.RGBA()
+	t.reg.PDF().SetTextColor(uint8(r>>8), uint8(g>>8), uint8(bl>>8))
+	t.reg.SetXY(x, t.y)
+	err := t.reg.Text(s)
+	t.reg.PDF().SetTextColor(0, 0, 0)
+	return err
+}
+
+// digits reports whether s is made of ASCII digits only.
+func digits(s string) bool {
+	return s != "" && strings.Trim(s, "0123456789") == ""
+}
@@ -0,0 +1,170 @@
+package barcode
+
+import "fmt"
+
+// code128Patterns are the widths of the bars and spaces of the Code 128
+// symbols, by value; 103 to 105 are the start symbols and 106 the stop.
+var code128Patterns = [...]string{
+	"212222", "222122", "222221", "121223", "121322", "131222", "122213", "122312", "132212", "221213",
+	"221312", "231212", "112232", "122132", "122231", "113222", "123122", "123221", "223211", "221132",
+	"221231", "213212", "223112", "312131", "311222", "321122", "321221", "312212", "322112", "322211",
+	"212123", "212321", "232121", "111323", "131123", "131321", "112313", "132113", "132311", "211313",
+	"231113", "231311", "112133", "112331", "132131", "113123", "113321", "133121", "313121", "211331",
+	"231131", "213113", "213311", "213131", "311123", "311321", "331121", "312113", "312311", "332111",
+	"314111", "221411", "431111", "111224", "111422", "121124", "121421", "141122", "141221", "112214",
+	"112412", "122114", "122411", "142112", "142211", "241211", "221114", "413111", "241112", "134111",
+	"111242", "121142", "121241", "114212", "124112", "124211", "411212", "421112", "421211", "212141",
+	"214121", "412121", "111143", "111341", "131141", "114113", "114311", "411113", "411311", "113141",
+	"114131", "311141", "411131", "211412", "211214", "211232", "2331112",
+}
+
+// Code 128 subsets and the values of its special symbols.
+const (
+	setA = iota
+	setB
+	setC
+
+	shift128 = 98
+	codeC    = 99
+	codeB    = 100 // In subsets A and C
+	codeA    = 101 // In subsets B and C
+	startA   = 103
+	stop128  = 106
+)
+
+// code128 encodes data in as few symbols as the subsets allow: subset C
+// for runs of digits, two to a symbol, subset A for control characters and
+// B for the rest.
+func (b *Barcode) code128(data string) error {
+	for _, r := range data {
+		if r > 127 {
+			return fmt.Errorf("%w: %q is not ASCII for Code 128", ErrInvalid, r)
+		}
+	}
+	if data == "" {
+		return fmt.Errorf("%w: no data", ErrInvalid)
+	}
+
+	// digitRun returns the number of digits from i on.
+	digitRun := func(i int) int {
+		n := 0
+		for i+n < len(data) && data[i+n] >= '0' && data[i+n] <= '9' {
+			n++
+		}
+		return n
+	}
+	// needs returns the subset, A or B, that the next character of data
+	// from i on that only one of them has needs.
+	needs := func(i int) int {
+		for ; i < len(data); i++ {
+			if data[i] < 32 {
+				return setA
+			}
+			if data[i] >= 96 {
+				return setB
+			}
+		}
+		return setB
+	}
+	value := func(set int, c byte) int {
+		if set == setA && c < 32 {
+			return int(c) + 64
+		}
+		return int(c) - 32
+	}
+	switchTo := func(set int) int {
+		if set == setA {
+			return codeA
+		}
+		return codeB
+	}
+	inSet := func(set int, c byte) bool {
+		if set == setA {
+			return c < 96
+		}
+		return c >= 32
+	}
+
+	var values []int
+	var set int
+	if n := digitRun(0); n >= 4 || n == len(data) && n%2 == 0 {
+		set = setC
+	} else {
+		set = needs(0)
+	}
+	values = append(values, startA+set)
+
+	for i := 0; i < len(data); {
+		if set == setC {
+			if digitRun(i) >= 2 {
+				values = append(values, int(data[i]-'0')*10+int(data[i+1]-'0'))
+				i += 2
+				continue
+			}
+			set = needs(i)
+			values = append(values, switchTo(set))
+			continue
+		}
+
+		// A run of digits is shorter in subset C from four digits on,
+		// starting with its first digit if there is an even number of
+		// them, else with its second.
+		if n := digitRun(i); n >= 4 {
+			if n%2 == 1 {
+				values = append(values, value(set, data[i]))
+				i++
+			}
+			set = setC
+			values = append(values, codeC)
+			continue
+		}
+		c := data[i]
+		if !inSet(set, c) {
+			other := 1 - set
+			// A single character of the other subset is shifted.
+			if i+1 < len(data) && needs(i+1) == set {
+				values = append(values, shift128, value(other, c))
+				i++
+				continue
+			}
+			set = other
+			values = append(values, switchTo(set))
+		}
+		values = append(values, value(set, c))
+		i++
+	}
+
+	sum := values[0]
+	for i, v := range values[1:] {
+		sum += (i + 1) * v
+	}
+	values = append(values, sum%103, stop128)
+
+	for _, v := range values {
+		b.widths(code128Patterns[v])
+	}
+	b.text = printable(data)
+	b.quiet = 10
+	return nil
+}
+
+// widths adds the modules of a pattern of bar and space widths, starting
+// with a bar.
+func (b *Barcode) widths(pattern string) {
+	for i, w := range pattern {
+		for range w - '0' {
+			b.modules = append(b.modules, i%2 == 0)
+		}
+	}
+}
+
+// printable returns s with its control characters as spaces.
+func printable(s string) string {
+	out := []byte(s)
+	for i, c := range out {
+		if c < 32 || c == 127 {
+			out[i] = ' '
+		}
+	}
+	return string(out)
+}
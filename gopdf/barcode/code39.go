@@ -0,0 +1,76 @@
+package barcode
+
+import (
+	"fmt"
+	"strings"
+)
+
+// code39Chars are the characters of Code 39, by value for the check
+// character.
+const code39Chars = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZ-. $/+%"
+
+// code39Patterns are the narrow (n) and wide (w) bars and spaces of the
+// Code 39 characters, in the order of code39Chars, then of the start and
+// stop character *.
+var code39Patterns = [...]string{
+	"nnnwwnwnn", "wnnwnnnnw", "nnwwnnnnw", "wnwwnnnnn", "nnnwwnnnw",
+	"wnnwwnnnn", "nnwwwnnnn", "nnnwnnwnw", "wnnwnnwnn", "nnwwnnwnn",
+	"wnnnnwnnw", "nnwnnwnnw", "wnwnnwnnn", "nnnnwwnnw", "wnnnwwnnn",
+	"nnwnwwnnn", "nnnnnwwnw", "wnnnnwwnn", "nnwnnwwnn", "nnnnwwwnn",
+	"wnnnnnnww", "nnwnnnnww", "wnwnnnnwn", "nnnnwnnww", "wnnnwnnwn",
+	"nnwnwnnwn", "nnnnnnwww", "wnnnnnwwn", "nnwnnnwwn", "nnnnwnwwn",
+	"wwnnnnnnw", "nwwnnnnnw", "wwwnnnnnn", "nwnnwnnnw", "wwnnwnnnn",
+	"nwwnwnnnn", "nwnnnnwnw", "wwnnnnwnn", "nwwnnnwnn", "nwnwnwnnn",
+	"nwnwnnnwn", "nwnnnwnwn", "nnnwnwnwn", "nwnnwnwnn",
+}
+
+// code39Wide is the width of a wide bar or space, in modules.
+const code39Wide = 3
+
+// code39 encodes data between start and stop characters, with the mod 43
+// check character if b.opts.Checksum is set.
+func (b *Barcode) code39(data string) error {
+	if data == "" {
+		return fmt.Errorf("%w: no data", ErrInvalid)
+	}
+	values := make([]int, 0, len(data)+1)
+	sum := 0
+	for i := 0; i < len(data); i++ {
+		v := strings.IndexByte(code39Chars, data[i])
+		if v < 0 {
+			return fmt.Errorf("%w: %q is not a Code 39 character", ErrInvalid, data[i])
+		}
+		values = append(values, v)
+		sum += v
+	}
+	b.text = data
+	if b.opts.Checksum {
+		values = append(values, sum%43)
+		b.text += code39Chars[sum%43 : sum%43+1]
+	}
+
+	star := len(code39Patterns) - 1
+	b.narrowWide(code39Patterns[star])
+	for _, v := range values {
+		b.modules = append(b.modules, false)
+		b.narrowWide(code39Patterns[v])
+	}
+	b.modules = append(b.modules, false)
+	b.narrowWide(code39Patterns[star])
+	b.quiet = 10
+	return nil
+}
+
+// narrowWide adds the modules of a pattern of narrow and wide bars and
+// spaces, starting with a bar.
+func (b *Barcode) narrowWide(pattern string) {
+	for i, c := range pattern {
+		w := 1
+		if c == 'w' {
+			w = code39Wide
+		}
+		for range w {
+			b.modules = append(b.modules, i%2 == 0)
+		}
+	}
+}
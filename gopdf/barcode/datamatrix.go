@@ -0,0 +1,198 @@
+package barcode
+
+import "fmt"
+
+// dmSize is a square ECC 200 DataMatrix symbol size.
+type dmSize struct {
+	size   int // Width of the symbol, in modules
+	region int // Width of each data region, in modules
+	data   int // Data codewords
+	ecc    int // Error correction codewords
+	blocks int // Interleaved blocks
+}
+
+var dmSizes = []dmSize{
+	{10, 8, 3, 5, 1}, {12, 10, 5, 7, 1}, {14, 12, 8, 10, 1}, {16, 14, 12, 12, 1},
+	{18, 16, 18, 14, 1}, {20, 18, 22, 18, 1}, {22, 20, 30, 20, 1}, {24, 22, 36, 24, 1},
+	{26, 24, 44, 28, 1}, {32, 14, 62, 36, 1}, {36, 16, 86, 42, 1}, {40, 18, 114, 48, 1},
+	{44, 20, 144, 56, 1}, {48, 22, 174, 68, 1}, {52, 24, 204, 84, 2}, {64, 14, 280, 112, 2},
+	{72, 16, 368, 144, 4}, {80, 18, 456, 192, 4}, {88, 20, 576, 224, 4}, {96, 22, 696, 272, 4},
+	{104, 24, 816, 336, 6}, {120, 18, 1050, 408, 6}, {132, 20, 1304, 496, 8}, {144, 22, 1558, 620, 10},
+}
+
+// NewDataMatrix encodes data as a square ECC 200 DataMatrix, the smallest
+// that fits, in ASCII mode: two digits go in a codeword, and bytes above
+// 127, such as those of UTF-8 text, take two. opts.Level and opts.Version
+// are for QR codes only.
+func NewDataMatrix(data string, opts MatrixOptions) (*Matrix, error) {
+	var cw []byte
+	for i := 0; i < len(data); i++ {
+		c := data[i]
+		switch {
+		case i+1 < len(data) && digits(data[i:i+2]):
+			cw = append(cw, 130+(c-'0')*10+data[i+1]-'0')
+			i++
+		case c >= 128:
+			cw = append(cw, 235, c-127) // Upper shift
+		default:
+			cw = append(cw, c+1)
+		}
+	}
+
+	var sz dmSize
+	for _, s := range dmSizes {
+		if len(cw) <= s.data {
+			sz = s
+			break
+		}
+	}
+	if sz.size == 0 {
+		return nil, fmt.Errorf("%w: %d codewords for a DataMatrix", ErrTooLong, len(cw))
+	}
+
+	// The first pad codeword is 129, the others are scrambled by their
+	// position, counting from 1.
+	for n := len(cw); len(cw) < sz.data; {
+		pad := 129
+		if len(cw) > n {
+			pad += (149*(len(cw)+1))%253 + 1
+			if pad > 254 {
+				pad -= 254
+			}
+		}
+		cw = append(cw, byte(pad))
+	}
+
+	// Codeword i of the data is in block i modulo the blocks, and so is
+	// each of their error correction codewords.
+	eccLen := sz.ecc / sz.blocks
+	all := append(cw, make([]byte, sz.ecc)...)
+	for b := range sz.blocks {
+		var block []byte
+		for i := b; i < sz.data; i += sz.blocks {
+			block = append(block, cw[i])
+		}
+		for j, e := range dmField.ecc(block, eccLen, 1) {
+			all[sz.data+j*sz.blocks+b] = e
+		}
+	}
+
+	m := newMatrix(sz.size, opts, 1)
+	m.dataMatrix(sz, dmPlace(sz, all))
+	return m, nil
+}
+
+// dmPlace returns the modules of the mapping matrix of a symbol, its data
+// regions put together, for the codewords, in rows; the placement follows
+// annex F of ISO/IEC 16022.
+func dmPlace(sz dmSize, cw []byte) []bool {
+	nrow := sz.size / (sz.region + 2) * sz.region
+	ncol := nrow
+	dark := make([]bool, nrow*ncol)
+	placed := make([]bool, nrow*ncol)
+
+	module := func(row, col, chr, bit int) {
+		if row < 0 {
+			row += nrow
+			col += 4 - (nrow+4)%8
+		}
+		if col < 0 {
+			col += ncol
+			row += 4 - (ncol+4)%8
+		}
+		placed[row*ncol+col] = true
+		dark[row*ncol+col] = cw[chr]&(1<<(8-bit)) != 0
+	}
+	utah := func(row, col, chr int) {
+		module(row-2, col-2, chr, 1)
+		module(row-2, col-1, chr, 2)
+		module(row-1, col-2, chr, 3)
+		module(row-1, col-1, chr, 4)
+		module(row-1, col, chr, 5)
+		module(row, col-2, chr, 6)
+		module(row, col-1, chr, 7)
+		module(row, col, chr, 8)
+	}
+	corner := func(chr int, cells [8][2]int) {
+		for i, c := range cells {
+			module(c[0], c[1], chr, i+1)
+		}
+	}
+
+	chr, row, col := 0, 4, 0
+	for row < nrow || col < ncol {
+		if row == nrow && col == 0 {
+			corner(chr, [8][2]int{{nrow - 1, 0}, {nrow - 1, 1}, {nrow - 1, 2}, {0, ncol - 2}, {0, ncol - 1}, {1, ncol - 1}, {2, ncol - 1}, {3, ncol - 1}})
+			chr++
+		}
+		if row == nrow-2 && col == 0 && ncol%4 != 0 {
+			corner(chr, [8][2]int{{nrow - 3, 0}, {nrow - 2, 0}, {nrow - 1, 0}, {0, ncol - 4}, {0, ncol - 3}, {0, ncol - 2}, {0, ncol - 1}, {1, ncol - 1}})
+			chr++
+		}
+		if row == nrow-2 && col == 0 && ncol%8 == 4 {
+			corner(chr, [8][2]int{{nrow - 3, 0}, {nrow - 2, 0}, {nrow - 1, 0}, {0, ncol - 2}, {0, ncol - 1}, {1, ncol - 1}, {2, ncol - 1}, {3, ncol - 1}})
+			chr++
+		}
+		if row == nrow+4 && col == 2 && ncol%8 == 0 {
+			corner(chr, [8][2]int{{nrow - 1, 0}, {nrow - 1, ncol - 1}, {0, ncol - 3}, {0, ncol - 2}, {0, ncol - 1}, {1, ncol - 3}, {1, ncol - 2}, {1, ncol - 1}})
+			chr++
+		}
+
+		// Up and to the right, then down and to the left.
+		for {
+			if row < nrow && col >= 0 && !placed[row*ncol+col] {
+				utah(row, col, chr)
+				chr++
+			}
+			row, col = row-2, col+2
+			if row < 0 || col >= ncol {
+				break
+			}
+		}
+		row, col = row+1, col+3
+		for {
+			if row >= 0 && col < ncol && !placed[row*ncol+col] {
+				utah(row, col, chr)
+				chr++
+			}
+			row, col = row+2, col-2
+			if row >= nrow || col < 0 {
+				break
+			}
+		}
+		row, col = row+3, col+1
+	}
+
+	// A corner left over is filled with a fixed pattern.
+	if !placed[nrow*ncol-1] {
+		dark[nrow*ncol-1] = true
+		dark[(nrow-2)*ncol+ncol-2] = true
+	}
+	return dark
+}
+
+// dataMatrix draws the data regions of a symbol, each inside its finder
+// pattern: solid on the left and bottom, alternating on the top and right.
+func (m *Matrix) dataMatrix(sz dmSize, mapping []bool) {
+	block := sz.region + 2
+	ncol := sz.size / block * sz.region
+	for y := range sz.size {
+		for x := range sz.size {
+			lx, ly := x%block, y%block
+			var dark bool
+			switch {
+			case lx == 0 || ly == block-1:
+				dark = true
+			case ly == 0:
+				dark = x%2 == 0
+			case lx == block-1:
+				dark = y%2 == 1
+			default:
+				row := y/block*sz.region + ly - 1
+				col := x/block*sz.region + lx - 1
+				dark = mapping[row*ncol+col]
+			}
+			m.set(x, y, dark)
+		}
+	}
+}
@@ -0,0 +1,154 @@
+package barcode
+
+import (
+	"fmt"
+
+	"github.com/signintech/gopdf"
+)
+
+// eanL are the modules of the digits with odd parity, set L. Those of set
+// R are their complements, and those of set G the R ones reversed.
+var eanL = [10]string{
+	"0001101", "0011001", "0010011", "0111101", "0100011",
+	"0110001", "0101111", "0111011", "0110111", "0001011",
+}
+
+// eanParity are the sets of the left digits of an EAN-13 barcode, by its
+// first digit, which is not drawn as bars.
+var eanParity = [10]string{
+	"LLLLLL", "LLGLGG", "LLGGLG", "LLGGGL", "LGLLGG",
+	"LGGLLG", "LGGGLL", "LGLGLG", "LGLGGL", "LGGLGL",
+}
+
+// ean encodes data as an EAN-13, EAN-8 or UPC-A barcode, adding its check
+// digit if it is left out. A UPC-A barcode is an EAN-13 one whose first
+// digit is 0.
+func (b *Barcode) ean(data string) error {
+	n := map[Kind]int{EAN13: 13, EAN8: 8, UPCA: 12}[b.kind]
+	if !digits(data) || len(data) != n && len(data) != n-1 {
+		return fmt.Errorf("%w: want %d or %d digits, got %q", ErrInvalid, n-1, n, data)
+	}
+	check := checkDigit(data[:n-1])
+	if len(data) == n && data[n-1] != check {
+		return fmt.Errorf("%w: %q should end in %c", ErrChecksum, data, check)
+	}
+	b.text = data[:n-1] + string(check)
+
+	d := b.text
+	if b.kind == UPCA {
+		d = "0" + d
+	}
+	parity := "LLLL"
+	left := d[:len(d)/2]
+	if b.kind != EAN8 {
+		parity = eanParity[d[0]-'0']
+		left = d[1:7]
+	}
+	right := d[len(d)-len(left):]
+
+	b.guard("101")
+	for i, c := range left {
+		code := eanL[c-'0']
+		if parity[i] == 'G' {
+			code = reverse(complement(code))
+		}
+		b.bits(code, b.kind == UPCA && i == 0)
+	}
+	b.guard("01010")
+	for i, c := range right {
+		b.bits(complement(eanL[c-'0']), b.kind == UPCA && i == len(right)-1)
+	}
+	b.guard("101")
+
+	// The quiet zones leave room for the digits outside the bars.
+	b.quiet = 11
+	if b.kind == EAN8 {
+		b.quiet = 7
+	}
+	return nil
+}
+
+// checkDigit returns the check digit of the EAN or UPC digits s: the one
+// that makes the sum of the digits, weighted 3 and 1 from the right, a
+// multiple of 10.
+func checkDigit(s string) byte {
+	sum := 0
+	for i := range len(s) {
+		w := 1
+		if i%2 == 0 {
+			w = 3
+		}
+		sum += w * int(s[len(s)-1-i]-'0')
+	}
+	return byte('0' + (10-sum%10)%10)
+}
+
+// guard adds the modules of a guard pattern of 0s and 1s.
+func (b *Barcode) guard(pattern string) {
+	b.bits(pattern, true)
+}
+
+// bits adds the modules of a pattern of 0s and 1s, as guard bars or not.
+func (b *Barcode) bits(pattern string, guard bool) {
+	for _, c := range pattern {
+		b.modules = append(b.modules, c == '1')
+		b.guards = append(b.guards, guard)
+	}
+}
+
+func complement(s string) string {
+	out := []byte(s)
+	for i, c := range out {
+		out[i] = '0' + '1' - c
+	}
+	return string(out)
+}
+
+func reverse(s string) string {
+	out := []byte(s)
+	for i, j := 0, len(out)-1; i < j; i, j = i+1, j-1 {
+		out[i], out[j] = out[j], out[i]
+	}
+	return string(out)
+}
+
+// eanText draws the digits of an EAN or UPC barcode whose bars start at
+// x0: each half under its half of the bars, between the guards, and the
+// first digit of an EAN-13 or UPC-A barcode, and the last of a UPC-A one,
+// outside them.
+func (b *Barcode) eanText(d *texter, x0, module float64) error {
+	at := func(m float64) float64 { return x0 + m*module }
+	t := b.text
+	type part struct {
+		s     string
+		x     float64
+		align int
+	}
+	var parts []part
+	switch b.kind {
+	case EAN13:
+		parts = []part{
+			{t[:1], at(-2), gopdf.Right},
+			{t[1:7], at(24), gopdf.Center},
+			{t[7:], at(71), gopdf.Center},
+		}
+	case EAN8:
+		parts = []part{
+			{t[:4], at(17), gopdf.Center},
+			{t[4:], at(50), gopdf.Center},
+		}
+	case UPCA:
+		parts = []part{
+			{t[:1], at(-2), gopdf.Right},
+			{t[1:6], at(27.5), gopdf.Center},
+			{t[6:11], at(67.5), gopdf.Center},
+			{t[11:], at(97), gopdf.Left},
+		}
+	}
+	for _, p := range parts {
+		if err := d.text(p.s, p.x, p.align); err != nil {
+			return err
+		}
+	}
+	return nil
+}
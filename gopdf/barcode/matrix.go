@@ -0,0 +1,137 @@
+package barcode
+
+import (
+	"errors"
+	"image/color"
+
+	"github.com/signintech/gopdf"
+
+	"pdf-tutorial/gopdf/page"
+	"pdf-tutorial/gopdf/shape"
+)
+
+// ErrTooLong is returned by NewQR and NewDataMatrix for data that does not
+// fit in the largest symbol, or in the QR version asked for.
+var ErrTooLong = errors.New("barcode: data too long")
+
+// Level is the error correction level of a QR code: the higher it is, the
+// more of a damaged code can still be read, and the larger the code.
+type Level int
+
+const (
+	LevelM Level = iota // Recovers about 15% of the codewords
+	LevelL              // About 7%
+	LevelQ              // About 25%
+	LevelH              // About 30%
+)
+
+func (l Level) String() string {
+	if l < LevelM || l > LevelH {
+		return "Level(?)"
+	}
+	return [...]string{"M", "L", "Q", "H"}[l]
+}
+
+// MatrixOptions controls how a QR code or DataMatrix is encoded and drawn.
+// Zero fields get the default given in their comment.
+type MatrixOptions struct {
+	Level   Level // Error correction of a QR code (LevelM)
+	Version int   // QR version, 1 to 40, 21 to 177 modules wide (the smallest that fits)
+
+	// Quiet is the width of the quiet zone around the symbol, in modules:
+	// 4 for a QR code and 1 for a DataMatrix by default, none if negative.
+	Quiet int
+
+	Color      color.Color // Color of the dark modules (black)
+	Background color.Color // Color of the light modules and the quiet zone (none)
+}
+
+func (o *MatrixOptions) setDefaults(quiet int) {
+	if o.Quiet == 0 {
+		o.Quiet = quiet
+	} else if o.Quiet < 0 {
+		o.Quiet = 0
+	}
+	if o.Color == nil {
+		o.Color = color.Black
+	}
+}
+
+// Matrix is an encoded two-dimensional barcode, a QR code or a DataMatrix,
+// drawn by Draw.
+type Matrix struct {
+	size  int    // Width and height of the symbol, in modules
+	dark  []bool // Modules of the symbol, row by row
+	opts  MatrixOptions
+	quiet int
+}
+
+func newMatrix(size int, opts MatrixOptions, quiet int) *Matrix {
+	opts.setDefaults(quiet)
+	return &Matrix{size: size, dark: make([]bool, size*size), opts: opts, quiet: opts.Quiet}
+}
+
+func (m *Matrix) set(x, y int, dark bool) {
+	m.dark[y*m.size+x] = dark
+}
+
+// Modules returns the width and height of m in modules, its quiet zone
+// included: a QR code is easiest to scan if its modules are at least
+// 0.5 mm wide.
+func (m *Matrix) Modules() int {
+	return m.size + 2*m.quiet
+}
+
+// Dark reports whether the module of the symbol at column x and row y, from
+// its top left corner and without the quiet zone, is dark.
+func (m *Matrix) Dark(x, y int) bool {
+	if x < 0 || y < 0 || x >= m.size || y >= m.size {
+		return false
+	}
+	return m.dark[y*m.size+x]
+}
+
+// Draw draws m in box, whose coordinates are in the unit of the document,
+// as large as fits and centered. If box has no width or no height, it is
+// as wide as it is high, or as high as it is wide.
+func (m *Matrix) Draw(pdf *gopdf.GoPdf, box page.Box) error {
+	if box.W <= 0 {
+		box.W = box.H
+	} else if box.H <= 0 {
+		box.H = box.W
+	}
+	side := min(box.W, box.H)
+	if side <= 0 {
+		return errors.New("barcode: empty box")
+	}
+	module := side / float64(m.Modules())
+	x0 := box.X + (box.W-side)/2
+	y0 := box.Y + (box.H-side)/2
+
+	if m.opts.Background != nil {
+		err := shape.New().Rect(x0, y0, side, side).Draw(pdf, shape.Style{Fill: m.opts.Background})
+		if err != nil {
+			return err
+		}
+	}
+
+	// The dark modules of a row go in a rectangle for each run of them.
+	x0 += float64(m.quiet) * module
+	y0 += float64(m.quiet) * module
+	p := shape.New()
+	for y := range m.size {
+		for x := 0; x < m.size; {
+			if !m.Dark(x, y) {
+				x++
+				continue
+			}
+			end := x
+			for end < m.size && m.Dark(end, y) {
+				end++
+			}
+			p.Rect(x0+float64(x)*module, y0+float64(y)*module, float64(end-x)*module, module)
+			x = end
+		}
+	}
+	return p.Draw(pdf, shape.Style{Fill: m.opts.Color})
+}
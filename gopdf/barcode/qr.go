@@ -0,0 +1,478 @@
+package barcode
+
+import (
+	"fmt"
+	"strings"
+)
+
+// qrECC are the error correction codewords of each block, and qrBlocks the
+// number of blocks, of the QR versions 1 to 40, by Level.
+var (
+	qrECC = [4][41]int{
+		LevelM: {0, 10, 16, 26, 18, 24, 16, 18, 22, 22, 26, 30, 22, 22, 24, 24, 28, 28, 26, 26, 26, 26, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28},
+		LevelL: {0, 7, 10, 15, 20, 26, 18, 20, 24, 30, 18, 20, 24, 26, 30, 22, 24, 28, 30, 28, 28, 28, 28, 30, 30, 26, 28, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30},
+		LevelQ: {0, 13, 22, 18, 26, 18, 24, 18, 22, 20, 24, 28, 26, 24, 20, 30, 24, 28, 28, 26, 30, 28, 30, 30, 30, 30, 28, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30},
+		LevelH: {0, 17, 28, 22, 16, 22, 28, 26, 26, 24, 28, 24, 28, 22, 24, 24, 30, 28, 28, 26, 28, 30, 24, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30},
+	}
+	qrBlocks = [4][41]int{
+		LevelM: {0, 1, 1, 1, 2, 2, 4, 4, 4, 5, 5, 5, 8, 9, 9, 10, 10, 11, 13, 14, 16, 17, 17, 18, 20, 21, 23, 25, 26, 28, 29, 31, 33, 35, 37, 38, 40, 43, 45, 47, 49},
+		LevelL: {0, 1, 1, 1, 1, 1, 2, 2, 2, 2, 4, 4, 4, 4, 4, 6, 6, 6, 6, 7, 8, 8, 9, 9, 10, 12, 12, 12, 13, 14, 15, 16, 17, 18, 19, 19, 20, 21, 22, 24, 25},
+		LevelQ: {0, 1, 1, 2, 2, 4, 4, 6, 6, 8, 8, 8, 10, 12, 16, 12, 17, 16, 18, 21, 20, 23, 23, 25, 27, 29, 34, 34, 35, 38, 40, 43, 45, 48, 51, 53, 56, 59, 62, 65, 68},
+		LevelH: {0, 1, 1, 2, 4, 4, 4, 5, 6, 8, 8, 11, 11, 16, 16, 18, 16, 19, 21, 25, 25, 25, 34, 30, 32, 35, 37, 40, 42, 45, 48, 51, 54, 57, 60, 63, 66, 70, 74, 77, 81},
+	}
+
+	// qrLevelBits are the bits of each Level in the format information.
+	qrLevelBits = [4]int{LevelM: 0, LevelL: 1, LevelQ: 3, LevelH: 2}
+)
+
+// qrAlphanumeric are the characters of the alphanumeric mode, by value.
+const qrAlphanumeric = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZ $%*+-./:"
+
+// qrMode is an encoding mode of QR code data.
+type qrMode struct {
+	indicator int
+	count     [3]int // Bits of the character count in versions 1-9, 10-26 and 27-40
+}
+
+var (
+	qrNumeric = qrMode{1, [3]int{10, 12, 14}}
+	qrAlnum   = qrMode{2, [3]int{9, 11, 13}}
+	qrByte    = qrMode{4, [3]int{8, 16, 16}}
+)
+
+// NewQR encodes data as a QR code: in numeric mode if it is all digits,
+// alphanumeric mode if it is all upper case letters, digits and the
+// symbols of qrAlphanumeric, such as an upper case URL, and else as UTF-8
+// bytes. The version is the smallest that fits data at opts.Level, unless
+// opts.Version asks for a larger one.
+func NewQR(data string, opts MatrixOptions) (*Matrix, error) {
+	if opts.Level < LevelM || opts.Level > LevelH {
+		return nil, fmt.Errorf("barcode: unknown QR level %d", opts.Level)
+	}
+	if opts.Version < 0 || opts.Version > 40 {
+		return nil, fmt.Errorf("barcode: QR version %d is not 1 to 40", opts.Version)
+	}
+
+	mode := qrByte
+	switch {
+	case data != "" && digits(data):
+		mode = qrNumeric
+	case strings.Trim(data, qrAlphanumeric) == "":
+		mode = qrAlnum
+	}
+	version := max(opts.Version, 1)
+	for ; ; version++ {
+		if version > 40 || opts.Version > 0 && version > opts.Version {
+			return nil, fmt.Errorf("%w: %d bytes for a QR code at level %v", ErrTooLong, len(data), opts.Level)
+		}
+		if qrBits(mode, len(data), version) <= 8*qrDataCodewords(version, opts.Level) {
+			break
+		}
+	}
+
+	q := &qrEncoder{
+		Matrix:   newMatrix(version*4+17, opts, 4),
+		version:  version,
+		function: make([]bool, (version*4+17)*(version*4+17)),
+	}
+	q.functionPatterns()
+	q.codewords(qrSplit(qrData(data, mode, version, opts.Level), version, opts.Level))
+
+	// The mask is the one that leaves the fewest patterns that are
+	// hard to read, such as long runs and blocks of one color.
+	best, penalty := 0, -1
+	for mask := range 8 {
+		q.mask(mask)
+		q.format(opts.Level, mask)
+		if p := q.penalty(); penalty < 0 || p < penalty {
+			best, penalty = mask, p
+		}
+		q.mask(mask)
+	}
+	q.mask(best)
+	q.format(opts.Level, best)
+	return q.Matrix, nil
+}
+
+// qrBits returns the number of bits of n characters of data in mode.
+func qrBits(mode qrMode, n, version int) int {
+	bits := 4 + mode.count[qrCountIndex(version)]
+	switch mode {
+	case qrNumeric:
+		bits += n/3*10 + [3]int{0, 4, 7}[n%3]
+	case qrAlnum:
+		bits += n/2*11 + n%2*6
+	default:
+		bits += n * 8
+	}
+	if n >= 1<<mode.count[qrCountIndex(version)] {
+		return 1 << 30
+	}
+	return bits
+}
+
+func qrCountIndex(version int) int {
+	switch {
+	case version <= 9:
+		return 0
+	case version <= 26:
+		return 1
+	}
+	return 2
+}
+
+// qrRawModules returns the number of modules of a version left for the
+// data and error correction codewords, once the function patterns and the
+// format and version information are in place.
+func qrRawModules(version int) int {
+	n := (16*version+128)*version + 64
+	if version >= 2 {
+		align := version/7 + 2
+		n -= (25*align-10)*align - 55
+		if version >= 7 {
+			n -= 36
+		}
+	}
+	return n
+}
+
+func qrDataCodewords(version int, level Level) int {
+	return qrRawModules(version)/8 - qrECC[level][version]*qrBlocks[level][version]
+}
+
+// bitWriter collects bits, most significant first.
+type bitWriter struct {
+	bytes []byte
+	n     int // Number of bits written
+}
+
+func (w *bitWriter) write(v, bits int) {
+	for i := bits - 1; i >= 0; i-- {
+		if w.n%8 == 0 {
+			w.bytes = append(w.bytes, 0)
+		}
+		if v>>i&1 == 1 {
+			w.bytes[w.n/8] |= 0x80 >> (w.n % 8)
+		}
+		w.n++
+	}
+}
+
+// qrData returns the data codewords of data in mode, padded to fill the
+// version.
+func qrData(data string, mode qrMode, version int, level Level) []byte {
+	var w bitWriter
+	w.write(mode.indicator, 4)
+	w.write(len(data), mode.count[qrCountIndex(version)])
+	switch mode {
+	case qrNumeric:
+		for i := 0; i < len(data); i += 3 {
+			group := data[i:min(i+3, len(data))]
+			v := 0
+			for _, c := range group {
+				v = v*10 + int(c-'0')
+			}
+			w.write(v, [4]int{0, 4, 7, 10}[len(group)])
+		}
+	case qrAlnum:
+		for i := 0; i+1 < len(data); i += 2 {
+			v := strings.IndexByte(qrAlphanumeric, data[i])*45 + strings.IndexByte(qrAlphanumeric, data[i+1])
+			w.write(v, 11)
+		}
+		if len(data)%2 == 1 {
+			w.write(strings.IndexByte(qrAlphanumeric, data[len(data)-1]), 6)
+		}
+	default:
+		for i := 0; i < len(data); i++ {
+			w.write(int(data[i]), 8)
+		}
+	}
+
+	// A terminator of up to four 0 bits, then 0 bits to the end of the
+	// byte, then pad bytes.
+	capacity := qrDataCodewords(version, level)
+	w.write(0, min(4, capacity*8-w.n))
+	w.write(0, (8-w.n%8)%8)
+	for pad := 0xec; len(w.bytes) < capacity; pad ^= 0xec ^ 0x11 {
+		w.write(pad, 8)
+	}
+	return w.bytes
+}
+
+// qrSplit splits data into the blocks of the version, adds the error
+// correction codewords of each and interleaves them.
+func qrSplit(data []byte, version int, level Level) []byte {
+	n := qrBlocks[level][version]
+	ecc := qrECC[level][version]
+	raw := qrRawModules(version) / 8
+	short := n - raw%n // Blocks with a data codeword less than the others
+	shortLen := raw/n - ecc
+
+	var blocks, eccs [][]byte
+	for i, k := 0, 0; i < n; i++ {
+		size := shortLen
+		if i >= short {
+			size++
+		}
+		blocks = append(blocks, data[k:k+size])
+		eccs = append(eccs, qrField.ecc(data[k:k+size], ecc, 0))
+		k += size
+	}
+
+	out := make([]byte, 0, raw)
+	for i := range shortLen + 1 {
+		for _, b := range blocks {
+			if i < len(b) {
+				out = append(out, b[i])
+			}
+		}
+	}
+	for i := range ecc {
+		for _, e := range eccs {
+			out = append(out, e[i])
+		}
+	}
+	return out
+}
+
+// qrEncoder places the modules of a QR code.
+type qrEncoder struct {
+	*Matrix
+	version  int
+	function []bool // Modules of the function patterns, which are not masked
+}
+
+func (q *qrEncoder) setFunction(x, y int, dark bool) {
+	q.set(x, y, dark)
+	q.function[y*q.size+x] = true
+}
+
+// functionPatterns draws the finder, timing and alignment patterns, and
+// reserves the modules of the format and version information.
+func (q *qrEncoder) functionPatterns() {
+	size := q.size
+	for i := range size {
+		q.setFunction(6, i, i%2 == 0)
+		q.setFunction(i, 6, i%2 == 0)
+	}
+
+	// The finder patterns, with their light separators.
+	for _, c := range [][2]int{{3, 3}, {size - 4, 3}, {3, size - 4}} {
+		for dy := -4; dy <= 4; dy++ {
+			for dx := -4; dx <= 4; dx++ {
+				x, y := c[0]+dx, c[1]+dy
+				if x < 0 || y < 0 || x >= size || y >= size {
+					continue
+				}
+				d := max(abs(dx), abs(dy))
+				q.setFunction(x, y, d != 2 && d != 4)
+			}
+		}
+	}
+
+	// The alignment patterns, but those on the finder patterns.
+	pos := q.alignment()
+	last := len(pos) - 1
+	for i, cy := range pos {
+		for j, cx := range pos {
+			if i == 0 && j == 0 || i == 0 && j == last || i == last && j == 0 {
+				continue
+			}
+			for dy := -2; dy <= 2; dy++ {
+				for dx := -2; dx <= 2; dx++ {
+					q.setFunction(cx+dx, cy+dy, max(abs(dx), abs(dy)) != 1)
+				}
+			}
+		}
+	}
+
+	q.format(LevelM, 0)
+	if q.version >= 7 {
+		rem := q.version
+		for range 12 {
+			rem = rem<<1 ^ (rem>>11)*0x1f25
+		}
+		bits := q.version<<12 | rem
+		for i := range 18 {
+			dark := bits>>i&1 == 1
+			a, b := size-11+i%3, i/3
+			q.setFunction(a, b, dark)
+			q.setFunction(b, a, dark)
+		}
+	}
+}
+
+// alignment returns the centers of the alignment patterns of the version,
+// across and down.
+func (q *qrEncoder) alignment() []int {
+	if q.version == 1 {
+		return nil
+	}
+	n := q.version/7 + 2
+	step := (q.version*8 + n*3 + 5) / (n*4 - 4) * 2
+	pos := make([]int, n)
+	pos[0] = 6
+	for i, p := n-1, q.size-7; i >= 1; i, p = i-1, p-step {
+		pos[i] = p
+	}
+	return pos
+}
+
+// format draws the two copies of the format information, the level and
+// the mask, and the dark module beside them.
+func (q *qrEncoder) format(level Level, mask int) {
+	data := qrLevelBits[level]<<3 | mask
+	rem := data
+	for range 10 {
+		rem = rem<<1 ^ (rem>>9)*0x537
+	}
+	bits := (data<<10 | rem) ^ 0x5412
+	bit := func(i int) bool { return bits>>i&1 == 1 }
+
+	for i := range 6 {
+		q.setFunction(8, i, bit(i))
+	}
+	q.setFunction(8, 7, bit(6))
+	q.setFunction(8, 8, bit(7))
+	q.setFunction(7, 8, bit(8))
+	for i := 9; i < 15; i++ {
+		q.setFunction(14-i, 8, bit(i))
+	}
+
+	size := q.size
+	for i := range 8 {
+		q.setFunction(size-1-i, 8, bit(i))
+	}
+	for i := 8; i < 15; i++ {
+		q.setFunction(8, size-15+i, bit(i))
+	}
+	q.setFunction(8, size-8, true)
+}
+
+// codewords places the codewords in two module wide columns, zigzagging up
+// and down from the bottom right corner, around the function patterns.
+func (q *qrEncoder) codewords(data []byte) {
+	i := 0
+	for right := q.size - 1; right >= 1; right -= 2 {
+		if right == 6 {
+			right = 5 // The vertical timing pattern
+		}
+		up := (right+1)&2 == 0
+		for vert := range q.size {
+			y := vert
+			if up {
+				y = q.size - 1 - vert
+			}
+			for j := range 2 {
+				x := right - j
+				if q.function[y*q.size+x] || i >= len(data)*8 {
+					continue
+				}
+				q.set(x, y, data[i/8]>>(7-i%8)&1 == 1)
+				i++
+			}
+		}
+	}
+}
+
+// mask flips the modules of mask that are not function patterns; masking
+// twice undoes it.
+func (q *qrEncoder) mask(mask int) {
+	for y := range q.size {
+		for x := range q.size {
+			var flip bool
+			switch mask {
+			case 0:
+				flip = (x+y)%2 == 0
+			case 1:
+				flip = y%2 == 0
+			case 2:
+				flip = x%3 == 0
+			case 3:
+				flip = (x+y)%3 == 0
+			case 4:
+				flip = (x/3+y/2)%2 == 0
+			case 5:
+				flip = x*y%2+x*y%3 == 0
+			case 6:
+				flip = (x*y%2+x*y%3)%2 == 0
+			case 7:
+				flip = ((x+y)%2+x*y%3)%2 == 0
+			}
+			if flip && !q.function[y*q.size+x] {
+				i := y*q.size + x
+				q.dark[i] = !q.dark[i]
+			}
+		}
+	}
+}
+
+// penalty scores how hard the symbol is to read, by the rules of the QR
+// code specification for choosing a mask.
+func (q *qrEncoder) penalty() int {
+	size := q.size
+	score := 0
+	for _, rows := range []bool{true, false} {
+		at := func(i, j int) bool {
+			if rows {
+				return q.Dark(j, i)
+			}
+			return q.Dark(i, j)
+		}
+		for i := range size {
+			// Runs of five or more modules of one color.
+			run := 1
+			for j := 1; j <= size; j++ {
+				if j < size && at(i, j) == at(i, j-1) {
+					run++
+					continue
+				}
+				if run >= 5 {
+					score += run - 2
+				}
+				run = 1
+			}
+			// Patterns like those of the finders, light on one side.
+			for j := 0; j+11 <= size; j++ {
+				var bits int
+				for k := range 11 {
+					bits <<= 1
+					if at(i, j+k) {
+						bits |= 1
+					}
+				}
+				if bits == 0b10111010000 || bits == 0b00001011101 {
+					score += 40
+				}
+			}
+		}
+	}
+
+	// Blocks of two by two modules of one color.
+	dark := 0
+	for y := range size {
+		for x := range size {
+			if q.Dark(x, y) {
+				dark++
+			}
+			if x+1 < size && y+1 < size {
+				c := q.Dark(x, y)
+				if c == q.Dark(x+1, y) && c == q.Dark(x, y+1) && c == q.Dark(x+1, y+1) {
+					score += 3
+				}
+			}
+		}
+	}
+
+	// A share of dark modules far from a half.
+	total := size * size
+	score += ((abs(dark*20-total*10)+total-1)/total - 1) * 10
+	return score
+}
+
+func abs(v int) int {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
@@ -0,0 +1,66 @@
+package barcode
+
+// field is a Galois field of 256 elements, GF(2^8), with the tables of the
+// powers and logarithms of its generator 2.
+type field struct {
+	exp [510]byte
+	log [256]int
+}
+
+// QR codes and DataMatrix build their field from different polynomials.
+var (
+	qrField = newField(0x11d)
+	dmField = newField(0x12d)
+)
+
+func newField(poly int) *field {
+	f := new(field)
+	x := 1
+	for i := range 255 {
+		f.exp[i] = byte(x)
+		f.exp[i+255] = byte(x)
+		f.log[x] = i
+		x <<= 1
+		if x >= 256 {
+			x ^= poly
+		}
+	}
+	return f
+}
+
+func (f *field) mul(a, b byte) byte {
+	if a == 0 || b == 0 {
+		return 0
+	}
+	return f.exp[f.log[a]+f.log[b]]
+}
+
+// ecc returns the n Reed-Solomon error correction codewords of data, for
+// the generator polynomial whose roots are the powers of 2 from first to
+// first+n-1.
+func (f *field) ecc(data []byte, n, first int) []byte {
+	// gen holds the coefficients of the generator polynomial, highest
+	// first, leaving out the leading 1.
+	gen := make([]byte, n)
+	gen[n-1] = 1
+	for i := range n {
+		root := f.exp[(first+i)%255]
+		for j := range n {
+			gen[j] = f.mul(gen[j], root)
+			if j+1 < n {
+				gen[j] ^= gen[j+1]
+			}
+		}
+	}
+
+	rem := make([]byte, n)
+	for _, b := range data {
+		factor := b ^ rem[0]
+		copy(rem, rem[1:])
+		rem[n-1] = 0
+		for j := range n {
+			rem[j] ^= f.mul(gen[j], factor)
+		}
+	}
+	return rem
+}
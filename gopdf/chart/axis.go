@@ -0,0 +1,137 @@
+package chart
+
+import (
+	"math"
+	"strconv"
+)
+
+// valueRange returns the smallest and largest value the value axis must
+// show. Bars and areas are drawn from zero, so their range includes it.
+func (d *drawer) valueRange() (lo, hi float64) {
+	lo, hi = math.Inf(1), math.Inf(-1)
+	if d.c.kind == StackedBar {
+		var pos, neg []float64
+		for _, s := range d.c.series {
+			for i, v := range s.Values {
+				for len(pos) <= i {
+					pos, neg = append(pos, 0), append(neg, 0)
+				}
+				if v >= 0 {
+					pos[i] += v
+				} else {
+					neg[i] += v
+				}
+			}
+		}
+		for i := range pos {
+			lo, hi = min(lo, neg[i]), max(hi, pos[i])
+		}
+	} else {
+		for _, s := range d.c.series {
+			for _, v := range s.Values {
+				lo, hi = min(lo, v), max(hi, v)
+			}
+		}
+	}
+	if math.IsInf(lo, 1) {
+		return 0, 1
+	}
+	if d.c.kind == Bar || d.c.kind == StackedBar || d.c.kind == Area {
+		lo, hi = min(lo, 0), max(hi, 0)
+	}
+	return lo, hi
+}
+
+// xRange returns the smallest and largest X of the series of a scatter
+// chart.
+func (d *drawer) xRange() (lo, hi float64) {
+	lo, hi = math.Inf(1), math.Inf(-1)
+	for _, s := range d.c.series {
+		for i := range s.Values {
+			x := xOf(s, i)
+			lo, hi = min(lo, x), max(hi, x)
+		}
+	}
+	if math.IsInf(lo, 1) {
+		return 0, 1
+	}
+	return lo, hi
+}
+
+// xOf returns the X of value i of s: s.X[i], or i if s has no X for it.
+func xOf(s Series, i int) float64 {
+	if i < len(s.X) {
+		return s.X[i]
+	}
+	return float64(i)
+}
+
+// scale returns the range of the value axis and the step of its ticks:
+// Options.Min and Max if set, or else the range of the values rounded out
+// to ticks.
+func (d *drawer) scale(lo, hi float64) (float64, float64, float64) {
+	if d.opts.Min != 0 || d.opts.Max != 0 {
+		lo, hi = d.opts.Min, d.opts.Max
+		if hi <= lo {
+			hi = lo + 1
+		}
+		return lo, hi, niceNum((hi-lo)/float64(d.opts.Ticks-1), true)
+	}
+	return niceRange(lo, hi, d.opts.Ticks)
+}
+
+// niceRange rounds lo and hi out to a step of about (hi-lo)/(n-1) that is
+// 1, 2 or 5 times a power of ten, and returns them with the step.
+func niceRange(lo, hi float64, n int) (float64, float64, float64) {
+	if hi <= lo {
+		pad := math.Max(math.Abs(lo)*0.1, 1)
+		lo, hi = lo-pad, hi+pad
+	}
+	step := niceNum(niceNum(hi-lo, false)/float64(n-1), true)
+	return math.Floor(lo/step) * step, math.Ceil(hi/step) * step, step
+}
+
+// niceNum returns a number of 1, 2, 5 or 10 times a power of ten close to
+// x: the nearest if round is true, and the smallest not below x if not.
+func niceNum(x float64, round bool) float64 {
+	exp := math.Floor(math.Log10(x))
+	f := x / math.Pow(10, exp)
+	var nf float64
+	switch {
+	case round && f < 1.5, !round && f <= 1:
+		nf = 1
+	case round && f < 3, !round && f <= 2:
+		nf = 2
+	case round && f < 7, !round && f <= 5:
+		nf = 5
+	default:
+		nf = 10
+	}
+	return nf * math.Pow(10, exp)
+}
+
+// ticks returns the multiples of step from lo to hi.
+func ticks(lo, hi, step float64) []float64 {
+	var ts []float64
+	first := math.Ceil(lo/step-1e-9) * step
+	for i := 0; ; i++ {
+		v := first + float64(i)*step
+		if v > hi+step*1e-9 {
+			return ts
+		}
+		ts = append(ts, v)
+	}
+}
+
+// decimals returns the number of decimals multiples of step need.
+func decimals(step float64) int {
+	return max(0, int(-math.Floor(math.Log10(step)+1e-9)))
+}
+
+// format writes a value for a tick label.
+func (d *drawer) format(v float64) string {
+	if d.opts.Format != nil {
+		return d.opts.Format(v)
+	}
+	return strconv.FormatFloat(v, 'f', d.decimals, 64)
+}
@@ -0,0 +1,159 @@
+// Package chart draws bar, stacked bar, line, area, scatter and pie charts
+// into a box on a page. Charts are drawn with gopdf's lines, rectangles and
+// polygons, so they stay sharp at any zoom and print size; no image is
+// made.
+//
+//	c := chart.New(chart.Bar, chart.Options{Title: "Sales by quarter"})
+//	c.SetLabels("Q1", "Q2", "Q3", "Q4")
+//	c.AddSeries("2024", 120, 150, 90, 180)
+//	c.AddSeries("2025", 140, 160, 130, 210)
+//	err := c.Draw(reg, page.Box{X: 50, Y: 100, W: 400, H: 250})
+//
+// Every chart but a pie has a value axis on the left with tick labels and
+// grid lines, and a category axis below, labelled with the labels set by
+// SetLabels. A scatter chart has a value axis below instead, for the X of
+// its series. The legend names the series, or the slices of a pie.
+package chart
+
+import (
+	"image/color"
+
+	"pdf-tutorial/gopdf/fonts"
+)
+
+// Kind is the type of a chart.
+type Kind int
+
+const (
+	Bar        Kind = iota // Bars side by side for every label
+	StackedBar             // The series stacked in one bar for every label
+	Line                   // A line through the values of every series
+	Area                   // Line, with the area below the line filled
+	Scatter                // Points at the X and Values of every series
+	Pie                    // Slices for the values of the first series
+)
+
+// Legend is where the legend goes.
+type Legend int
+
+const (
+	LegendRight Legend = iota
+	LegendBottom
+	LegendNone
+)
+
+// DefaultPalette is the colors of the series, in order, if Options has no
+// Palette.
+var DefaultPalette = []color.Color{
+	color.RGBA{31, 119, 180, 255},  // blue
+	color.RGBA{255, 127, 14, 255},  // orange
+	color.RGBA{44, 160, 44, 255},   // green
+	color.RGBA{214, 39, 40, 255},   // red
+	color.RGBA{148, 103, 189, 255}, // purple
+	color.RGBA{140, 86, 75, 255},   // brown
+	color.RGBA{227, 119, 194, 255}, // pink
+	color.RGBA{127, 127, 127, 255}, // gray
+	color.RGBA{188, 189, 34, 255},  // olive
+	color.RGBA{23, 190, 207, 255},  // cyan
+}
+
+// Options controls how a chart looks. Zero fields get the default given in
+// their comment.
+type Options struct {
+	Title  string
+	Family string      // Font family of the text (fonts.Sans)
+	Size   float64     // Font size of the labels, in points (8)
+	Color  color.Color // Color of the text and the axes (dark gray)
+
+	Palette   []color.Color // Colors of the series (DefaultPalette)
+	Legend    Legend        // Position of the legend (LegendRight)
+	GridColor color.Color   // Color of the grid lines (light gray)
+
+	// Ticks is the number of ticks the value axis should have, about (6).
+	Ticks int
+
+	// Min and Max are the range of the value axis. If both are zero, the
+	// range is taken from the values and rounded out to ticks.
+	Min, Max float64
+
+	// Format writes the numbers of the tick labels. Nil writes them with
+	// as many decimals as the ticks need. Pie slices are labelled with
+	// their share in whole percents.
+	Format func(v float64) string
+
+	LineWidth float64 // Width of the lines of Line and Area charts, in points (1.5)
+	Marker    float64 // Radius of the points of Line and Scatter charts, in points (2.5)
+}
+
+func (o *Options) setDefaults() {
+	if o.Family == "" {
+		o.Family = fonts.Sans
+	}
+	if o.Size <= 0 {
+		o.Size = 8
+	}
+	if o.Color == nil {
+		o.Color = color.RGBA{60, 60, 60, 255}
+	}
+	if len(o.Palette) == 0 {
+		o.Palette = DefaultPalette
+	}
+	if o.GridColor == nil {
+		o.GridColor = color.RGBA{220, 220, 220, 255}
+	}
+	if o.Ticks <= 1 {
+		o.Ticks = 6
+	}
+	if o.LineWidth <= 0 {
+		o.LineWidth = 1.5
+	}
+	if o.Marker <= 0 {
+		o.Marker = 2.5
+	}
+}
+
+// Series is a named list of values.
+type Series struct {
+	Name   string
+	Values []float64
+	X      []float64   // X of every value, for a Scatter chart
+	Color  color.Color // Color of the series (the next color of the palette)
+}
+
+// Chart is the kind, labels and series of a chart, drawn by Draw.
+type Chart struct {
+	kind   Kind
+	opts   Options
+	labels []string
+	series []Series
+}
+
+// New returns a chart with no data.
+func New(kind Kind, opts Options) *Chart {
+	return &Chart{kind: kind, opts: opts}
+}
+
+// SetLabels sets the labels of the values: the categories of a bar, line
+// or area chart, or the slices of a pie.
+func (c *Chart) SetLabels(labels ...string) {
+	c.labels = labels
+}
+
+// AddSeries adds a series of values, one for every label.
+func (c *Chart) AddSeries(name string, values ...float64) {
+	c.series = append(c.series, Series{Name: name, Values: values})
+}
+
+// Add adds a series, e.g. one with its own color, or with X values for a
+// Scatter chart.
+func (c *Chart) Add(s Series) {
+	c.series = append(c.series, s)
+}
+
+// color returns the color of series i.
+func (c *Chart) color(opts Options, i int) color.Color {
+	if i < len(c.series) && c.series[i].Color != nil {
+		return c.series[i].Color
+	}
+	return opts.Palette[i%len(opts.Palette)]
+}
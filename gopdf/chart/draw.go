@@ -0,0 +1,456 @@
+package chart
+
+import (
+	"image/color"
+	"math"
+	"strconv"
+
+	"pdf-tutorial/gopdf/fonts"
+	"pdf-tutorial/gopdf/page"
+	"pdf-tutorial/gopdf/richtext"
+
+	"github.com/signintech/gopdf"
+)
+
+// Draw draws the chart in box, whose coordinates are in the unit of the
+// document. Text is drawn with the fonts of reg; missing glyphs are
+// reported as by richtext.Missing.
+func (c *Chart) Draw(reg *fonts.Registry, box page.Box) error {
+	opts := c.opts
+	opts.setDefaults()
+	d := &drawer{c: c, reg: reg, pdf: reg.PDF(), opts: opts}
+
+	err := d.draw(box)
+	d.pdf.ClearTransparency()
+	d.pdf.SetStrokeColor(0, 0, 0)
+	d.pdf.SetFillColor(0, 0, 0)
+	d.pdf.SetTextColor(0, 0, 0)
+	if err == nil {
+		err = d.missing.Err()
+	}
+	return err
+}
+
+// drawer draws one chart.
+type drawer struct {
+	c        *Chart
+	reg      *fonts.Registry
+	pdf      *gopdf.GoPdf
+	opts     Options
+	decimals int // Decimals of the value ticks
+	missing  richtext.Missing
+}
+
+// pt returns v points in the unit of the document.
+func (d *drawer) pt(v float64) float64 {
+	return d.pdf.PointsToUnits(v)
+}
+
+// draw draws the title, the legend and the chart in what is left of box.
+func (d *drawer) draw(box page.Box) error {
+	if d.opts.Title != "" {
+		size := d.opts.Size * 1.5
+		if err := d.text(d.opts.Title, box.CenterX(), box.Y+d.pt(size), gopdf.Center, size, fonts.Bold, d.opts.Color); err != nil {
+			return err
+		}
+		box.Y += d.pt(size * 1.8)
+		box.H -= d.pt(size * 1.8)
+	}
+
+	var names []string
+	var colors []color.Color
+	if d.c.kind == Pie {
+		if len(d.c.series) > 0 {
+			for i := range d.c.series[0].Values {
+				if i < len(d.c.labels) {
+					names = append(names, d.c.labels[i])
+					colors = append(colors, d.opts.Palette[i%len(d.opts.Palette)])
+				}
+			}
+		}
+	} else {
+		for i, s := range d.c.series {
+			if s.Name != "" {
+				names = append(names, s.Name)
+				colors = append(colors, d.c.color(d.opts, i))
+			}
+		}
+	}
+	if len(names) > 0 && d.opts.Legend != LegendNone {
+		var err error
+		if box, err = d.legend(box, names, colors); err != nil {
+			return err
+		}
+	}
+
+	if d.c.kind == Pie {
+		return d.pie(box)
+	}
+	return d.plot(box)
+}
+
+// legend draws a swatch and name for every entry, at the right or the
+// bottom of box, and returns what is left of box.
+func (d *drawer) legend(box page.Box, names []string, colors []color.Color) (page.Box, error) {
+	sw, gap, lh := d.pt(d.opts.Size), d.pt(4), d.pt(d.opts.Size*1.7)
+	widths := make([]float64, len(names))
+	for i, name := range names {
+		w, err := d.measure(name, d.opts.Size, fonts.Regular)
+		if err != nil {
+			return box, err
+		}
+		widths[i] = w
+	}
+
+	// Place the entries: in a column at the right, or in rows below.
+	xs, ys := make([]float64, len(names)), make([]float64, len(names))
+	if d.opts.Legend == LegendBottom {
+		x, row := box.X, 0
+		for i, w := range widths {
+			ew := sw + gap + w + d.pt(12)
+			if x > box.X && x+ew > box.Right() {
+				x, row = box.X, row+1
+			}
+			xs[i], ys[i] = x, float64(row)*lh
+			x += ew
+		}
+		h := float64(row+1) * lh
+		for i := range ys {
+			ys[i] += box.Bottom() - h
+		}
+		box.H -= h + d.pt(6)
+	} else {
+		w := sw + gap
+		for _, ew := range widths {
+			w = max(w, sw+gap+ew)
+		}
+		top := box.Y + max((box.H-lh*float64(len(names)))/2, 0)
+		for i := range names {
+			xs[i], ys[i] = box.Right()-w, top+float64(i)*lh
+		}
+		box.W -= w + d.pt(12)
+	}
+
+	for i, name := range names {
+		d.pdf.SetFillColor(richtext.RGB(colors[i]))
+		d.pdf.RectFromUpperLeftWithStyle(xs[i], ys[i]+(lh-sw)/2, sw, sw, "F")
+		if err := d.text(name, xs[i]+sw+gap, ys[i]+lh/2+d.pt(d.opts.Size)*0.35, gopdf.Left, d.opts.Size, fonts.Regular, d.opts.Color); err != nil {
+			return box, err
+		}
+	}
+	return box, nil
+}
+
+// plot draws the axes and the series of every chart but a pie.
+func (d *drawer) plot(box page.Box) error {
+	c := d.c
+	n := len(c.labels)
+	for _, s := range c.series {
+		n = max(n, len(s.Values))
+	}
+	if n == 0 {
+		return nil
+	}
+
+	lo, hi, step := d.scale(d.valueRange())
+	d.decimals = decimals(step)
+	marks := ticks(lo, hi, step)
+	labels := make([]string, len(marks))
+	labelW := 0.0
+	for i, v := range marks {
+		labels[i] = d.format(v)
+		w, err := d.measure(labels[i], d.opts.Size, fonts.Regular)
+		if err != nil {
+			return err
+		}
+		labelW = max(labelW, w)
+	}
+
+	fontH := d.pt(d.opts.Size)
+	plot := page.Box{X: box.X + labelW + d.pt(5), Y: box.Y + fontH/2}
+	plot.W = box.Right() - plot.X - d.pt(2)
+	plot.H = box.Bottom() - fontH*2 - plot.Y
+	if plot.W <= 0 || plot.H <= 0 {
+		return nil
+	}
+	y := func(v float64) float64 {
+		v = min(max(v, lo), hi)
+		return plot.Bottom() - (v-lo)/(hi-lo)*plot.H
+	}
+
+	// Grid lines and the labels of the value axis
+	d.pdf.SetLineWidth(d.pt(0.5))
+	for i, v := range marks {
+		d.pdf.SetStrokeColor(richtext.RGB(d.opts.GridColor))
+		d.pdf.Line(plot.X, y(v), plot.Right(), y(v))
+		if err := d.text(labels[i], plot.X-d.pt(4), y(v)+fontH*0.35, gopdf.Right, d.opts.Size, fonts.Regular, d.opts.Color); err != nil {
+			return err
+		}
+	}
+
+	// The category axis, or the X axis of a scatter chart
+	var x func(i int, v float64) float64
+	if c.kind == Scatter {
+		xlo, xhi := d.xRange()
+		xlo, xhi, xstep := niceRange(xlo, xhi, d.opts.Ticks)
+		x = func(_ int, v float64) float64 {
+			return plot.X + (v-xlo)/(xhi-xlo)*plot.W
+		}
+		dec := decimals(xstep)
+		for _, v := range ticks(xlo, xhi, xstep) {
+			d.pdf.SetStrokeColor(richtext.RGB(d.opts.GridColor))
+			d.pdf.Line(x(0, v), plot.Y, x(0, v), plot.Bottom())
+			label := strconv.FormatFloat(v, 'f', dec, 64)
+			if d.opts.Format != nil {
+				label = d.opts.Format(v)
+			}
+			if err := d.text(label, x(0, v), plot.Bottom()+fontH*1.4, gopdf.Center, d.opts.Size, fonts.Regular, d.opts.Color); err != nil {
+				return err
+			}
+		}
+	} else {
+		slot := plot.W / float64(n)
+		x = func(i int, _ float64) float64 {
+			return plot.X + (float64(i)+0.5)*slot
+		}
+		if err := d.categories(n, slot, x, plot.Bottom()+fontH*1.4); err != nil {
+			return err
+		}
+	}
+
+	var err error
+	switch c.kind {
+	case Bar:
+		d.bars(n, plot, y)
+	case StackedBar:
+		d.stacked(n, plot, y)
+	case Line, Area:
+		err = d.lines(x, y, c.kind == Area)
+	case Scatter:
+		d.points(x, y)
+	}
+	if err != nil {
+		return err
+	}
+
+	// The axes, over the series, the category axis at zero
+	d.pdf.SetStrokeColor(richtext.RGB(d.opts.Color))
+	d.pdf.SetLineWidth(d.pt(0.75))
+	d.pdf.Line(plot.X, plot.Y, plot.X, plot.Bottom())
+	d.pdf.Line(plot.X, y(0), plot.Right(), y(0))
+	return nil
+}
+
+// categories draws the labels below the categories, leaving out labels
+// at regular steps if they do not all fit.
+func (d *drawer) categories(n int, slot float64, x func(int, float64) float64, baseline float64) error {
+	widest := 0.0
+	for _, label := range d.c.labels {
+		w, err := d.measure(label, d.opts.Size, fonts.Regular)
+		if err != nil {
+			return err
+		}
+		widest = max(widest, w)
+	}
+	every := max(int(math.Ceil((widest+d.pt(4))/slot)), 1)
+	for i, label := range d.c.labels {
+		if i >= n || i%every != 0 {
+			continue
+		}
+		if err := d.text(label, x(i, 0), baseline, gopdf.Center, d.opts.Size, fonts.Regular, d.opts.Color); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// bars draws the series of a bar chart side by side in every category.
+func (d *drawer) bars(n int, plot page.Box, y func(float64) float64) {
+	slot := plot.W / float64(n)
+	group := slot * 0.75
+	bw := group / float64(len(d.c.series))
+	for s, series := range d.c.series {
+		d.pdf.SetFillColor(richtext.RGB(d.c.color(d.opts, s)))
+		for i, v := range series.Values {
+			x := plot.X + float64(i)*slot + (slot-group)/2 + float64(s)*bw
+			d.bar(x, bw, y(0), y(v))
+		}
+	}
+}
+
+// stacked draws the series of a stacked bar chart on top of each other,
+// the positive values up from zero and the negative ones down.
+func (d *drawer) stacked(n int, plot page.Box, y func(float64) float64) {
+	slot := plot.W / float64(n)
+	bw := slot * 0.6
+	pos, neg := make([]float64, n), make([]float64, n)
+	for s, series := range d.c.series {
+		d.pdf.SetFillColor(richtext.RGB(d.c.color(d.opts, s)))
+		for i, v := range series.Values {
+			x := plot.X + float64(i)*slot + (slot-bw)/2
+			if v >= 0 {
+				d.bar(x, bw, y(pos[i]), y(pos[i]+v))
+				pos[i] += v
+			} else {
+				d.bar(x, bw, y(neg[i]), y(neg[i]+v))
+				neg[i] += v
+			}
+		}
+	}
+}
+
+// bar fills a bar of width w from y0 to y1.
+func (d *drawer) bar(x, w, y0, y1 float64) {
+	if h := math.Abs(y1 - y0); h > 0 {
+		d.pdf.RectFromUpperLeftWithStyle(x, min(y0, y1), w, h, "F")
+	}
+}
+
+// lines draws a line through the values of every series, with a point at
+// every value. For an area chart, the area between the line and zero is
+// filled, lightly so that the series behind show through.
+func (d *drawer) lines(x func(int, float64) float64, y func(float64) float64, area bool) error {
+	for s, series := range d.c.series {
+		if len(series.Values) == 0 {
+			continue
+		}
+		col := d.c.color(d.opts, s)
+		pts := make([]gopdf.Point, len(series.Values))
+		for i, v := range series.Values {
+			pts[i] = gopdf.Point{X: x(i, v), Y: y(v)}
+		}
+		if area && len(pts) > 1 {
+			poly := append(pts[:len(pts):len(pts)],
+				gopdf.Point{X: pts[len(pts)-1].X, Y: y(0)},
+				gopdf.Point{X: pts[0].X, Y: y(0)})
+			err := d.pdf.SetTransparency(gopdf.Transparency{Alpha: 0.35, BlendModeType: gopdf.NormalBlendMode})
+			if err != nil {
+				return err
+			}
+			d.pdf.SetFillColor(richtext.RGB(col))
+			d.pdf.Polygon(poly, "F")
+			d.pdf.ClearTransparency()
+		}
+		d.pdf.SetStrokeColor(richtext.RGB(col))
+		d.pdf.SetLineWidth(d.pt(d.opts.LineWidth))
+		for i := 1; i < len(pts); i++ {
+			d.pdf.Line(pts[i-1].X, pts[i-1].Y, pts[i].X, pts[i].Y)
+		}
+		if !area {
+			d.pdf.SetFillColor(richtext.RGB(col))
+			for _, p := range pts {
+				d.dot(p.X, p.Y)
+			}
+		}
+	}
+	return nil
+}
+
+// points draws a point at the X and value of every value of every series.
+func (d *drawer) points(x func(int, float64) float64, y func(float64) float64) {
+	for s, series := range d.c.series {
+		d.pdf.SetFillColor(richtext.RGB(d.c.color(d.opts, s)))
+		for i, v := range series.Values {
+			d.dot(x(i, xOf(series, i)), y(v))
+		}
+	}
+}
+
+// dot fills a point of the size of Options.Marker at x, y.
+func (d *drawer) dot(x, y float64) {
+	r := d.pt(d.opts.Marker)
+	pts := make([]gopdf.Point, 16)
+	for k := range pts {
+		t := float64(k) * math.Pi / 8
+		pts[k] = gopdf.Point{X: x + r*math.Cos(t), Y: y + r*math.Sin(t)}
+	}
+	d.pdf.Polygon(pts, "F")
+}
+
+// pie draws the values of the first series as slices of a pie centered
+// in box, from the top clockwise, with the share of the larger slices
+// written on them.
+func (d *drawer) pie(box page.Box) error {
+	if len(d.c.series) == 0 {
+		return nil
+	}
+	values := d.c.series[0].Values
+	total := 0.0
+	for _, v := range values {
+		total += max(v, 0)
+	}
+	r := min(box.W, box.H)/2 - d.pt(2)
+	if total <= 0 || r <= 0 {
+		return nil
+	}
+	cx, cy := box.CenterX(), box.Y+box.H/2
+
+	d.pdf.SetStrokeColor(255, 255, 255)
+	d.pdf.SetLineWidth(d.pt(1))
+	a := -math.Pi / 2
+	for i, v := range values {
+		if v <= 0 {
+			continue
+		}
+		sweep := v / total * 2 * math.Pi
+		d.pdf.SetFillColor(richtext.RGB(d.opts.Palette[i%len(d.opts.Palette)]))
+		d.pdf.Polygon(arc(cx, cy, r, a, sweep, sweep < 2*math.Pi-1e-9), "FD")
+
+		if share := v / total; share >= 0.05 {
+			mid := a + sweep/2
+			label := strconv.FormatFloat(share*100, 'f', 0, 64) + "%"
+			tx, ty := cx+math.Cos(mid)*r*0.62, cy+math.Sin(mid)*r*0.62
+			if err := d.text(label, tx, ty+d.pt(d.opts.Size)*0.35, gopdf.Center, d.opts.Size, fonts.Bold, color.White); err != nil {
+				return err
+			}
+		}
+		a += sweep
+	}
+	return nil
+}
+
+// arc returns the points of an arc of a circle from angle a over sweep,
+// in radians clockwise from the x axis, and the center if slice is true,
+// for a slice of a pie.
+func arc(cx, cy, r, a, sweep float64, slice bool) []gopdf.Point {
+	steps := max(int(math.Ceil(sweep/(math.Pi/60))), 8)
+	var pts []gopdf.Point
+	if slice {
+		pts = append(pts, gopdf.Point{X: cx, Y: cy})
+	}
+	for k := range steps + 1 {
+		t := a + sweep*float64(k)/float64(steps)
+		pts = append(pts, gopdf.Point{X: cx + r*math.Cos(t), Y: cy + r*math.Sin(t)})
+	}
+	return pts
+}
+
+// text draws s with its baseline at y, left, centered or right aligned on
+// x (gopdf.Left, Center or Right).
+func (d *drawer) text(s string, x, y float64, align int, size float64, style fonts.Style, c color.Color) error {
+	if align != gopdf.Left {
+		w, err := d.measure(s, size, style)
+		if err != nil {
+			return err
+		}
+		if align == gopdf.Center {
+			x -= w / 2
+		} else {
+			x -= w
+		}
+	}
+	if err := d.reg.SetFont(d.opts.Family, style, size); err != nil {
+		return err
+	}
+	d.pdf.SetTextColor(richtext.RGB(c))
+	d.reg.SetXY(x, y)
+	return d.missing.Note(d.reg.Text(s))
+}
+
+// measure returns the width of s in the font of the chart.
+func (d *drawer) measure(s string, size float64, style fonts.Style) (float64, error) {
+	if err := d.reg.SetFont(d.opts.Family, style, size); err != nil {
+		return 0, err
+	}
+	return d.reg.MeasureTextWidth(s)
+}
@@ -0,0 +1,396 @@
+// Package flow places content down the page and starts new pages when it
+// runs out of room, so examples no longer track yPos by hand.
+//
+// A Flow keeps a cursor at the top of the free space between the page
+// margins. Every block reserves its height before it is drawn; if the block
+// does not fit, the flow adds a page, draws its header and footer, runs the
+// page callbacks and places the block at the top of the new page:
+//
+//	f := flow.New(&pdf, flow.Options{})
+//	f.SetFooter(20, func(f *flow.Flow, b flow.Band) error {
+//		text := fmt.Sprintf("Page %d of %s", f.Page(), flow.TotalPages)
+//		return f.PageText(text, b.X, b.Y+15, b.W, gopdf.Right)
+//	})
+//	for _, line := range lines {
+//		if err := f.Text(line, 20); err != nil {
+//			return err
+//		}
+//	}
+//	err := f.WritePdf("report.pdf")
+//
+// TotalPages in page texts is replaced by the number of pages when the
+// document is written, so footers can show it before it is known.
+package flow
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"pdf-tutorial/gopdf/fonts"
+	"pdf-tutorial/gopdf/page"
+	"pdf-tutorial/gopdf/textlayout"
+	"pdf-tutorial/gopdf/units"
+
+	"github.com/signintech/gopdf"
+)
+
+// ErrNoRoom is returned for a block that is taller than a whole page.
+var ErrNoRoom = errors.New("flow: block is taller than the page")
+
+// TotalPages is replaced by the number of pages of the document in the text
+// of PageText, when the document is finished.
+const TotalPages = "{total}"
+
+// Margins are the distances between the page edges and the content area.
+type Margins = page.Margins
+
+// Options controls the pages a Flow adds. Zero fields get the default
+// given in their comment.
+type Options struct {
+	PageSize gopdf.Rect // Size of new pages (the size of the current page)
+	Margins  Margins    // Page margins (the document's margins if set with SetMargins, or 50pt on every side)
+
+	// Drawer draws the text of Text and Paragraph: the document itself or
+	// a *fonts.Registry, to pick fonts by style and use fallbacks.
+	// The default is the document.
+	Drawer textlayout.Drawer
+}
+
+// Flow places blocks of content one below the other on the pages of a
+// document.
+type Flow struct {
+	pdf  *gopdf.GoPdf
+	opts Options
+
+	y         float64 // Top of the free space on the current page
+	callbacks []func(f *Flow) error
+	inPage    bool // Whether page callbacks are running
+
+	header, footer   func(f *Flow, b Band) error
+	headerH, footerH float64
+
+	placeholders []placeholder // Page texts waiting for TotalPages
+	finished     bool
+}
+
+// Band is the area of a page header or footer.
+type Band struct {
+	X, Y, W, H float64 // Top left corner and size
+}
+
+// placeholder is a page text drawn with a gopdf placeholder, to be filled
+// in by Finish.
+type placeholder struct {
+	name, text string
+	align      int
+	font       func() error // Selects the font the text is drawn with
+}
+
+// fontSelector is implemented by *fonts.Registry.
+type fontSelector interface {
+	Current() (fonts.Face, float64)
+	SetFont(family string, style fonts.Style, size float64) error
+}
+
+// New returns a Flow that adds content to pdf. It continues on the current
+// page at the top margin if pdf has one, and adds a page at the first block
+// otherwise.
+func New(pdf *gopdf.GoPdf, opts Options) *Flow {
+	if opts.PageSize.W <= 0 || opts.PageSize.H <= 0 {
+		opts.PageSize = page.Size(pdf)
+	}
+	if opts.Margins == (Margins{}) {
+		opts.Margins = page.MarginsOf(pdf)
+		// A document starts with gopdf's 10pt margins, which are too
+		// narrow for running text; keep them only if they were set.
+		if d := (10 * units.Pt).Units(pdf); opts.Margins == (Margins{Left: d, Top: d, Right: d, Bottom: d}) {
+			opts.Margins = Margins{}
+		}
+	}
+	if opts.Margins == (Margins{}) {
+		m := (50 * units.Pt).Units(pdf)
+		opts.Margins = Margins{Left: m, Top: m, Right: m, Bottom: m}
+	}
+	if opts.Drawer == nil {
+		opts.Drawer = pdf
+	}
+	return &Flow{pdf: pdf, opts: opts, y: opts.Margins.Top}
+}
+
+// SetHeader sets the function that draws the header of every page the flow
+// adds. The header gets a band of height h at the top of the content area,
+// and content starts below it. Set it before the first block is drawn.
+func (f *Flow) SetHeader(h float64, fn func(f *Flow, b Band) error) {
+	f.header, f.headerH = fn, h
+}
+
+// SetFooter sets the function that draws the footer of every page the flow
+// adds, in a band of height h at the bottom of the content area. Content
+// stops above it.
+func (f *Flow) SetFooter(h float64, fn func(f *Flow, b Band) error) {
+	f.footer, f.footerH = fn, h
+}
+
+// OnNewPage registers fn to be called after every page the flow adds, in
+// the order the callbacks were registered, after the header and footer.
+// The cursor is at the top of the content area when the first callback
+// runs; a callback that draws something there should move it down with
+// Space. Callbacks never cause page breaks themselves. If the Drawer is a
+// *fonts.Registry, its font is restored after the callbacks; otherwise a
+// callback that changes the font should set it back. The same holds for
+// colors, and for headers and footers.
+func (f *Flow) OnNewPage(fn func(f *Flow) error) {
+	f.callbacks = append(f.callbacks, fn)
+}
+
+// PDF returns the document the flow draws on.
+func (f *Flow) PDF() *gopdf.GoPdf {
+	return f.pdf
+}
+
+// Page returns the number of the current page in the document, counting
+// from 1. The flow always adds pages at the end of the document.
+func (f *Flow) Page() int {
+	return f.pdf.GetNumberOfPages()
+}
+
+// X returns the left edge of the content area.
+func (f *Flow) X() float64 {
+	return f.opts.Margins.Left
+}
+
+// Y returns the cursor: the top of the free space on the current page.
+func (f *Flow) Y() float64 {
+	return f.y
+}
+
+// SetY moves the cursor, e.g. below a title drawn without the flow.
+func (f *Flow) SetY(y float64) {
+	f.y = y
+}
+
+// Width returns the width of the content area.
+func (f *Flow) Width() float64 {
+	return f.opts.PageSize.W - f.opts.Margins.Left - f.opts.Margins.Right
+}
+
+// Top returns the top edge of the content area, below the header.
+func (f *Flow) Top() float64 {
+	return f.opts.Margins.Top + f.headerH
+}
+
+// Bottom returns the bottom edge of the content area, above the footer.
+func (f *Flow) Bottom() float64 {
+	return f.opts.PageSize.H - f.opts.Margins.Bottom - f.footerH
+}
+
+// Remaining returns the height left on the current page.
+func (f *Flow) Remaining() float64 {
+	return max(f.Bottom()-f.y, 0)
+}
+
+// NewPage adds a page, draws its header and footer, moves the cursor to the
+// top of the content area and runs the page callbacks.
+func (f *Flow) NewPage() error {
+	f.pdf.AddPageWithOption(gopdf.PageOption{PageSize: &f.opts.PageSize})
+	f.y = f.Top()
+
+	f.inPage = true
+	defer func() { f.inPage = false }()
+	if fs, ok := f.opts.Drawer.(fontSelector); ok {
+		// Give the block that caused the page break its font back.
+		face, size := fs.Current()
+		defer func() {
+			if face.Family != "" {
+				fs.SetFont(face.Family, face.Style, size)
+			}
+		}()
+	}
+	x, w := f.X(), f.Width()
+	if f.header != nil {
+		if err := f.header(f, Band{x, f.opts.Margins.Top, w, f.headerH}); err != nil {
+			return err
+		}
+	}
+	if f.footer != nil {
+		if err := f.footer(f, Band{x, f.Bottom(), w, f.footerH}); err != nil {
+			return err
+		}
+	}
+	for _, fn := range f.callbacks {
+		if err := fn(f); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Ensure starts a new page unless h fits below the cursor, and reports
+// whether it did. Use it to keep several blocks together, such as a
+// heading and the first lines of the text after it.
+func (f *Flow) Ensure(h float64) (bool, error) {
+	if f.pdf.GetNumberOfPages() == 0 {
+		return true, f.NewPage()
+	}
+	if f.inPage || f.y+h <= f.Bottom() {
+		return false, nil
+	}
+	if f.y <= f.Top() {
+		// A new page would not have more room.
+		return false, ErrNoRoom
+	}
+	if err := f.NewPage(); err != nil {
+		return true, err
+	}
+	if f.y+h > f.Bottom() {
+		return true, ErrNoRoom
+	}
+	return true, nil
+}
+
+// Reserve makes room for a block of height h, on a new page if needed, and
+// returns the y of its top edge. The cursor moves below the block.
+func (f *Flow) Reserve(h float64) (float64, error) {
+	if _, err := f.Ensure(h); err != nil {
+		return f.y, err
+	}
+	y := f.y
+	f.y += h
+	return y, nil
+}
+
+// Block reserves a block of height h and calls draw with its top left
+// corner and the width of the content area.
+func (f *Flow) Block(h float64, draw func(x, y, w float64) error) error {
+	y, err := f.Reserve(h)
+	if err != nil {
+		return err
+	}
+	return draw(f.X(), y, f.Width())
+}
+
+// Space moves the cursor down by h. Space is not carried over to a new
+// page: if h does not fit, the cursor moves to the bottom of the page and
+// the next block starts the new one.
+func (f *Flow) Space(h float64) {
+	f.y = min(f.y+h, f.Bottom())
+}
+
+// Text draws a single line of text in the current font, in a block of
+// height lineHeight. The baseline is at 80% of the block, which leaves room
+// for the ascenders when lineHeight is at least the font size.
+func (f *Flow) Text(text string, lineHeight float64) error {
+	y, err := f.Reserve(lineHeight)
+	if err != nil {
+		return err
+	}
+	f.opts.Drawer.SetXY(f.X(), y+lineHeight*0.8)
+	return f.opts.Drawer.Text(text)
+}
+
+// Paragraph wraps text in the current font and draws it line by line,
+// continuing on new pages as needed. A zero opts.Width means the width of
+// the content area. Baselines are placed as in Text.
+func (f *Flow) Paragraph(text string, opts textlayout.Options) error {
+	if opts.Width <= 0 {
+		opts.Width = f.Width()
+	}
+	lines, err := textlayout.Layout(f.opts.Drawer, text, f.X(), 0, opts)
+	if err != nil {
+		return err
+	}
+	return f.Lines(lines, opts.LineHeight)
+}
+
+// Lines draws lines laid out by textlayout.Layout or textlayout.Justify,
+// one block of height lineHeight each, keeping their x positions.
+func (f *Flow) Lines(lines []textlayout.Line, lineHeight float64) error {
+	for _, line := range lines {
+		y, err := f.Reserve(lineHeight)
+		if err != nil {
+			return err
+		}
+		line.Y = y + lineHeight*0.8
+		if err := textlayout.Draw(f.opts.Drawer, []textlayout.Line{line}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// PageText draws text in the current font on a line of width w starting at
+// x, with its baseline at y, aligned with gopdf.Left, gopdf.Center or
+// gopdf.Right. It is meant for headers and footers: TotalPages in text is
+// left blank and filled in by Finish.
+//
+// If the flow's Drawer is a *fonts.Registry, Finish measures the completed
+// text in the font it is drawn with; with a plain document, centered and
+// right-aligned texts are measured in the font that is current when Finish
+// is called.
+func (f *Flow) PageText(text string, x, y, w float64, align int) error {
+	d := f.opts.Drawer
+	if !strings.Contains(text, TotalPages) {
+		tw, err := d.MeasureTextWidth(text)
+		if err != nil {
+			return err
+		}
+		d.SetXY(x+offset(w-tw, align), y)
+		return d.Text(text)
+	}
+
+	p := placeholder{name: fmt.Sprintf("flow.TotalPages.%p.%d", f, len(f.placeholders)), text: text, align: align}
+	if fs, ok := d.(fontSelector); ok {
+		face, size := fs.Current()
+		p.font = func() error { return fs.SetFont(face.Family, face.Style, size) }
+	}
+	f.pdf.SetXY(x, y)
+	if err := f.pdf.PlaceHolderText(p.name, w); err != nil {
+		return err
+	}
+	f.placeholders = append(f.placeholders, p)
+	return nil
+}
+
+// Finish fills in TotalPages in the page texts with the number of pages of
+// the document. Call it once, after the last page has been added; WritePdf
+// calls it.
+func (f *Flow) Finish() error {
+	if f.finished {
+		return nil
+	}
+	f.finished = true
+	total := strconv.Itoa(f.pdf.GetNumberOfPages())
+	for _, p := range f.placeholders {
+		if p.font != nil {
+			if err := p.font(); err != nil {
+				return err
+			}
+		}
+		if err := f.pdf.FillInPlaceHoldText(p.name, strings.ReplaceAll(p.text, TotalPages, total), p.align); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// WritePdf finishes the document and writes it to path.
+func (f *Flow) WritePdf(path string) error {
+	if err := f.Finish(); err != nil {
+		return err
+	}
+	return f.pdf.WritePdf(path)
+}
+
+// offset returns the distance from the start of a line to text aligned in
+// it, given the room left next to the text.
+func offset(room float64, align int) float64 {
+	switch {
+	case align&gopdf.Right != 0:
+		return room
+	case align&gopdf.Center != 0:
+		return room / 2
+	}
+	return 0
+}
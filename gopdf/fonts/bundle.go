@@ -0,0 +1,99 @@
+package fonts
+
+import (
+	"embed"
+	"fmt"
+	"strings"
+
+	"github.com/signintech/gopdf"
+)
+
+// The Liberation fonts (SIL Open Font License 1.1, see ttf/LICENSE) are
+// compiled into the program, so documents look the same on every machine,
+// including servers and containers without any fonts installed. They are
+// metric-compatible with Arial, Times New Roman and Courier New.
+//
+//go:embed ttf/*.ttf
+var bundle embed.FS
+
+// Names of the embedded font families.
+const (
+	Sans  = "sans"  // Liberation Sans, metric-compatible with Arial
+	Serif = "serif" // Liberation Serif, metric-compatible with Times New Roman
+	Mono  = "mono"  // Liberation Mono, metric-compatible with Courier New
+)
+
+// BundledFamilies lists the embedded families. Each has all four styles.
+var BundledFamilies = []string{Sans, Serif, Mono}
+
+var bundleFiles = map[string]string{
+	Sans:  "LiberationSans",
+	Serif: "LiberationSerif",
+	Mono:  "LiberationMono",
+}
+
+// bundleAliases maps family names to the embedded family that replaces them.
+var bundleAliases = map[string]string{
+	"sans": Sans, "sansserif": Sans, "liberationsans": Sans, "arial": Sans, "helvetica": Sans, "arimo": Sans,
+	"serif": Serif, "liberationserif": Serif, "times": Serif, "timesnewroman": Serif, "tinos": Serif,
+	"mono": Mono, "monospace": Mono, "liberationmono": Mono, "courier": Mono, "couriernew": Mono, "cousine": Mono,
+}
+
+// BundledFace returns the TrueType data of an embedded face. family is one
+// of Sans, Serif or Mono.
+func BundledFace(family string, style Style) ([]byte, error) {
+	base, ok := bundleFiles[family]
+	if !ok {
+		return nil, fmt.Errorf("%w: %q is not an embedded family", ErrFontNotFound, family)
+	}
+	suffix := strings.ReplaceAll(style.String(), " ", "")
+	return bundle.ReadFile("ttf/" + base + "-" + suffix + ".ttf")
+}
+
+// bundledFamily returns the embedded family standing in for family, if any.
+func bundledFamily(family string) (string, bool) {
+	name, ok := bundleAliases[normalize(family)]
+	return name, ok
+}
+
+// addBundle adds the embedded families to the registry. They are only
+// registered with gopdf when first used, so unused faces cost nothing.
+func (r *Registry) addBundle() {
+	for _, family := range BundledFamilies {
+		fam := &Family{Name: family}
+		for _, style := range []Style{Regular, Bold, Italic, BoldItalic} {
+			data, err := BundledFace(family, style)
+			if err != nil {
+				panic(err) // The files are compiled in; this cannot happen
+			}
+			fam.faces[style] = &Face{Family: family, Style: style, name: family, data: data}
+		}
+		r.families[family] = fam
+	}
+}
+
+// registerBundled adds the embedded face standing in for family to pdf.
+func registerBundled(pdf *gopdf.GoPdf, name, family, style string) error {
+	bundled, ok := bundledFamily(family)
+	if !ok {
+		return fmt.Errorf("%w: %q (%s) is not installed or embedded", ErrFontNotFound, family, style)
+	}
+	data, err := BundledFace(bundled, parseStyle(style))
+	if err != nil {
+		return err
+	}
+	return pdf.AddTTFFontData(name, data)
+}
+
+// parseStyle converts a style name such as "Bold Oblique" to a Style.
+func parseStyle(style string) Style {
+	switch canonicalStyle(style) {
+	case "bold":
+		return Bold
+	case "italic":
+		return Italic
+	case "bold italic":
+		return BoldItalic
+	}
+	return Regular
+}
@@ -0,0 +1,141 @@
+package fonts
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"unicode"
+
+	"golang.org/x/image/font/sfnt"
+)
+
+// ErrMissingGlyphs is wrapped by MissingGlyphsError.
+var ErrMissingGlyphs = errors.New("fonts: missing glyphs")
+
+// MissingGlyphsError lists the runes that neither the current family nor
+// any fallback family has a glyph for. gopdf draws them as spaces.
+type MissingGlyphsError struct {
+	Runes []rune // Each rune is listed once, in order of appearance
+}
+
+func (e *MissingGlyphsError) Error() string {
+	codes := make([]string, len(e.Runes))
+	for i, r := range e.Runes {
+		codes[i] = fmt.Sprintf("%q (U+%04X)", r, r)
+	}
+	return fmt.Sprintf("fonts: no glyph for %s", strings.Join(codes, ", "))
+}
+
+func (e *MissingGlyphsError) Unwrap() error {
+	return ErrMissingGlyphs
+}
+
+// Run is a piece of text drawn with a single face.
+type Run struct {
+	Text string
+	Face Face
+}
+
+// SetFallbacks sets the families tried, in order, for runes the current
+// family has no glyph for, e.g. SetFallbacks("symbols", "cjk") after
+// SetFont("latin", ...). The families must have been added to the registry.
+func (r *Registry) SetFallbacks(families ...string) error {
+	for _, family := range families {
+		if _, ok := r.families[family]; !ok {
+			return fmt.Errorf("%w: %q", ErrUnknownFamily, family)
+		}
+	}
+	r.fallbacks = families
+	return nil
+}
+
+// Fallbacks returns the families set with SetFallbacks.
+func (r *Registry) Fallbacks() []string {
+	return r.fallbacks
+}
+
+// Covers reports whether the font file behind face has a glyph for ch.
+func (r *Registry) Covers(face Face, ch rune) bool {
+	font, err := r.sfntFont(face)
+	if err != nil {
+		return false
+	}
+	var buf sfnt.Buffer
+	index, err := font.GlyphIndex(&buf, ch)
+	return err == nil && index != 0
+}
+
+// Runs splits text into runs for the current style: each rune goes to the
+// first family, current family first and then the fallbacks, that has a
+// glyph for it. Spaces stay in the run they appear in. Runes no family
+// covers stay in the current run and are also returned in missing.
+func (r *Registry) Runs(text string) (runs []Run, missing []rune) {
+	chain := []Face{r.current}
+	for _, family := range r.fallbacks {
+		if family == r.current.Family {
+			continue
+		}
+		if face, err := r.Face(family, r.current.Style); err == nil {
+			chain = append(chain, face)
+		}
+	}
+
+	seen := make(map[rune]bool)
+	var b strings.Builder
+	active := 0 // Index in chain of the run being built
+	for _, ch := range text {
+		next := active
+		if !(unicode.IsSpace(ch) && r.Covers(chain[active], ch)) {
+			next = -1
+			for i, face := range chain {
+				if r.Covers(face, ch) {
+					next = i
+					break
+				}
+			}
+			if next < 0 {
+				if !seen[ch] {
+					seen[ch] = true
+					missing = append(missing, ch)
+				}
+				next = active
+			}
+		}
+		if next != active && b.Len() > 0 {
+			runs = append(runs, Run{Text: b.String(), Face: chain[active]})
+			b.Reset()
+		}
+		active = next
+		b.WriteRune(ch)
+	}
+	if b.Len() > 0 {
+		runs = append(runs, Run{Text: b.String(), Face: chain[active]})
+	}
+	return runs, missing
+}
+
+// sfntFont parses, once, the font file behind face.
+func (r *Registry) sfntFont(face Face) (*sfnt.Font, error) {
+	key := face.path
+	if face.data != nil {
+		key = fmt.Sprintf("data:%p", &face.data[0])
+	}
+	if font, ok := r.parsed[key]; ok {
+		return font, nil
+	}
+
+	data := face.data
+	if data == nil {
+		var err error
+		if data, err = os.ReadFile(face.path); err != nil {
+			return nil, err
+		}
+	}
+	font, err := sfnt.Parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("fonts: parsing %s %s: %w", face.Family, face.Style, err)
+	}
+	r.parsed[key] = font
+	return font, nil
+}
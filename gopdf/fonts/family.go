@@ -0,0 +1,387 @@
+package fonts
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/signintech/gopdf"
+	"golang.org/x/image/font/sfnt"
+)
+
+// Style selects a face within a font family.
+type Style int
+
+// Font styles. Bold and Italic can be combined: Bold|Italic == BoldItalic.
+const (
+	Regular    Style = 0
+	Bold       Style = 1
+	Italic     Style = 2
+	BoldItalic Style = Bold | Italic
+)
+
+// String returns the style name used in TrueType name tables.
+func (s Style) String() string {
+	switch s {
+	case Bold:
+		return "Bold"
+	case Italic:
+		return "Italic"
+	case BoldItalic:
+		return "Bold Italic"
+	}
+	return "Regular"
+}
+
+// gopdfStyle converts a Style to the gopdf.Bold/gopdf.Italic flags, which
+// gopdf uses together with the family name to look up a registered font.
+func (s Style) gopdfStyle() int {
+	style := gopdf.Regular
+	if s&Bold != 0 {
+		style |= gopdf.Bold
+	}
+	if s&Italic != 0 {
+		style |= gopdf.Italic
+	}
+	return style
+}
+
+// ErrUnknownFamily is returned when a family was never added to a Registry.
+var ErrUnknownFamily = errors.New("fonts: unknown font family")
+
+// Face is one style of a font family.
+type Face struct {
+	Family string // Registry family name, e.g. "Body"
+	Style  Style  // Requested style
+
+	// Synthetic holds the style bits that are emulated because the family
+	// has no file for them: Bold is drawn by overprinting the text with a
+	// small offset. gopdf cannot slant text, so Italic is drawn upright.
+	Synthetic Style
+
+	name string // Family name of the source face in gopdf
+	path string // TrueType file, or
+	data []byte // TrueType data
+}
+
+// Family groups the regular, bold, italic and bold-italic faces of a font.
+type Family struct {
+	Name  string
+	faces [4]*Face // Indexed by Style
+}
+
+// Has reports whether the family has a real (non synthetic) face for style.
+func (f *Family) Has(style Style) bool {
+	return f.faces[style&BoldItalic] != nil
+}
+
+// Registry registers font families with a gopdf document and selects faces
+// by family name and style, e.g. SetFont("Body", fonts.Bold, 12).
+//
+// Faces are registered with gopdf under the family name and the gopdf style
+// flags, so pdf.SetFont("Body", "B", 12) works too once a face is used.
+// Missing styles are synthesized from the closest available face, and
+// registered under another name, as is a face that replaces one gopdf
+// already has.
+type Registry struct {
+	// Resolver is used by AddSystemFamily. It defaults to Default.
+	Resolver *Resolver
+
+	pdf        *gopdf.GoPdf
+	families   map[string]*Family
+	registered map[string]bool // "family/style" keys known to gopdf
+	current    Face
+	size       float64
+	color      [3]uint8 // Text color set with SetTextColor
+
+	fallbacks []string              // Families tried when a glyph is missing
+	parsed    map[string]*sfnt.Font // Parsed faces, by source, for coverage checks
+}
+
+// NewRegistry returns a Registry for pdf that already holds the embedded
+// families Sans, Serif and Mono, so SetFont(fonts.Sans, fonts.Bold, 12)
+// works on any machine without further setup.
+func NewRegistry(pdf *gopdf.GoPdf) *Registry {
+	r := &Registry{
+		Resolver:   Default,
+		pdf:        pdf,
+		families:   make(map[string]*Family),
+		registered: make(map[string]bool),
+		parsed:     make(map[string]*sfnt.Font),
+	}
+	r.addBundle()
+	return r
+}
+
+// AddFace adds the TrueType file at path as the given style of family.
+func (r *Registry) AddFace(family string, style Style, path string) error {
+	if _, err := os.Stat(path); err != nil {
+		return fmt.Errorf("fonts: %s %s: %w", family, style, err)
+	}
+	return r.addFace(family, &Face{Family: family, Style: style & BoldItalic, path: path})
+}
+
+// AddFaceData adds TrueType font data as the given style of family.
+func (r *Registry) AddFaceData(family string, style Style, data []byte) error {
+	return r.addFace(family, &Face{Family: family, Style: style & BoldItalic, data: data})
+}
+
+// AddSystemFamily adds every style of an installed font family, found with
+// the registry's Resolver, under the name family. The regular face must
+// exist; bold, italic and bold-italic are optional and synthesized when
+// missing.
+func (r *Registry) AddSystemFamily(family, systemFamily string) error {
+	for _, style := range []Style{Regular, Bold, Italic, BoldItalic} {
+		f, err := r.Resolver.Find(systemFamily, style.String())
+		if err != nil {
+			if style == Regular {
+				return err
+			}
+			continue
+		}
+		if err := r.AddFace(family, style, f.Path); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Family returns the family registered under name.
+func (r *Registry) Family(name string) (*Family, bool) {
+	f, ok := r.families[name]
+	return f, ok
+}
+
+// Face returns the face used for family and style. When the family has no
+// file for style, the closest face is returned with its Synthetic bits set:
+// bold-italic falls back to bold, then italic, then regular; bold and
+// italic fall back to regular.
+func (r *Registry) Face(family string, style Style) (Face, error) {
+	fam, ok := r.families[family]
+	if !ok {
+		return Face{}, fmt.Errorf("%w: %q", ErrUnknownFamily, family)
+	}
+	style &= BoldItalic
+	for _, s := range fallbackStyles(style) {
+		if src := fam.faces[s]; src != nil {
+			face := *src
+			face.Style = style
+			face.Synthetic = style &^ s
+			return face, nil
+		}
+	}
+	return Face{}, fmt.Errorf("%w: %q has no %s face", ErrUnknownFamily, family, style)
+}
+
+// SetFont selects family, style and size for the following Text and
+// MeasureTextWidth calls, registering synthetic faces with gopdf on first
+// use.
+func (r *Registry) SetFont(family string, style Style, size float64) error {
+	face, err := r.Face(family, style)
+	if err != nil {
+		return err
+	}
+	if err := r.use(face, size); err != nil {
+		return err
+	}
+	r.current = face
+	r.size = size
+	return nil
+}
+
+// Current returns the face and size selected by the last SetFont call.
+func (r *Registry) Current() (Face, float64) {
+	return r.current, r.size
+}
+
+// SetTextColor sets the color of the following text, like gopdf's
+// SetTextColor, and remembers it: gopdf cannot tell its text color, so code
+// that draws in colors of its own restores this one afterwards.
+func (r *Registry) SetTextColor(red, green, blue uint8) {
+	r.color = [3]uint8{red, green, blue}
+	r.pdf.SetTextColor(red, green, blue)
+}
+
+// TextColor returns the color set with SetTextColor, black if none.
+func (r *Registry) TextColor() (red, green, blue uint8) {
+	return r.color[0], r.color[1], r.color[2]
+}
+
+// SetXY moves the document's current position, like gopdf's SetXY, so a
+// Registry can be used wherever text is drawn with SetXY and Text.
+func (r *Registry) SetXY(x, y float64) {
+	r.pdf.SetXY(x, y)
+}
+
+// PDF returns the document the registry adds its faces to.
+func (r *Registry) PDF() *gopdf.GoPdf {
+	return r.pdf
+}
+
+// Text draws text at the current position (y is the baseline) with the
+// current face, emulating synthetic bold, and moves the current
+// x position to the end of the text like gopdf's Text does.
+//
+// Runes the current face has no glyph for are drawn with the first fallback
+// family that has one (see SetFallbacks). If no family covers some runes,
+// the rest of the text is still drawn and a *MissingGlyphsError is returned.
+func (r *Registry) Text(text string) error {
+	runs, missing := r.Runs(text)
+	for _, run := range runs {
+		if err := r.drawRun(run); err != nil {
+			return err
+		}
+	}
+	if err := r.use(r.current, r.size); err != nil {
+		return err
+	}
+	if len(missing) > 0 {
+		return &MissingGlyphsError{Runes: missing}
+	}
+	return nil
+}
+
+// MeasureTextWidth returns the width of text as Text would draw it,
+// including fallback fonts and the extra width of synthetic bold.
+func (r *Registry) MeasureTextWidth(text string) (float64, error) {
+	runs, _ := r.Runs(text)
+	total := 0.0
+	for _, run := range runs {
+		if err := r.use(run.Face, r.size); err != nil {
+			return 0, err
+		}
+		w, err := r.measure(run.Face, run.Text)
+		if err != nil {
+			return 0, err
+		}
+		total += w
+	}
+	return total, r.use(r.current, r.size)
+}
+
+// use makes face the active gopdf font.
+func (r *Registry) use(face Face, size float64) error {
+	if face.Family == "" {
+		return nil
+	}
+	if err := r.register(face); err != nil {
+		return err
+	}
+	return r.pdf.SetFontWithStyle(face.pdfFamily(), face.Style.gopdfStyle(), size)
+}
+
+// measure returns the width of text in face, which must be active.
+func (r *Registry) measure(face Face, text string) (float64, error) {
+	w, err := r.pdf.MeasureTextWidth(text)
+	if err != nil {
+		return 0, err
+	}
+	if face.Synthetic&Bold != 0 && text != "" {
+		w += syntheticBoldOffset(r.size)
+	}
+	return w, nil
+}
+
+// drawRun draws one run at the current position and advances x.
+func (r *Registry) drawRun(run Run) error {
+	if err := r.use(run.Face, r.size); err != nil {
+		return err
+	}
+	x, y := r.pdf.GetX(), r.pdf.GetY()
+	if run.Face.Synthetic&Bold == 0 {
+		return r.pdf.Text(run.Text)
+	}
+
+	d := syntheticBoldOffset(r.size)
+	for _, dx := range []float64{0, d / 2, d} {
+		r.pdf.SetXY(x+dx, y)
+		if err := r.pdf.Text(run.Text); err != nil {
+			return err
+		}
+	}
+
+	w, err := r.measure(run.Face, run.Text)
+	if err != nil {
+		return err
+	}
+	r.pdf.SetXY(x+w, y)
+	return nil
+}
+
+// syntheticBoldOffset is how far synthetic bold text is smeared to the right.
+func syntheticBoldOffset(size float64) float64 {
+	return size * 0.03
+}
+
+func (r *Registry) addFace(family string, face *Face) error {
+	// gopdf keeps the first font added under a name, so a replacement
+	// needs a name of its own.
+	face.name = family
+	for n := 2; r.registered[face.key()]; n++ {
+		face.name = fmt.Sprintf("%s#%d", family, n)
+	}
+	if err := r.register(*face); err != nil {
+		return err
+	}
+	fam, ok := r.families[family]
+	if !ok {
+		fam = &Family{Name: family}
+		r.families[family] = fam
+	}
+	fam.faces[face.Style] = face
+	return nil
+}
+
+// pdfFamily returns the family name face is registered with gopdf under.
+// A synthetic face gets a name of its own, that of the face it is drawn
+// with and of its style, so that gopdf does not take it for the real face
+// of its style if that is added later.
+func (f Face) pdfFamily() string {
+	if f.Synthetic == Regular {
+		return f.name
+	}
+	return fmt.Sprintf("%s~%s", f.name, f.Style&^f.Synthetic)
+}
+
+// key returns the "family/style" key of face in gopdf.
+func (f Face) key() string {
+	return fmt.Sprintf("%s/%d", f.pdfFamily(), f.Style)
+}
+
+// register adds a face to gopdf under its family and style, once.
+func (r *Registry) register(face Face) error {
+	family := face.pdfFamily()
+	key := face.key()
+	if r.registered[key] {
+		return nil
+	}
+
+	option := gopdf.TtfOption{
+		Style:                     face.Style.gopdfStyle(),
+		OnGlyphNotFoundSubstitute: gopdf.DefaultOnGlyphNotFoundSubstitute,
+	}
+	var err error
+	if face.data != nil {
+		err = r.pdf.AddTTFFontDataWithOption(family, face.data, option)
+	} else {
+		err = r.pdf.AddTTFFontWithOption(family, face.path, option)
+	}
+	if err != nil {
+		return fmt.Errorf("fonts: loading %s %s: %w", face.Family, face.Style, err)
+	}
+	r.registered[key] = true
+	return nil
+}
+
+// fallbackStyles lists the faces to try, best first, for a requested style.
+func fallbackStyles(style Style) []Style {
+	switch style {
+	case BoldItalic:
+		return []Style{BoldItalic, Bold, Italic, Regular}
+	case Bold:
+		return []Style{Bold, Regular}
+	case Italic:
+		return []Style{Italic, Regular}
+	}
+	return []Style{Regular}
+}
@@ -0,0 +1,432 @@
+// Package fonts finds and loads TrueType fonts for gopdf documents.
+//
+// The examples in this repository used to try "./fonts/arial.ttf" and then
+// "C:/Windows/Fonts/arial.ttf", which only works on Windows. A Resolver
+// instead searches a list of directories (see DefaultDirs), reads the name
+// table of every .ttf file it finds and matches fonts by their real family
+// and style names, so the same code works on Linux, macOS and Windows.
+//
+// The package also embeds the Liberation Sans, Serif and Mono families
+// (see Sans, Serif and Mono), groups faces into families with a Registry,
+// and falls back to other fonts for characters a font cannot draw.
+package fonts
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/signintech/gopdf"
+	"golang.org/x/image/font/sfnt"
+)
+
+// EnvFontPath is the environment variable holding extra font directories,
+// separated by os.PathListSeparator (":" on Linux/macOS, ";" on Windows).
+// Directories listed there are searched before any other location.
+const EnvFontPath = "GOPDF_FONT_PATH"
+
+// ErrFontNotFound is returned when no font file matches a family and style.
+var ErrFontNotFound = errors.New("fonts: font not found")
+
+// FontFile describes a TrueType font file found on disk.
+type FontFile struct {
+	Path           string // Location of the .ttf file
+	Family         string // Family name, e.g. "DejaVu Sans"
+	Style          string // Style (subfamily) name, e.g. "Bold Oblique"
+	FullName       string // Full name, e.g. "DejaVu Sans Bold Oblique"
+	PostScriptName string // PostScript name, e.g. "DejaVuSans-BoldOblique"
+}
+
+// DefaultSubstitutes maps common proprietary families to metric-compatible or
+// look-alike open fonts that are usually installed on Linux machines.
+var DefaultSubstitutes = map[string][]string{
+	"arial":           {"Liberation Sans", "Arimo", "DejaVu Sans", "FreeSans"},
+	"helvetica":       {"Liberation Sans", "Arimo", "DejaVu Sans", "FreeSans"},
+	"times":           {"Liberation Serif", "Tinos", "DejaVu Serif", "FreeSerif"},
+	"times new roman": {"Liberation Serif", "Tinos", "DejaVu Serif", "FreeSerif"},
+	"courier":         {"Liberation Mono", "Cousine", "DejaVu Sans Mono", "FreeMono"},
+	"courier new":     {"Liberation Mono", "Cousine", "DejaVu Sans Mono", "FreeMono"},
+	"calibri":         {"Carlito", "Liberation Sans", "DejaVu Sans"},
+	"cambria":         {"Caladea", "Liberation Serif", "DejaVu Serif"},
+}
+
+// Resolver looks up fonts by family and style name in a list of directories.
+// The directories are scanned once, on first use.
+type Resolver struct {
+	// Dirs are searched recursively, in order. When two files share the
+	// same family and style, the one found first wins.
+	Dirs []string
+
+	// Substitutes lists replacement families to try when a requested
+	// family is not installed. Keys are lower case. Set it to nil to
+	// disable substitution.
+	Substitutes map[string][]string
+
+	once  sync.Once
+	files []FontFile
+}
+
+// NewResolver returns a Resolver that searches dirs, or DefaultDirs when no
+// directory is given.
+func NewResolver(dirs ...string) *Resolver {
+	if len(dirs) == 0 {
+		dirs = DefaultDirs()
+	}
+	return &Resolver{Dirs: dirs, Substitutes: DefaultSubstitutes}
+}
+
+// Default is the resolver used by the package level Find and Register.
+var Default = NewResolver()
+
+// Find looks up a font with the Default resolver.
+func Find(family, style string) (FontFile, error) {
+	return Default.Find(family, style)
+}
+
+// Register looks up a font with the Default resolver and adds it to pdf.
+func Register(pdf *gopdf.GoPdf, name, family, style string) error {
+	return Default.Register(pdf, name, family, style)
+}
+
+// DefaultDirs returns the font directories for the current platform:
+//
+//  1. the directories listed in $GOPDF_FONT_PATH
+//  2. ./fonts (the project's own font folder)
+//  3. the user's font folders (~/.fonts, ~/.local/share/fonts, ...)
+//  4. the <dir> entries of the fontconfig configuration (Linux/BSD)
+//  5. the system font folders (/usr/share/fonts, C:/Windows/Fonts, ...)
+//
+// Directories that do not exist are kept; they are skipped while scanning.
+func DefaultDirs() []string {
+	var dirs []string
+	if env := os.Getenv(EnvFontPath); env != "" {
+		dirs = append(dirs, filepath.SplitList(env)...)
+	}
+	dirs = append(dirs, "fonts")
+
+	home, _ := os.UserHomeDir()
+	switch runtime.GOOS {
+	case "windows":
+		windir := os.Getenv("WINDIR")
+		if windir == "" {
+			windir = `C:\Windows`
+		}
+		if local := os.Getenv("LOCALAPPDATA"); local != "" {
+			dirs = append(dirs, filepath.Join(local, "Microsoft", "Windows", "Fonts"))
+		}
+		dirs = append(dirs, filepath.Join(windir, "Fonts"))
+	case "darwin":
+		if home != "" {
+			dirs = append(dirs, filepath.Join(home, "Library", "Fonts"))
+		}
+		dirs = append(dirs, "/Library/Fonts", "/System/Library/Fonts", "/Network/Library/Fonts")
+	default:
+		if home != "" {
+			dirs = append(dirs, filepath.Join(home, ".fonts"))
+		}
+		dirs = append(dirs, filepath.Join(xdgDataHome(home), "fonts"))
+		dirs = append(dirs, fontconfigDirs("/etc/fonts/fonts.conf", home)...)
+		dirs = append(dirs, "/usr/local/share/fonts", "/usr/share/fonts")
+	}
+	return dedupe(dirs)
+}
+
+// Faces returns every font file found in the resolver's directories, sorted
+// by family and style.
+func (r *Resolver) Faces() []FontFile {
+	r.once.Do(r.scan)
+	return r.files
+}
+
+// Find returns the font file whose family and style match the given names.
+// Names are compared case-insensitively and ignoring spaces, dashes and
+// underscores, so "dejavu-sans" matches "DejaVu Sans". An empty style means
+// "Regular". The file name without extension is accepted as a family name
+// too, which keeps old calls such as Find("arial", "") working.
+//
+// When the family is not installed, the families listed in Substitutes are
+// tried in order. The returned error wraps ErrFontNotFound.
+func (r *Resolver) Find(family, style string) (FontFile, error) {
+	candidates := []string{family}
+	candidates = append(candidates, r.Substitutes[strings.ToLower(strings.TrimSpace(family))]...)
+	return r.find(candidates, style)
+}
+
+// find returns the first font matching one of the families and style.
+func (r *Resolver) find(families []string, style string) (FontFile, error) {
+	files := r.Faces()
+	wantStyle := canonicalStyle(style)
+	for _, name := range families {
+		key := normalize(name)
+		for _, f := range files {
+			if canonicalStyle(f.Style) != wantStyle {
+				continue
+			}
+			if normalize(f.Family) == key || normalize(stem(f.Path)) == key {
+				return f, nil
+			}
+		}
+	}
+
+	if style == "" {
+		style = "Regular"
+	}
+	return FontFile{}, fmt.Errorf("%w: %q (%s) in %d directories", ErrFontNotFound, families[0], style, len(r.Dirs))
+}
+
+// Register finds a font and adds it to pdf under name, so it can be used
+// with pdf.SetFont(name, "", size).
+//
+// The embedded families Sans, Serif and Mono always come from the bundle,
+// which gives identical output on every machine. For other families an
+// installed font with that exact name wins; then comes the embedded
+// stand-in, if the family has one (Arial, Times New Roman, Courier New and
+// their clones); then the families listed in Substitutes.
+func (r *Resolver) Register(pdf *gopdf.GoPdf, name, family, style string) error {
+	if _, ok := bundleFiles[family]; ok {
+		return registerBundled(pdf, name, family, style)
+	}
+
+	f, err := r.find([]string{family}, style)
+	if err != nil {
+		if _, ok := bundledFamily(family); ok {
+			return registerBundled(pdf, name, family, style)
+		}
+		if f, err = r.Find(family, style); err != nil {
+			return err
+		}
+	}
+	if err := pdf.AddTTFFont(name, f.Path); err != nil {
+		return fmt.Errorf("fonts: loading %s: %w", f.Path, err)
+	}
+	return nil
+}
+
+// scan walks all directories and reads the name table of every .ttf file.
+// Unreadable files and missing directories are skipped.
+func (r *Resolver) scan() {
+	seen := make(map[string]bool)
+	for _, dir := range r.Dirs {
+		if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+			continue
+		}
+		filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+			if err != nil || d.IsDir() || !strings.EqualFold(filepath.Ext(path), ".ttf") {
+				return nil
+			}
+			f, err := ReadFontFile(path)
+			if err != nil {
+				return nil
+			}
+			key := normalize(f.Family) + "/" + canonicalStyle(f.Style)
+			if !seen[key] {
+				seen[key] = true
+				r.files = append(r.files, f)
+			}
+			return nil
+		})
+	}
+	sort.SliceStable(r.files, func(i, j int) bool {
+		if r.files[i].Family != r.files[j].Family {
+			return r.files[i].Family < r.files[j].Family
+		}
+		return r.files[i].Style < r.files[j].Style
+	})
+}
+
+// ReadFontFile reads the family and style names from a TrueType file.
+// The typographic family (name ID 16/17) is preferred over the legacy
+// family (name ID 1/2), so "Arial Black" files report family "Arial" and
+// style "Black" when the font provides both.
+func ReadFontFile(path string) (FontFile, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return FontFile{}, err
+	}
+	defer file.Close()
+
+	font, err := sfnt.ParseReaderAt(file)
+	if err != nil {
+		return FontFile{}, fmt.Errorf("fonts: parsing %s: %w", path, err)
+	}
+
+	var buf sfnt.Buffer
+	name := func(ids ...sfnt.NameID) string {
+		for _, id := range ids {
+			if s, err := font.Name(&buf, id); err == nil && s != "" {
+				return s
+			}
+		}
+		return ""
+	}
+
+	f := FontFile{
+		Path:           path,
+		Family:         name(sfnt.NameIDTypographicFamily, sfnt.NameIDFamily),
+		Style:          name(sfnt.NameIDTypographicSubfamily, sfnt.NameIDSubfamily),
+		FullName:       name(sfnt.NameIDFull),
+		PostScriptName: name(sfnt.NameIDPostScript),
+	}
+	if f.Family == "" {
+		return FontFile{}, fmt.Errorf("fonts: %s has no family name", path)
+	}
+	return f, nil
+}
+
+// canonicalStyle reduces a style name to "regular", "bold", "italic" or
+// "bold italic". Oblique counts as italic; weights other than bold (such
+// as "Light" or "Black") are kept as they are.
+func canonicalStyle(style string) string {
+	s := strings.ToLower(style)
+	bold := strings.Contains(s, "bold") && !strings.Contains(s, "semibold") &&
+		!strings.Contains(s, "demibold") && !strings.Contains(s, "extrabold") &&
+		!strings.Contains(s, "ultrabold")
+	italic := strings.Contains(s, "italic") || strings.Contains(s, "oblique")
+
+	switch {
+	case bold && italic:
+		return "bold italic"
+	case bold:
+		return "bold"
+	case italic && strings.TrimSpace(strings.NewReplacer("italic", "", "oblique", "").Replace(s)) == "":
+		return "italic"
+	case s == "" || s == "regular" || s == "normal" || s == "book" || s == "roman" || s == "plain":
+		return "regular"
+	}
+	return normalize(s)
+}
+
+// normalize lower-cases a name and drops spaces, dashes and underscores.
+func normalize(s string) string {
+	return strings.Map(func(r rune) rune {
+		switch r {
+		case ' ', '-', '_':
+			return -1
+		}
+		return r
+	}, strings.ToLower(s))
+}
+
+// stem returns the file name without directory and extension.
+func stem(path string) string {
+	base := filepath.Base(path)
+	return strings.TrimSuffix(base, filepath.Ext(base))
+}
+
+// xdgDataHome returns $XDG_DATA_HOME or its default, ~/.local/share.
+func xdgDataHome(home string) string {
+	if dir := os.Getenv("XDG_DATA_HOME"); dir != "" {
+		return dir
+	}
+	return filepath.Join(home, ".local", "share")
+}
+
+// fontconfigDirs returns the <dir> entries of a fontconfig file and of the
+// files it includes (typically /etc/fonts/conf.d/*.conf).
+func fontconfigDirs(conf, home string) []string {
+	var dirs []string
+	visited := make(map[string]bool)
+
+	var parse func(path string)
+	parse = func(path string) {
+		if visited[path] {
+			return
+		}
+		visited[path] = true
+
+		info, err := os.Stat(path)
+		if err != nil {
+			return
+		}
+		if info.IsDir() {
+			matches, _ := filepath.Glob(filepath.Join(path, "*.conf"))
+			sort.Strings(matches)
+			for _, m := range matches {
+				parse(m)
+			}
+			return
+		}
+
+		file, err := os.Open(path)
+		if err != nil {
+			return
+		}
+		defer file.Close()
+
+		dec := xml.NewDecoder(file)
+		dec.Strict = false
+		for {
+			tok, err := dec.Token()
+			if err != nil {
+				return
+			}
+			start, ok := tok.(xml.StartElement)
+			if !ok || (start.Name.Local != "dir" && start.Name.Local != "include") {
+				continue
+			}
+			var text string
+			if err := dec.DecodeElement(&text, &start); err != nil {
+				return
+			}
+			p := expandFontconfigPath(strings.TrimSpace(text), attr(start, "prefix"), home, filepath.Dir(path))
+			if p == "" {
+				continue
+			}
+			if start.Name.Local == "dir" {
+				dirs = append(dirs, p)
+			} else {
+				parse(p)
+			}
+		}
+	}
+	parse(conf)
+	return dirs
+}
+
+// expandFontconfigPath resolves "~", the "xdg" prefix and relative paths the
+// way fontconfig does.
+func expandFontconfigPath(p, prefix, home, confDir string) string {
+	switch {
+	case p == "":
+		return ""
+	case prefix == "xdg":
+		return filepath.Join(xdgDataHome(home), p)
+	case strings.HasPrefix(p, "~"):
+		if home == "" {
+			return ""
+		}
+		return filepath.Join(home, p[1:])
+	case !filepath.IsAbs(p):
+		return filepath.Join(confDir, p)
+	}
+	return p
+}
+
+func attr(e xml.StartElement, name string) string {
+	for _, a := range e.Attr {
+		if a.Name.Local == name {
+			return a.Value
+		}
+	}
+	return ""
+}
+
+func dedupe(dirs []string) []string {
+	seen := make(map[string]bool)
+	out := dirs[:0]
+	for _, d := range dirs {
+		d = filepath.Clean(d)
+		if !seen[d] {
+			seen[d] = true
+			out = append(out, d)
+		}
+	}
+	return out
+}
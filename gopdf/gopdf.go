@@ -4,6 +4,8 @@ import (
 	"log"
 
 	"github.com/signintech/gopdf"
+
+	"pdf-tutorial/gopdf/fonts"
 )
 
 func main() {
@@ -15,8 +17,9 @@ func main() {
 	// Add a new page
 	pdf.AddPage()
 
-	// Add a font (TTF required)
-	err := pdf.AddTTFFont("arial", "C:/Windows/Fonts/arial.ttf")
+	// Add a font (TTF required): the embedded Liberation Sans has the same
+	// metrics as Arial and needs no font files on the machine
+	err := fonts.Register(&pdf, "arial", fonts.Sans, "Regular")
 	if err != nil {
 		log.Print(err.Error())
 		return
@@ -0,0 +1,240 @@
+// Package hyphen finds the places where words may be hyphenated, with
+// Liang's algorithm and the TeX hyphenation patterns.
+//
+// Patterns for English, Spanish, French, German and Portuguese are compiled
+// in (see patterns/README):
+//
+//	h, err := hyphen.Language("en")
+//	...
+//	h.Hyphenate("hyphenation") // [hy phen a tion]
+//
+// A *Hyphenator can be set as textlayout.Options.Hyphenator to hyphenate
+// wrapped and justified text.
+package hyphen
+
+import (
+	"bufio"
+	"embed"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"unicode"
+	"unicode/utf8"
+)
+
+//go:embed patterns/*.txt
+var patternFiles embed.FS
+
+// ErrUnknownLanguage is returned by Language for a language without
+// embedded patterns.
+var ErrUnknownLanguage = errors.New("hyphen: no patterns for language")
+
+// Languages lists the languages with embedded patterns.
+var Languages = []string{"en", "es", "fr", "de", "pt"}
+
+// languages maps a language to its pattern files and the minimum number
+// of characters kept before and after a hyphen, as set by its TeX file.
+var languages = map[string]struct {
+	file              string
+	leftMin, rightMin int
+}{
+	"en": {"hyph-en-us", 2, 3},
+	"es": {"hyph-es", 2, 2},
+	"fr": {"hyph-fr", 2, 3},
+	"de": {"hyph-de-1996", 2, 2},
+	"pt": {"hyph-pt", 2, 3},
+}
+
+var (
+	mu     sync.Mutex
+	loaded = make(map[string]*Hyphenator)
+)
+
+// Language returns the Hyphenator for an embedded language. lang is a
+// language code, optionally with a region: "en", "en-US", "pt_BR" and "de"
+// are all accepted. The patterns are parsed once, on first use.
+func Language(lang string) (*Hyphenator, error) {
+	base := strings.ToLower(lang)
+	if i := strings.IndexAny(base, "-_"); i >= 0 {
+		base = base[:i]
+	}
+	l, ok := languages[base]
+	if !ok {
+		return nil, fmt.Errorf("%w %q", ErrUnknownLanguage, lang)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if h, ok := loaded[base]; ok {
+		return h, nil
+	}
+	patterns, err := patternFiles.ReadFile("patterns/" + l.file + ".pat.txt")
+	if err != nil {
+		return nil, err
+	}
+	exceptions, _ := patternFiles.ReadFile("patterns/" + l.file + ".hyp.txt") // Optional
+	h := New(string(patterns), string(exceptions))
+	h.LeftMin, h.RightMin = l.leftMin, l.rightMin
+	loaded[base] = h
+	return h, nil
+}
+
+// Hyphenator hyphenates words of one language.
+type Hyphenator struct {
+	// LeftMin and RightMin are the minimum number of characters kept
+	// before and after a hyphen.
+	LeftMin  int
+	RightMin int
+
+	patterns   map[string][]uint8 // Letters of a pattern to its digits
+	maxLen     int                // Letters in the longest pattern
+	exceptions map[string][]int   // Word to the rune indexes it breaks before
+}
+
+// New returns a Hyphenator for TeX patterns such as "1b2l" or ".ach4",
+// separated by white space, and exceptions such as "ta-ble". Both use
+// lower case letters.
+func New(patterns, exceptions string) *Hyphenator {
+	h := &Hyphenator{
+		LeftMin:    2,
+		RightMin:   3,
+		patterns:   make(map[string][]uint8),
+		exceptions: make(map[string][]int),
+	}
+	for _, p := range words(patterns) {
+		var letters []rune
+		digits := []uint8{0}
+		for _, r := range p {
+			if r >= '0' && r <= '9' {
+				digits[len(digits)-1] = uint8(r - '0')
+				continue
+			}
+			letters = append(letters, r)
+			digits = append(digits, 0)
+		}
+		h.patterns[string(letters)] = digits
+		h.maxLen = max(h.maxLen, len(letters))
+	}
+	for _, e := range words(exceptions) {
+		var points []int
+		n := 0
+		for _, r := range e {
+			if r == '-' {
+				points = append(points, n)
+				continue
+			}
+			n++
+		}
+		h.exceptions[strings.ReplaceAll(e, "-", "")] = points
+	}
+	return h
+}
+
+// words splits s at white space.
+func words(s string) []string {
+	var out []string
+	sc := bufio.NewScanner(strings.NewReader(s))
+	sc.Split(bufio.ScanWords)
+	for sc.Scan() {
+		out = append(out, sc.Text())
+	}
+	return out
+}
+
+// Points returns the byte offsets in word where a hyphen may be inserted,
+// in increasing order. word may contain punctuation: each run of letters
+// in it is hyphenated on its own, so "(well-being)" and "word," work.
+func (h *Hyphenator) Points(word string) []int {
+	var points []int
+	start := -1 // Byte offset of the letter run being scanned
+	for i, r := range word + " " {
+		if isLetter(r) {
+			if start < 0 {
+				start = i
+			}
+			continue
+		}
+		if start >= 0 {
+			for _, p := range h.runPoints(word[start:i]) {
+				points = append(points, start+p)
+			}
+			start = -1
+		}
+	}
+	return points
+}
+
+// Hyphenate splits word into the pieces between its hyphenation points.
+func (h *Hyphenator) Hyphenate(word string) []string {
+	var pieces []string
+	last := 0
+	for _, p := range h.Points(word) {
+		pieces = append(pieces, word[last:p])
+		last = p
+	}
+	return append(pieces, word[last:])
+}
+
+// runPoints returns the byte offsets of the hyphenation points of a word
+// made only of letters.
+func (h *Hyphenator) runPoints(word string) []int {
+	n := utf8.RuneCountInString(word)
+	if n < h.LeftMin+h.RightMin {
+		return nil
+	}
+	lower := []rune(normalizeWord(word))
+
+	// breaks holds the rune indexes the word may break before.
+	breaks, ok := h.exceptions[string(lower)]
+	if !ok {
+		// Match every pattern against every substring of ".word." and
+		// keep the highest digit at each position; odd digits allow a
+		// break there.
+		text := append(append([]rune{'.'}, lower...), '.')
+		values := make([]uint8, len(text)+1)
+		for i := range text {
+			for j := i + 1; j <= len(text) && j-i <= h.maxLen; j++ {
+				digits, ok := h.patterns[string(text[i:j])]
+				if !ok {
+					continue
+				}
+				for k, d := range digits {
+					values[i+k] = max(values[i+k], d)
+				}
+			}
+		}
+		for k := 1; k < n; k++ {
+			if values[k+1]%2 == 1 { // values[k+1] is before word rune k
+				breaks = append(breaks, k)
+			}
+		}
+	}
+
+	var points []int
+	k := 0
+	for i := range word {
+		if k >= h.LeftMin && n-k >= h.RightMin && slices.Contains(breaks, k) {
+			points = append(points, i)
+		}
+		k++
+	}
+	return points
+}
+
+// normalizeWord lower-cases word and uses the apostrophe the patterns use.
+func normalizeWord(word string) string {
+	return strings.Map(func(r rune) rune {
+		if r == '’' {
+			return '\''
+		}
+		return unicode.ToLower(r)
+	}, word)
+}
+
+// isLetter reports whether r can be part of a hyphenated word. Apostrophes
+// are included for French ("l'homme").
+func isLetter(r rune) bool {
+	return unicode.IsLetter(r) || r == '\'' || r == '’'
+}
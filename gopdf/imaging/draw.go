@@ -0,0 +1,256 @@
+package imaging
+
+import (
+	"errors"
+	"image/color"
+
+	"github.com/signintech/gopdf"
+
+	"pdf-tutorial/gopdf/fonts"
+	"pdf-tutorial/gopdf/page"
+	"pdf-tutorial/gopdf/shape"
+	"pdf-tutorial/gopdf/textlayout"
+)
+
+// Fit is how an image fills its box.
+type Fit int
+
+const (
+	Contain Fit = iota // As large as fits in the box, all of it shown
+	Cover              // As small as fills the box, clipped to it
+	Stretch            // Exactly the box, distorted if need be
+	Native             // At its own size, from its resolution, clipped to the box
+)
+
+// Options controls how an image is placed. Zero fields get the default
+// given in their comment.
+type Options struct {
+	Fit Fit // (Contain)
+
+	// Align places the image in the box when it does not fill it, or the
+	// part of the image shown when it is larger: gopdf.Left, gopdf.Center
+	// or gopdf.Right, or'ed with gopdf.Top, gopdf.Middle or gopdf.Bottom
+	// (centered both ways). The caption is aligned the same way.
+	Align int
+
+	// DPI is the resolution of images whose file gives none, which sets
+	// their size for Native and for a box with neither width nor height
+	// (72, a pixel a point).
+	DPI float64
+
+	Border      float64     // Width of a border around the image, in points (none)
+	BorderColor color.Color // (black)
+
+	// Caption is a text below the image, wrapped to the width of the box.
+	Caption       string
+	CaptionFamily string      // (fonts.Sans)
+	CaptionSize   float64     // In points (9)
+	CaptionColor  color.Color // (black)
+}
+
+func (o *Options) setDefaults() {
+	if o.DPI <= 0 {
+		o.DPI = 72
+	}
+	if o.BorderColor == nil {
+		o.BorderColor = color.Black
+	}
+	if o.CaptionFamily == "" {
+		o.CaptionFamily = fonts.Sans
+	}
+	if o.CaptionSize <= 0 {
+		o.CaptionSize = 9
+	}
+	if o.CaptionColor == nil {
+		o.CaptionColor = color.Black
+	}
+}
+
+// placement is where an image and its caption go.
+type placement struct {
+	image   page.Box // The whole image, scaled
+	visible page.Box // The part of it inside the box
+	caption []textlayout.Line
+	height  float64 // From the top of the box to the bottom of the caption
+}
+
+// Draw draws img in box, whose coordinates are in the unit of the
+// document, and returns the height it took from the top of the box: the
+// bottom of the caption, or of the image if it has none. If box has no
+// height, the image gets the one that keeps its proportions for the width;
+// if it has no width either, it is drawn at its own size.
+func (img *Image) Draw(reg *fonts.Registry, box page.Box, opts Options) (float64, error) {
+	opts.setDefaults()
+	p, err := img.place(reg, box, opts)
+	if err != nil {
+		return 0, err
+	}
+	pdf := reg.PDF()
+
+	iopts := gopdf.ImageOptions{
+		X:    p.image.X,
+		Y:    p.image.Y,
+		Rect: &gopdf.Rect{W: p.image.W, H: p.image.H},
+	}
+	if p.visible != p.image {
+		iopts.X, iopts.Y = p.visible.X, p.visible.Y
+		iopts.Crop = &gopdf.CropOptions{
+			X:      pdf.UnitsToPoints(p.visible.X - p.image.X),
+			Y:      pdf.UnitsToPoints(p.visible.Y - p.image.Y),
+			Width:  pdf.UnitsToPoints(p.visible.W),
+			Height: pdf.UnitsToPoints(p.visible.H),
+		}
+	}
+	if err := pdf.ImageByHolderWithOptions(img.holder(), iopts); err != nil {
+		return 0, err
+	}
+
+	if opts.Border > 0 {
+		v := p.visible
+		err := shape.New().Rect(v.X, v.Y, v.W, v.H).Draw(pdf, shape.Style{
+			Stroke: opts.BorderColor,
+			Width:  opts.Border,
+		})
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	if len(p.caption) > 0 {
+		if err := reg.SetFont(opts.CaptionFamily, fonts.Regular, opts.CaptionSize); err != nil {
+			return 0, err
+		}
+		r, g, b, _ := opts.CaptionColor.RGBA()
+		pdf.SetTextColor(uint8(r>>8), uint8(g>>8), uint8(b>>8))
+		err := textlayout.Draw(reg, p.caption)
+		pdf.SetTextColor(0, 0, 0)
+		if err != nil {
+			return 0, err
+		}
+	}
+	return p.height, nil
+}
+
+// Height returns the height Draw takes for img in a box of width w and no
+// height, such as to reserve a block of a flow.Flow for it.
+func (img *Image) Height(reg *fonts.Registry, w float64, opts Options) (float64, error) {
+	opts.setDefaults()
+	p, err := img.place(reg, page.Box{W: w}, opts)
+	return p.height, err
+}
+
+// place lays out img and its caption in box.
+func (img *Image) place(reg *fonts.Registry, box page.Box, opts Options) (placement, error) {
+	pdf := reg.PDF()
+	if img.width <= 0 || img.height <= 0 {
+		return placement{}, errors.New("imaging: image has no pixels")
+	}
+
+	// The size of the image at its resolution.
+	dpiX, dpiY := img.dpiX, img.dpiY
+	if dpiX <= 0 || dpiY <= 0 {
+		dpiX, dpiY = opts.DPI, opts.DPI
+	}
+	nw := pdf.PointsToUnits(float64(img.width) / dpiX * 72)
+	nh := pdf.PointsToUnits(float64(img.height) / dpiY * 72)
+
+	switch {
+	case box.W <= 0 && box.H <= 0:
+		box.W = nw
+	case box.W <= 0 && opts.Fit == Native:
+		box.W = nw
+	case box.W <= 0:
+		box.W = box.H * nw / nh
+	}
+
+	// The caption takes lines of 1.25 times its size below the image,
+	// after a gap of 0.4 times its size.
+	var p placement
+	var captionH float64
+	if opts.Caption != "" {
+		if err := reg.SetFont(opts.CaptionFamily, fonts.Regular, opts.CaptionSize); err != nil {
+			return p, err
+		}
+		size := pdf.PointsToUnits(opts.CaptionSize)
+		lines, err := textlayout.Layout(reg, opts.Caption, box.X, 0, textlayout.Options{
+			Width:      box.W,
+			LineHeight: size * 1.25,
+		})
+		if err != nil {
+			return p, err
+		}
+		p.caption = lines
+		captionH = size*0.4 + float64(len(lines))*size*1.25
+	}
+
+	area := box
+	if box.H > 0 {
+		area.H -= captionH
+		if area.H <= 0 {
+			return p, errors.New("imaging: box too low for the caption")
+		}
+	} else if opts.Fit == Native {
+		area.H = nh
+	} else {
+		area.H = box.W * nh / nw
+	}
+
+	w, h := nw, nh
+	switch opts.Fit {
+	case Contain:
+		s := min(area.W/nw, area.H/nh)
+		w, h = nw*s, nh*s
+	case Cover:
+		s := max(area.W/nw, area.H/nh)
+		w, h = nw*s, nh*s
+	case Stretch:
+		w, h = area.W, area.H
+	}
+	fx, fy := alignment(opts.Align)
+	p.image = page.Box{X: area.X + (area.W-w)*fx, Y: area.Y + (area.H-h)*fy, W: w, H: h}
+
+	p.visible = p.image
+	if w > area.W {
+		p.visible.X, p.visible.W = area.X, area.W
+	}
+	if h > area.H {
+		p.visible.Y, p.visible.H = area.Y, area.H
+	}
+
+	p.height = p.visible.Bottom() - box.Y
+	if len(p.caption) > 0 {
+		size := pdf.PointsToUnits(opts.CaptionSize)
+		y := p.visible.Bottom() + size*0.4
+		for i := range p.caption {
+			l := &p.caption[i]
+			dx := (box.W - l.Width) * fx
+			l.X += dx
+			for j := range l.Spans {
+				l.Spans[j].X += dx
+			}
+			// The baseline sits at 80% of the line, as in flow.Text.
+			l.Y = y + float64(i)*size*1.25 + size
+		}
+		p.height += captionH
+	}
+	return p, nil
+}
+
+// alignment returns where an image goes in the room left beside it, from
+// 0 for the left or top to 1 for the right or bottom.
+func alignment(align int) (fx, fy float64) {
+	fx, fy = 0.5, 0.5
+	switch {
+	case align&gopdf.Left != 0:
+		fx = 0
+	case align&gopdf.Right != 0:
+		fx = 1
+	}
+	switch {
+	case align&gopdf.Top != 0:
+		fy = 0
+	case align&gopdf.Bottom != 0:
+		fy = 1
+	}
+	return fx, fy
+}
@@ -0,0 +1,146 @@
+// Package imaging places JPEG and PNG images on gopdf pages without
+// distorting them: an image fits its box by one of several modes, aligned
+// within it, with an optional border and caption, at a size that follows
+// its pixel size and resolution.
+//
+//	img, err := imaging.Open("images/photo.jpg")
+//	...
+//	h, err := img.Draw(reg, page.Box{X: 50, Y: 100, W: 200, H: 150}, imaging.Options{
+//		Fit:     imaging.Cover,
+//		Caption: "The harbor at dawn",
+//	})
+//
+// Draw returns the height it took, so the content after it can start
+// below; with no box height, the height follows from the width.
+//
+// Images are also read from an io.Reader, a byte slice or an fs.FS such as
+// an embed.FS. The PDF embeds each distinct content once, however many
+// times and from whichever source it is placed, and a Cache shares one
+// Image between all the reads of the same content.
+package imaging
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"image"
+	_ "image/jpeg" // Registers the formats DecodeConfig reads
+	_ "image/png"
+	"os"
+)
+
+// ErrFormat is returned for an image that is not a JPEG or PNG file, the
+// formats gopdf embeds.
+var ErrFormat = errors.New("imaging: not a JPEG or PNG image")
+
+// Image is an encoded JPEG or PNG image, with its size and resolution.
+type Image struct {
+	data   []byte
+	sum    [32]byte // sha256 of data
+	format string   // "jpeg" or "png"
+	width  int      // In pixels
+	height int
+	dpiX   float64 // Resolution the file gives, 0 if none
+	dpiY   float64
+}
+
+// Open reads the image in the file name.
+func Open(name string) (*Image, error) {
+	data, err := os.ReadFile(name)
+	if err != nil {
+		return nil, fmt.Errorf("imaging: %w", err)
+	}
+	return parse(data)
+}
+
+// parse reads the size and resolution of the image in data.
+func parse(data []byte) (*Image, error) {
+	cfg, format, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		if errors.Is(err, image.ErrFormat) {
+			return nil, ErrFormat
+		}
+		return nil, fmt.Errorf("imaging: %w", err)
+	}
+	if format != "jpeg" && format != "png" {
+		return nil, ErrFormat
+	}
+	img := &Image{
+		data:   data,
+		sum:    sha256.Sum256(data),
+		format: format,
+		width:  cfg.Width,
+		height: cfg.Height,
+	}
+	if format == "jpeg" {
+		img.dpiX, img.dpiY = jfifDPI(data)
+	} else {
+		img.dpiX, img.dpiY = pngDPI(data)
+	}
+	return img, nil
+}
+
+// Size returns the width and height of img in pixels.
+func (img *Image) Size() (w, h int) {
+	return img.width, img.height
+}
+
+// DPI returns the horizontal and vertical resolution img gives, in dots
+// per inch, or 0, 0 if it gives none.
+func (img *Image) DPI() (x, y float64) {
+	return img.dpiX, img.dpiY
+}
+
+// Format returns "jpeg" or "png".
+func (img *Image) Format() string {
+	return img.format
+}
+
+// jfifDPI returns the resolution in the JFIF segment of a JPEG file.
+func jfifDPI(data []byte) (x, y float64) {
+	// Segments follow the start of image marker, each a marker and a
+	// length that counts itself, up to the start of scan.
+	for i := 2; i+4 <= len(data) && data[i] == 0xff; {
+		marker := data[i+1]
+		n := int(binary.BigEndian.Uint16(data[i+2:]))
+		if marker == 0xda || n < 2 { // A length below 2 is a damaged file
+			break
+		}
+		seg := data[i+4 : min(i+2+n, len(data))]
+		// APP0: "JFIF\0", version, units, densities.
+		if marker == 0xe0 && len(seg) >= 12 && string(seg[:5]) == "JFIF\x00" {
+			dx := float64(binary.BigEndian.Uint16(seg[8:]))
+			dy := float64(binary.BigEndian.Uint16(seg[10:]))
+			switch seg[7] {
+			case 1: // Dots per inch
+				return dx, dy
+			case 2: // Dots per centimeter
+				return dx * 2.54, dy * 2.54
+			}
+			return 0, 0
+		}
+		i += 2 + n
+	}
+	return 0, 0
+}
+
+// pngDPI returns the resolution in the pHYs chunk of a PNG file.
+func pngDPI(data []byte) (x, y float64) {
+	// Chunks follow the 8 byte signature, each a length, a type, the data
+	// and a checksum.
+	for i := 8; i+8 <= len(data); {
+		n := int(binary.BigEndian.Uint32(data[i:]))
+		typ := string(data[i+4 : i+8])
+		if typ == "IDAT" || n < 0 || i+12+n > len(data) {
+			break
+		}
+		if typ == "pHYs" && n == 9 && data[i+16] == 1 { // Pixels per meter
+			c := data[i+8:]
+			return float64(binary.BigEndian.Uint32(c)) * 0.0254, float64(binary.BigEndian.Uint32(c[4:])) * 0.0254
+		}
+		i += 12 + n
+	}
+	return 0, 0
+}
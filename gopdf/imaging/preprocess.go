@@ -0,0 +1,191 @@
+package imaging
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"math"
+
+	xdraw "golang.org/x/image/draw"
+
+	"pdf-tutorial/gopdf/fonts"
+	"pdf-tutorial/gopdf/page"
+)
+
+// PreprocessOptions controls how Preprocess reduces an image. Zero fields
+// get the default given in their comment.
+type PreprocessOptions struct {
+	// MaxDPI is the highest resolution kept for the size the image is
+	// placed at; an image with more pixels is downsampled to it, one with
+	// fewer keeps them (150).
+	MaxDPI float64
+
+	// Quality is the JPEG quality JPEG images are re-encoded at, from 1 to
+	// 100 (75). PNG images stay PNG, lossless, with their transparency.
+	Quality int
+
+	Grayscale bool // Converts the image to shades of gray
+}
+
+func (o *PreprocessOptions) setDefaults() {
+	if o.MaxDPI <= 0 {
+		o.MaxDPI = 150
+	}
+	if o.Quality <= 0 {
+		o.Quality = 75
+	}
+	o.Quality = min(o.Quality, 100)
+}
+
+// Report tells what Preprocess did to an image.
+type Report struct {
+	Width, Height       int // In pixels, before
+	NewWidth, NewHeight int // In pixels, after
+	Bytes, NewBytes     int // Size of the encoded image, before and after
+}
+
+// Saved returns the number of bytes Preprocess saved.
+func (r Report) Saved() int {
+	return r.Bytes - r.NewBytes
+}
+
+func (r Report) String() string {
+	pct := 0.0
+	if r.Bytes > 0 {
+		pct = float64(r.Saved()) * 100 / float64(r.Bytes)
+	}
+	return fmt.Sprintf("%dx%d to %dx%d pixels, %d to %d bytes (%.0f%% saved)",
+		r.Width, r.Height, r.NewWidth, r.NewHeight, r.Bytes, r.NewBytes, pct)
+}
+
+// Preprocess returns a copy of img reduced for drawing in box with opts,
+// as Draw would: downsampled to pre.MaxDPI for the size it takes there,
+// re-encoded and, if asked, in gray. The copy has the same size in the
+// document as img, so it is drawn with the same box and options, or
+// smaller ones; for an image drawn at several sizes, preprocess it once for
+// the largest, so that it is embedded once.
+//
+// Transparent PNG images keep their alpha channel, which gopdf embeds as a
+// soft mask; their edges are averaged with their alpha, so that they take
+// no color from the transparent pixels. If the copy would only come out
+// larger, Preprocess returns img itself.
+func (img *Image) Preprocess(reg *fonts.Registry, box page.Box, opts Options, pre PreprocessOptions) (*Image, Report, error) {
+	opts.setDefaults()
+	pre.setDefaults()
+	report := Report{
+		Width: img.width, Height: img.height,
+		NewWidth: img.width, NewHeight: img.height,
+		Bytes: len(img.data), NewBytes: len(img.data),
+	}
+	p, err := img.place(reg, box, opts)
+	if err != nil {
+		return nil, report, err
+	}
+
+	// The pixels the image needs at pre.MaxDPI for its size in points.
+	pdf := reg.PDF()
+	w := int(math.Ceil(pdf.UnitsToPoints(p.image.W) / 72 * pre.MaxDPI))
+	h := int(math.Ceil(pdf.UnitsToPoints(p.image.H) / 72 * pre.MaxDPI))
+	w, h = min(max(w, 1), img.width), min(max(h, 1), img.height)
+
+	src, _, err := image.Decode(bytes.NewReader(img.data))
+	if err != nil {
+		return nil, report, fmt.Errorf("imaging: %w", err)
+	}
+	dst := src
+	scale := w < img.width || h < img.height
+	if scale {
+		// CatmullRom works on premultiplied colors, which keeps the color
+		// of transparent pixels out of the edges.
+		scaled := image.NewRGBA(image.Rect(0, 0, w, h))
+		xdraw.CatmullRom.Scale(scaled, scaled.Bounds(), src, src.Bounds(), xdraw.Src, nil)
+		dst = scaled
+	}
+	if pre.Grayscale {
+		dst = gray(dst)
+	}
+
+	var buf bytes.Buffer
+	if img.format == "jpeg" {
+		err = jpeg.Encode(&buf, dst, &jpeg.Options{Quality: pre.Quality})
+	} else {
+		if !embeddable(dst) {
+			dst = toRGBA(dst)
+		}
+		enc := png.Encoder{CompressionLevel: png.BestCompression}
+		err = enc.Encode(&buf, dst)
+	}
+	if err != nil {
+		return nil, report, fmt.Errorf("imaging: %w", err)
+	}
+	if !scale && !pre.Grayscale && buf.Len() >= len(img.data) {
+		return img, report, nil
+	}
+
+	out, err := parse(buf.Bytes())
+	if err != nil {
+		return nil, report, err
+	}
+	// The resolution follows the pixels, so that the copy has the size of
+	// img in the document.
+	dpiX, dpiY := img.dpiX, img.dpiY
+	if dpiX <= 0 || dpiY <= 0 {
+		dpiX, dpiY = opts.DPI, opts.DPI
+	}
+	out.dpiX = dpiX * float64(w) / float64(img.width)
+	out.dpiY = dpiY * float64(h) / float64(img.height)
+
+	report.NewWidth, report.NewHeight = w, h
+	report.NewBytes = len(out.data)
+	return out, report, nil
+}
+
+// gray returns m in shades of gray, keeping its alpha channel if it has one.
+func gray(m image.Image) image.Image {
+	b := m.Bounds()
+	if opaque(m) {
+		g := image.NewGray(b)
+		xdraw.Draw(g, b, m, b.Min, xdraw.Src)
+		return g
+	}
+	// Gray is a weighted sum of the channels, so it is the same on
+	// premultiplied ones.
+	g := image.NewRGBA(b)
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			c := color.RGBAModel.Convert(m.At(x, y)).(color.RGBA)
+			v := color.GrayModel.Convert(color.RGBA{c.R, c.G, c.B, 0xff}).(color.Gray).Y
+			g.SetRGBA(x, y, color.RGBA{v, v, v, c.A})
+		}
+	}
+	return g
+}
+
+// toRGBA returns m as an 8 bit RGBA image.
+func toRGBA(m image.Image) *image.RGBA {
+	b := m.Bounds()
+	rgba := image.NewRGBA(b)
+	xdraw.Draw(rgba, b, m, b.Min, xdraw.Src)
+	return rgba
+}
+
+// opaque reports whether m has no transparent pixel.
+func opaque(m image.Image) bool {
+	if o, ok := m.(interface{ Opaque() bool }); ok {
+		return o.Opaque()
+	}
+	return false
+}
+
+// embeddable reports whether gopdf embeds m as png.Encoder writes it: with
+// 8 bits a channel, and with no palette, whose transparency it drops.
+func embeddable(m image.Image) bool {
+	switch m.(type) {
+	case *image.Gray16, *image.RGBA64, *image.NRGBA64, *image.Paletted:
+		return false
+	}
+	return true
+}
@@ -0,0 +1,128 @@
+package imaging
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"sync"
+)
+
+// Decode reads an image from r, such as an HTTP response body or an
+// object from a storage bucket.
+func Decode(r io.Reader) (*Image, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("imaging: %w", err)
+	}
+	return parse(data)
+}
+
+// FromBytes reads an image from data, which it keeps: data must not change
+// afterwards.
+func FromBytes(data []byte) (*Image, error) {
+	return parse(data)
+}
+
+// OpenFS reads the image in the file name of fsys, such as an embed.FS.
+func OpenFS(fsys fs.FS, name string) (*Image, error) {
+	data, err := fs.ReadFile(fsys, name)
+	if err != nil {
+		return nil, fmt.Errorf("imaging: %w", err)
+	}
+	return parse(data)
+}
+
+// holder gives gopdf the data of an image, under an ID that is the hash of
+// the data: gopdf embeds the image once, the first time, and refers to it
+// every other time the ID comes back, whatever the image was read from.
+type holder struct {
+	*bytes.Reader
+	id string
+}
+
+func (h holder) ID() string {
+	return h.id
+}
+
+func (img *Image) holder() holder {
+	return holder{Reader: bytes.NewReader(img.data), id: fmt.Sprintf("sha256:%x", img.sum)}
+}
+
+// Cache hands out one Image for every distinct content, however many times
+// and from whatever source it is read, so a logo placed on every page of
+// many documents is decoded and held in memory once. It is safe for
+// concurrent use.
+type Cache struct {
+	mu     sync.Mutex
+	images map[[32]byte]*Image
+}
+
+// NewCache returns an empty Cache.
+func NewCache() *Cache {
+	return &Cache{images: make(map[[32]byte]*Image)}
+}
+
+// Decode reads an image from r, or returns the one of the cache with the
+// same content.
+func (c *Cache) Decode(r io.Reader) (*Image, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("imaging: %w", err)
+	}
+	return c.FromBytes(data)
+}
+
+// FromBytes reads an image from data, or returns the one of the cache with
+// the same content.
+func (c *Cache) FromBytes(data []byte) (*Image, error) {
+	sum := sha256.Sum256(data)
+	c.mu.Lock()
+	img, ok := c.images[sum]
+	c.mu.Unlock()
+	if ok {
+		return img, nil
+	}
+
+	img, err := parse(data)
+	if err != nil {
+		return nil, err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	// Another goroutine may have added it meanwhile.
+	if prev, ok := c.images[sum]; ok {
+		return prev, nil
+	}
+	c.images[sum] = img
+	return img, nil
+}
+
+// Open reads the image in the file name, or returns the one of the cache
+// with the same content.
+func (c *Cache) Open(name string) (*Image, error) {
+	data, err := os.ReadFile(name)
+	if err != nil {
+		return nil, fmt.Errorf("imaging: %w", err)
+	}
+	return c.FromBytes(data)
+}
+
+// OpenFS reads the image in the file name of fsys, or returns the one of
+// the cache with the same content.
+func (c *Cache) OpenFS(fsys fs.FS, name string) (*Image, error) {
+	data, err := fs.ReadFile(fsys, name)
+	if err != nil {
+		return nil, fmt.Errorf("imaging: %w", err)
+	}
+	return c.FromBytes(data)
+}
+
+// Len returns the number of distinct images in the cache.
+func (c *Cache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.images)
+}
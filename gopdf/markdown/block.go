@@ -0,0 +1,355 @@
+package markdown
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// blockKind is the type of a block.
+type blockKind int
+
+const (
+	paragraphBlock blockKind = iota
+	headingBlock
+	codeBlock
+	quoteBlock
+	listBlock
+	itemBlock
+	tableBlock
+	ruleBlock
+)
+
+// block is a node of the document tree.
+type block struct {
+	kind     blockKind
+	text     string   // Inline text of a paragraph or heading; content of a code block
+	level    int      // Heading level, 1 to 6
+	ordered  bool     // Whether a list is numbered
+	start    int      // First number of an ordered list
+	loose    bool     // Whether list items are separated by blank lines
+	children []*block // Blocks of a quote or list item; items of a list
+
+	// Tables.
+	header []string
+	align  []align
+	rows   [][]string
+}
+
+// align is the alignment of a table column.
+type align int
+
+const (
+	alignLeft align = iota
+	alignCenter
+	alignRight
+)
+
+var (
+	atxHeading   = regexp.MustCompile(`^ {0,3}(#{1,6})(?:[ \t]+(.*?))?(?:[ \t]+#+)?[ \t]*$`)
+	thematic     = regexp.MustCompile(`^ {0,3}(?:(?:-[ \t]*){3,}|(?:\*[ \t]*){3,}|(?:_[ \t]*){3,})$`)
+	setextLine   = regexp.MustCompile(`^ {0,3}(=+|-+)[ \t]*$`)
+	fenceOpen    = regexp.MustCompile("^( {0,3})(`{3,}|~{3,})[ \t]*([^`]*)$")
+	listMarker   = regexp.MustCompile(`^( {0,3})([-+*]|\d{1,9}[.)])([ \t]+|$)`)
+	quoteMarker  = regexp.MustCompile(`^ {0,3}> ?`)
+	tableDivider = regexp.MustCompile(`^ {0,3}\|?[ \t]*:?-+:?[ \t]*(\|[ \t]*:?-+:?[ \t]*)*\|?[ \t]*$`)
+	linkDef      = regexp.MustCompile(`^ {0,3}\[([^\]]+)\]:[ \t]*<?([^ \t>]+)>?(?:[ \t]+["'(].*["')])?[ \t]*$`)
+)
+
+// parser splits a document into blocks.
+type parser struct {
+	refs map[string]string // Link reference definitions, by normalized label
+}
+
+// parseBlocks parses lines into blocks.
+func (p *parser) parseBlocks(lines []string) []*block {
+	var blocks []*block
+	var para []string // Lines of the paragraph being read
+	endPara := func() {
+		if len(para) > 0 {
+			text := strings.TrimSpace(strings.Join(para, "\n"))
+			blocks = append(blocks, &block{kind: paragraphBlock, text: text})
+			para = nil
+		}
+	}
+
+	for i := 0; i < len(lines); i++ {
+		line := lines[i]
+		switch {
+		case strings.TrimSpace(line) == "":
+			endPara()
+
+		case len(para) > 0 && setextLine.MatchString(line) && !listMarker.MatchString(line):
+			level := 1
+			if strings.TrimSpace(line)[0] == '-' {
+				level = 2
+			}
+			text := strings.TrimSpace(strings.Join(para, "\n"))
+			blocks = append(blocks, &block{kind: headingBlock, level: level, text: text})
+			para = nil
+
+		case thematic.MatchString(line):
+			endPara()
+			blocks = append(blocks, &block{kind: ruleBlock})
+
+		case atxHeading.MatchString(line):
+			endPara()
+			m := atxHeading.FindStringSubmatch(line)
+			blocks = append(blocks, &block{kind: headingBlock, level: len(m[1]), text: strings.TrimSpace(m[2])})
+
+		case fenceOpen.MatchString(line):
+			endPara()
+			var b *block
+			b, i = p.fencedCode(lines, i)
+			blocks = append(blocks, b)
+
+		case len(para) == 0 && indentWidth(line) >= 4:
+			var code []string
+			for ; i < len(lines) && (indentWidth(lines[i]) >= 4 || strings.TrimSpace(lines[i]) == ""); i++ {
+				code = append(code, stripIndent(lines[i], 4))
+			}
+			i--
+			for len(code) > 0 && strings.TrimSpace(code[len(code)-1]) == "" {
+				code = code[:len(code)-1]
+			}
+			blocks = append(blocks, &block{kind: codeBlock, text: strings.Join(code, "\n")})
+
+		case quoteMarker.MatchString(line):
+			endPara()
+			var inner []string
+			for ; i < len(lines); i++ {
+				l := lines[i]
+				if m := quoteMarker.FindString(l); m != "" {
+					inner = append(inner, l[len(m):])
+				} else if strings.TrimSpace(l) != "" && len(inner) > 0 && !startsBlock(l) &&
+					strings.TrimSpace(inner[len(inner)-1]) != "" {
+					inner = append(inner, l) // Lazy continuation of a paragraph
+				} else {
+					break
+				}
+			}
+			i--
+			blocks = append(blocks, &block{kind: quoteBlock, children: p.parseBlocks(inner)})
+
+		case listMarker.MatchString(line) && (len(para) == 0 || canInterrupt(line)):
+			endPara()
+			var b *block
+			b, i = p.list(lines, i)
+			blocks = append(blocks, b)
+
+		case i+1 < len(lines) && strings.Contains(line, "|") &&
+			tableDivider.MatchString(lines[i+1]) && len(splitRow(line)) == len(splitRow(lines[i+1])):
+			// As in GFM, a table interrupts a paragraph.
+			endPara()
+			var b *block
+			b, i = tableRows(lines, i)
+			blocks = append(blocks, b)
+
+		case len(para) == 0 && linkDef.MatchString(line):
+			m := linkDef.FindStringSubmatch(line)
+			if _, ok := p.refs[normalizeLabel(m[1])]; !ok {
+				p.refs[normalizeLabel(m[1])] = m[2]
+			}
+
+		default:
+			para = append(para, line)
+		}
+	}
+	endPara()
+	return blocks
+}
+
+// fencedCode reads a fenced code block starting at lines[i], and returns it
+// with the index of its last line.
+func (p *parser) fencedCode(lines []string, i int) (*block, int) {
+	m := fenceOpen.FindStringSubmatch(lines[i])
+	indent, fence := len(m[1]), m[2]
+	var code []string
+	for i++; i < len(lines); i++ {
+		t := strings.TrimSpace(lines[i])
+		if indentWidth(lines[i]) < 4 && strings.HasPrefix(t, fence) && strings.Trim(t, fence[:1]) == "" {
+			break
+		}
+		code = append(code, stripIndent(lines[i], indent))
+	}
+	return &block{kind: codeBlock, text: strings.Join(code, "\n")}, i
+}
+
+// list reads a list starting at lines[i], and returns it with the index of
+// its last line.
+func (p *parser) list(lines []string, i int) (*block, int) {
+	first := listMarker.FindStringSubmatch(lines[i])
+	list := &block{kind: listBlock}
+	if n, err := strconv.Atoi(strings.TrimRight(first[2], ".)")); err == nil {
+		list.ordered, list.start = true, n
+	}
+	delim := first[2][len(first[2])-1:] // "-", "+", "*", "." or ")"
+
+	blankBetween := false
+	for i < len(lines) {
+		m := listMarker.FindStringSubmatch(lines[i])
+		if m == nil || m[2][len(m[2])-1:] != delim {
+			break
+		}
+		// Content starts after the marker and one to four spaces; with
+		// more spaces, it is indented code after the first space.
+		width, content := len(m[0]), lines[i][len(m[0]):]
+		if m[3] == "" || len(m[3]) > 4 {
+			width = len(m[1]) + len(m[2]) + 1
+			content = strings.Repeat(" ", max(len(m[3])-1, 0)) + content
+		}
+		item := []string{content}
+
+		for i++; i < len(lines); i++ {
+			l := lines[i]
+			switch {
+			case strings.TrimSpace(l) == "":
+				item = append(item, "")
+				continue
+			case indentWidth(l) >= width:
+				item = append(item, stripIndent(l, width))
+				continue
+			case strings.TrimSpace(item[len(item)-1]) != "" && !startsBlock(l) && !listMarker.MatchString(l):
+				item = append(item, l) // Lazy continuation of a paragraph
+				continue
+			}
+			break
+		}
+
+		// Trailing blank lines separate this item from the next one.
+		n := len(item)
+		for n > 0 && strings.TrimSpace(item[n-1]) == "" {
+			n--
+		}
+		if n < len(item) && i < len(lines) && listMarker.MatchString(lines[i]) {
+			blankBetween = true
+		}
+		children := p.parseBlocks(item[:n])
+		if hasInnerBlank(item[:n]) && len(children) > 1 {
+			list.loose = true
+		}
+		list.children = append(list.children, &block{kind: itemBlock, children: children})
+	}
+	if blankBetween {
+		list.loose = true
+	}
+	return list, i - 1
+}
+
+// tableRows reads a table starting at lines[i], and returns it with the
+// index of its last line.
+func tableRows(lines []string, i int) (*block, int) {
+	t := &block{kind: tableBlock, header: splitRow(lines[i])}
+	for _, cell := range splitRow(lines[i+1]) {
+		switch {
+		case strings.HasPrefix(cell, ":") && strings.HasSuffix(cell, ":"):
+			t.align = append(t.align, alignCenter)
+		case strings.HasSuffix(cell, ":"):
+			t.align = append(t.align, alignRight)
+		default:
+			t.align = append(t.align, alignLeft)
+		}
+	}
+	for i += 2; i < len(lines) && strings.TrimSpace(lines[i]) != "" && !startsBlock(lines[i]); i++ {
+		row := splitRow(lines[i])
+		// Rows have exactly as many cells as the header.
+		for len(row) < len(t.header) {
+			row = append(row, "")
+		}
+		t.rows = append(t.rows, row[:len(t.header)])
+	}
+	return t, i - 1
+}
+
+// splitRow splits a table row into trimmed cells. \| is a literal pipe.
+func splitRow(line string) []string {
+	line = strings.TrimSpace(line)
+	line = strings.TrimPrefix(line, "|")
+	if strings.HasSuffix(line, "|") && !strings.HasSuffix(line, `\|`) {
+		line = line[:len(line)-1]
+	}
+	var cells []string
+	var cell strings.Builder
+	for i := 0; i < len(line); i++ {
+		switch {
+		case line[i] == '\\' && i+1 < len(line) && line[i+1] == '|':
+			cell.WriteByte('|')
+			i++
+		case line[i] == '|':
+			cells = append(cells, strings.TrimSpace(cell.String()))
+			cell.Reset()
+		default:
+			cell.WriteByte(line[i])
+		}
+	}
+	return append(cells, strings.TrimSpace(cell.String()))
+}
+
+// startsBlock reports whether line starts a block that ends a paragraph.
+func startsBlock(line string) bool {
+	return thematic.MatchString(line) || atxHeading.MatchString(line) || fenceOpen.MatchString(line) ||
+		quoteMarker.MatchString(line) || (listMarker.MatchString(line) && canInterrupt(line))
+}
+
+// canInterrupt reports whether a list item line may end a paragraph: it
+// must not be empty, and a numbered list must start at 1.
+func canInterrupt(line string) bool {
+	m := listMarker.FindStringSubmatch(line)
+	if m == nil || strings.TrimSpace(line[len(m[0]):]) == "" {
+		return false
+	}
+	n, err := strconv.Atoi(strings.TrimRight(m[2], ".)"))
+	return err != nil || n == 1
+}
+
+// hasInnerBlank reports whether lines contain a blank line between two
+// non-blank ones.
+func hasInnerBlank(lines []string) bool {
+	for i := 1; i+1 < len(lines); i++ {
+		if strings.TrimSpace(lines[i]) == "" {
+			return true
+		}
+	}
+	return false
+}
+
+// indentWidth returns the width of the leading white space of line, with
+// tabs to multiples of 4.
+func indentWidth(line string) int {
+	w := 0
+	for _, c := range line {
+		switch c {
+		case ' ':
+			w++
+		case '\t':
+			w += 4 - w%4
+		default:
+			return w
+		}
+	}
+	return w
+}
+
+// stripIndent removes up to n columns of leading white space.
+func stripIndent(line string, n int) string {
+	w := 0
+	for i, c := range line {
+		if w >= n || (c != ' ' && c != '\t') {
+			return line[i:]
+		}
+		if c == '\t' {
+			w += 4 - w%4
+			if w > n {
+				return strings.Repeat(" ", w-n) + line[i+1:]
+			}
+		} else {
+			w++
+		}
+	}
+	return ""
+}
+
+// normalizeLabel folds case and white space in a link label.
+func normalizeLabel(label string) string {
+	return strings.ToLower(strings.Join(strings.Fields(label), " "))
+}
@@ -0,0 +1,373 @@
+package markdown
+
+import (
+	"html"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"pdf-tutorial/gopdf/fonts"
+)
+
+// span is a piece of inline text with its formatting.
+type span struct {
+	text  string
+	style fonts.Style
+	code  bool   // Code span, drawn in the code font
+	image bool   // Alternative text of an image
+	link  string // Link destination
+}
+
+// node is a span, or a run of * or _ that may open or close emphasis.
+type node struct {
+	span
+	delim             byte // '*' or '_' for delimiter runs
+	n, orig           int  // Characters left in the run, and at first
+	canOpen, canClose bool
+}
+
+var (
+	autolink  = regexp.MustCompile(`^<([a-zA-Z][a-zA-Z0-9+.-]{1,31}:[^<>\s]*)>`)
+	emailLink = regexp.MustCompile(`^<([a-zA-Z0-9.!#$%&'*+/=?^_{|}~-]+@[a-zA-Z0-9](?:[a-zA-Z0-9-]*[a-zA-Z0-9])?(?:\.[a-zA-Z0-9](?:[a-zA-Z0-9-]*[a-zA-Z0-9])?)*)>`)
+)
+
+// inline parses the inline content of a paragraph or heading.
+func (p *parser) inline(text string) []span {
+	var nodes []node
+	var buf strings.Builder
+	flush := func() {
+		if buf.Len() > 0 {
+			nodes = append(nodes, node{span: span{text: html.UnescapeString(buf.String())}})
+			buf.Reset()
+		}
+	}
+
+	for i := 0; i < len(text); {
+		c := text[i]
+		switch {
+		case c == '\\' && i+1 < len(text) && text[i+1] == '\n':
+			flush()
+			nodes = append(nodes, node{span: span{text: "\n"}})
+			i += 2
+			i = skipSpaces(text, i)
+
+		case c == '\\' && i+1 < len(text) && isASCIIPunct(text[i+1]):
+			buf.WriteByte(text[i+1])
+			i += 2
+
+		case c == '`':
+			n := runLength(text, i, '`')
+			if end := closingBackticks(text, i+n, n); end >= 0 {
+				flush()
+				code := strings.ReplaceAll(text[i+n:end], "\n", " ")
+				if len(code) > 2 && code[0] == ' ' && code[len(code)-1] == ' ' && strings.Trim(code, " ") != "" {
+					code = code[1 : len(code)-1]
+				}
+				nodes = append(nodes, node{span: span{text: code, code: true}})
+				i = end + n
+			} else {
+				buf.WriteString(text[i : i+n])
+				i += n
+			}
+
+		case c == '<' && (autolink.MatchString(text[i:]) || emailLink.MatchString(text[i:])):
+			flush()
+			m := autolink.FindStringSubmatch(text[i:])
+			dest := ""
+			if m != nil {
+				dest = m[1]
+			} else {
+				m = emailLink.FindStringSubmatch(text[i:])
+				dest = "mailto:" + m[1]
+			}
+			nodes = append(nodes, node{span: span{text: m[1], link: dest}})
+			i += len(m[0])
+
+		case c == '[' || (c == '!' && i+1 < len(text) && text[i+1] == '['):
+			image := c == '!'
+			start := i
+			if image {
+				start++
+			}
+			label, dest, end, ok := p.link(text, start)
+			if !ok {
+				buf.WriteString(text[i : start+1])
+				i = start + 1
+				continue
+			}
+			flush()
+			for _, s := range p.inline(label) {
+				if image {
+					s.image = true
+					s.style |= fonts.Italic
+				} else if s.link == "" {
+					s.link = dest
+				}
+				nodes = append(nodes, node{span: s})
+			}
+			i = end
+
+		case c == '*' || c == '_':
+			flush()
+			n := runLength(text, i, c)
+			prev, _ := utf8.DecodeLastRuneInString(text[:i])
+			next, _ := utf8.DecodeRuneInString(text[i+n:])
+			if i == 0 {
+				prev = ' '
+			}
+			if i+n == len(text) {
+				next = ' '
+			}
+			left := !unicode.IsSpace(next) && (!isPunct(next) || unicode.IsSpace(prev) || isPunct(prev))
+			right := !unicode.IsSpace(prev) && (!isPunct(prev) || unicode.IsSpace(next) || isPunct(next))
+			d := node{span: span{text: text[i : i+n]}, delim: c, n: n, orig: n, canOpen: left, canClose: right}
+			if c == '_' {
+				d.canOpen = left && (!right || isPunct(prev))
+				d.canClose = right && (!left || isPunct(next))
+			}
+			nodes = append(nodes, d)
+			i += n
+
+		case c == '\n':
+			// Two spaces at the end of a line make a hard line break;
+			// other line breaks are spaces.
+			s := buf.String()
+			trimmed := strings.TrimRight(s, " ")
+			buf.Reset()
+			buf.WriteString(trimmed)
+			if len(s)-len(trimmed) >= 2 {
+				flush()
+				nodes = append(nodes, node{span: span{text: "\n"}})
+			} else {
+				buf.WriteByte(' ')
+			}
+			i = skipSpaces(text, i+1)
+
+		default:
+			buf.WriteByte(c)
+			i++
+		}
+	}
+	flush()
+
+	emphasis(nodes)
+	var spans []span
+	for _, nd := range nodes {
+		if nd.delim != 0 {
+			nd.text = strings.Repeat(string(nd.delim), nd.n)
+		}
+		if nd.text != "" {
+			spans = append(spans, nd.span)
+		}
+	}
+	return spans
+}
+
+// emphasis matches the delimiter runs in nodes, as in CommonMark's
+// "process emphasis" procedure, and styles the nodes between them.
+func emphasis(nodes []node) {
+	for c := 0; c < len(nodes); c++ {
+		closer := &nodes[c]
+		if closer.delim == 0 || !closer.canClose {
+			continue
+		}
+		for closer.n > 0 {
+			o := -1
+			for j := c - 1; j >= 0; j-- {
+				opener := &nodes[j]
+				if opener.delim != closer.delim || !opener.canOpen || opener.n == 0 {
+					continue
+				}
+				// The "rule of 3": a run that can both open and close
+				// does not match if the lengths add up to a multiple of 3.
+				if (opener.canClose || closer.canOpen) && (opener.orig+closer.orig)%3 == 0 &&
+					!(opener.orig%3 == 0 && closer.orig%3 == 0) {
+					continue
+				}
+				o = j
+				break
+			}
+			if o < 0 {
+				break
+			}
+			opener := &nodes[o]
+			use, style := 1, fonts.Italic
+			if opener.n >= 2 && closer.n >= 2 {
+				use, style = 2, fonts.Bold
+			}
+			opener.n -= use
+			closer.n -= use
+			for k := o + 1; k < c; k++ {
+				nodes[k].style |= style
+				if nodes[k].delim != 0 {
+					// Unmatched runs inside are plain text now.
+					nodes[k].canOpen, nodes[k].canClose = false, false
+				}
+			}
+		}
+	}
+}
+
+// link parses a link that starts with the '[' at text[i]. It returns the
+// link text, the destination and the index after the link.
+func (p *parser) link(text string, i int) (label, dest string, end int, ok bool) {
+	rb := closingBracket(text, i)
+	if rb < 0 {
+		return "", "", 0, false
+	}
+	label = text[i+1 : rb]
+	rest := text[rb+1:]
+
+	switch {
+	case strings.HasPrefix(rest, "("):
+		dest, n, ok := inlineDestination(rest)
+		if ok {
+			return label, dest, rb + 1 + n, true
+		}
+	case strings.HasPrefix(rest, "[]"):
+		if dest, ok := p.refs[normalizeLabel(label)]; ok {
+			return label, dest, rb + 3, true
+		}
+		return "", "", 0, false
+	case strings.HasPrefix(rest, "["):
+		if refEnd := strings.IndexByte(rest, ']'); refEnd > 0 {
+			if dest, ok := p.refs[normalizeLabel(rest[1:refEnd])]; ok {
+				return label, dest, rb + 2 + refEnd, true
+			}
+			return "", "", 0, false
+		}
+	}
+	// Shortcut reference: [label] alone.
+	if dest, ok := p.refs[normalizeLabel(label)]; ok {
+		return label, dest, rb + 1, true
+	}
+	return "", "", 0, false
+}
+
+// inlineDestination parses `(dest "title")` at the start of s, and returns
+// the destination and the length of the whole.
+func inlineDestination(s string) (dest string, n int, ok bool) {
+	i := skipWhite(s, 1)
+	if i < len(s) && s[i] == '<' {
+		end := strings.IndexByte(s[i:], '>')
+		if end < 0 {
+			return "", 0, false
+		}
+		dest = s[i+1 : i+end]
+		i += end + 1
+	} else {
+		depth, start := 0, i
+		for ; i < len(s); i++ {
+			c := s[i]
+			if c == '\\' && i+1 < len(s) {
+				i++
+				continue
+			}
+			if c == '(' {
+				depth++
+			} else if c == ')' {
+				if depth == 0 {
+					break
+				}
+				depth--
+			} else if c == ' ' || c == '\t' || c == '\n' {
+				break
+			}
+		}
+		dest = s[start:i]
+	}
+
+	i = skipWhite(s, i)
+	if i < len(s) && (s[i] == '"' || s[i] == '\'' || s[i] == '(') {
+		closeQuote := s[i]
+		if closeQuote == '(' {
+			closeQuote = ')'
+		}
+		end := strings.IndexByte(s[i+1:], closeQuote)
+		if end < 0 {
+			return "", 0, false
+		}
+		i = skipWhite(s, i+end+2)
+	}
+	if i >= len(s) || s[i] != ')' {
+		return "", 0, false
+	}
+	return html.UnescapeString(dest), i + 1, true
+}
+
+// closingBracket returns the index of the ']' matching the '[' at
+// text[i], or -1.
+func closingBracket(text string, i int) int {
+	depth := 0
+	for j := i; j < len(text); j++ {
+		switch text[j] {
+		case '\\':
+			j++
+		case '`':
+			n := runLength(text, j, '`')
+			if end := closingBackticks(text, j+n, n); end >= 0 {
+				j = end + n - 1
+			} else {
+				j += n - 1
+			}
+		case '[':
+			depth++
+		case ']':
+			depth--
+			if depth == 0 {
+				return j
+			}
+		}
+	}
+	return -1
+}
+
+// closingBackticks returns the index of the next run of exactly n
+// backticks at or after i, or -1.
+func closingBackticks(text string, i, n int) int {
+	for i < len(text) {
+		j := strings.IndexByte(text[i:], '`')
+		if j < 0 {
+			return -1
+		}
+		j += i
+		m := runLength(text, j, '`')
+		if m == n {
+			return j
+		}
+		i = j + m
+	}
+	return -1
+}
+
+// runLength returns the number of c bytes starting at text[i].
+func runLength(text string, i int, c byte) int {
+	n := 0
+	for i+n < len(text) && text[i+n] == c {
+		n++
+	}
+	return n
+}
+
+func skipSpaces(text string, i int) int {
+	for i < len(text) && text[i] == ' ' {
+		i++
+	}
+	return i
+}
+
+func skipWhite(text string, i int) int {
+	for i < len(text) && (text[i] == ' ' || text[i] == '\t' || text[i] == '\n') {
+		i++
+	}
+	return i
+}
+
+func isASCIIPunct(c byte) bool {
+	return c < utf8.RuneSelf && unicode.IsPunct(rune(c)) || strings.IndexByte("$+<=>^`|~", c) >= 0
+}
+
+func isPunct(r rune) bool {
+	return unicode.IsPunct(r) || unicode.IsSymbol(r)
+}
@@ -0,0 +1,531 @@
+// Package markdown draws Markdown documents with gopdf.
+//
+// It understands the common subset of CommonMark: ATX and setext headings,
+// paragraphs, bullet and numbered lists (nested), fenced and indented code
+// blocks, block quotes, thematic breaks, emphasis, code spans, links,
+// autolinks and reference links, plus GitHub-style pipe tables. Images are
+// shown as their alternative text and raw HTML as plain text.
+//
+//	reg := fonts.NewRegistry(&pdf)
+//	y, err := markdown.Render(reg, notes, 50, markdown.Options{})
+//
+// Text is wrapped to the page width and new pages are added as needed. To
+// give the pages a header and footer, draw the document into a flow.Flow
+// with Draw instead.
+package markdown
+
+import (
+	"errors"
+	"fmt"
+	"image/color"
+	"strings"
+
+	"pdf-tutorial/gopdf/flow"
+	"pdf-tutorial/gopdf/fonts"
+	"pdf-tutorial/gopdf/richtext"
+
+	"github.com/signintech/gopdf"
+)
+
+// Options controls how a document is laid out. Zero fields get the default
+// given in their comment.
+type Options struct {
+	// Pages added by Render; Draw uses the pages of its flow.
+	PageSize gopdf.Rect // Size of the document's pages (the size of the current page)
+	Margin   float64    // Page margin on every side (50)
+
+	Family     string      // Body text family (fonts.Sans)
+	CodeFamily string      // Family of code spans and blocks (fonts.Mono)
+	Size       float64     // Body text size (11)
+	LineHeight float64     // Distance between body baselines (1.35 times Size)
+	Color      color.Color // Body text color (black)
+	LinkColor  color.Color // Link color (#0645ad)
+}
+
+func (o *Options) setDefaults() {
+	if o.Margin <= 0 {
+		o.Margin = 50
+	}
+	if o.Family == "" {
+		o.Family = fonts.Sans
+	}
+	if o.CodeFamily == "" {
+		o.CodeFamily = fonts.Mono
+	}
+	if o.Size <= 0 {
+		o.Size = 11
+	}
+	if o.LineHeight <= 0 {
+		o.LineHeight = o.Size * 1.35
+	}
+	if o.Color == nil {
+		o.Color = color.Black
+	}
+	if o.LinkColor == nil {
+		o.LinkColor = color.RGBA{0x06, 0x45, 0xad, 255}
+	}
+}
+
+// bullets are the markers of list items, by nesting depth.
+var bullets = []string{"•", "◦", "▪"}
+
+// headingScale is the size of each heading level relative to body text.
+var headingScale = [7]float64{1, 2, 1.6, 1.3, 1.15, 1, 0.9}
+
+// Colors of the decorations.
+var (
+	quoteColor     = color.RGBA{85, 85, 85, 255}
+	quoteBarColor  = color.RGBA{200, 200, 200, 255}
+	codeBackground = color.RGBA{244, 244, 244, 255}
+	ruleColor      = color.RGBA{200, 200, 200, 255}
+	headerFill     = color.RGBA{235, 235, 235, 255}
+	borderColor    = color.RGBA{160, 160, 160, 255}
+)
+
+// Render draws the Markdown document src, starting at y on the current page
+// (a page is added if the document has none), and returns the y position
+// below the last block. Missing glyphs are reported as by
+// richtext.Missing.
+func Render(reg *fonts.Registry, src string, y float64, opts Options) (float64, error) {
+	opts.setDefaults()
+	m := opts.Margin
+	f := flow.New(reg.PDF(), flow.Options{
+		PageSize: opts.PageSize,
+		Margins:  flow.Margins{Left: m, Top: m, Right: m, Bottom: m},
+		Drawer:   reg,
+	})
+	if reg.PDF().GetNumberOfPages() == 0 {
+		if err := f.NewPage(); err != nil {
+			return y, err
+		}
+	}
+	f.SetY(y)
+	err := Draw(f, reg, src, opts)
+	return f.Y(), err
+}
+
+// Draw draws the Markdown document src at the cursor of f, in the width of
+// its content area, and leaves the cursor below the last block. Errors are
+// reported as by Render.
+func Draw(f *flow.Flow, reg *fonts.Registry, src string, opts Options) error {
+	opts.setDefaults()
+	p := &parser{refs: make(map[string]string)}
+	src = strings.ReplaceAll(src, "\r\n", "\n")
+	blocks := p.parseBlocks(strings.Split(src, "\n"))
+
+	pdf := reg.PDF()
+	r := &renderer{reg: reg, pdf: pdf, f: f, p: p, opts: opts}
+	err := r.blocks(blocks, f.X(), f.Width(), style{color: opts.Color}, false)
+	pdf.SetStrokeColor(0, 0, 0)
+	pdf.SetFillColor(0, 0, 0)
+	if err == nil {
+		err = r.missing.Err()
+	}
+	return err
+}
+
+// style is the text style blocks inherit from their container.
+type style struct {
+	color color.Color
+}
+
+// marker is a list marker waiting to be drawn next to the first line of
+// its item.
+type marker struct {
+	text  string
+	right float64 // Right edge
+}
+
+// renderer draws blocks, top to bottom, adding pages as needed.
+type renderer struct {
+	reg  *fonts.Registry
+	pdf  *gopdf.GoPdf
+	f    *flow.Flow
+	p    *parser
+	opts Options
+
+	bars    []float64 // x positions of the bars of the open block quotes
+	depth   int       // Number of open lists
+	marker  *marker
+	missing richtext.Missing
+}
+
+// blocks draws blocks in a column of width w at x. In a tight list, blocks
+// are not separated by space.
+func (r *renderer) blocks(blocks []*block, x, w float64, st style, tight bool) error {
+	for i, b := range blocks {
+		if i > 0 && !tight {
+			r.space(r.opts.Size * 0.6)
+		}
+		var err error
+		switch b.kind {
+		case paragraphBlock:
+			err = r.text(r.runs(r.p.inline(b.text), st, r.opts.Size, fonts.Regular), x, w, r.opts.Size, r.opts.LineHeight)
+		case headingBlock:
+			err = r.heading(b, x, w, st)
+		case codeBlock:
+			err = r.code(b, x, w)
+		case quoteBlock:
+			err = r.quote(b, x, w)
+		case listBlock:
+			err = r.list(b, x, w, st)
+		case tableBlock:
+			err = r.table(b, x, w, st)
+		case ruleBlock:
+			if _, err = r.ensure(r.opts.Size); err == nil {
+				r.hline(x, r.f.Y()+r.opts.Size/2, x+w, ruleColor, 1)
+				r.advance(r.opts.Size)
+			}
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *renderer) heading(b *block, x, w float64, st style) error {
+	size := r.opts.Size * headingScale[b.level]
+	lineHeight := size * 1.25
+	if r.f.Y() > r.f.Top() {
+		r.space(size * 0.5)
+	}
+	// Keep the heading on the page of the text that follows it.
+	if _, err := r.ensure(lineHeight + 2*r.opts.LineHeight); err != nil {
+		return err
+	}
+	if err := r.text(r.runs(r.p.inline(b.text), st, size, fonts.Bold), x, w, size, lineHeight); err != nil {
+		return err
+	}
+	if b.level <= 2 {
+		r.hline(x, r.f.Y()+1, x+w, ruleColor, 0.75)
+		r.space(3)
+	}
+	return nil
+}
+
+func (r *renderer) code(b *block, x, w float64) error {
+	const pad = 6
+	size := r.opts.Size * 0.9
+	opts := richtext.Options{Width: w - 2*pad, LineHeight: size * 1.3, Family: r.opts.CodeFamily, Size: size, Color: r.opts.Color}
+
+	if err := r.fill(x, w, pad); err != nil {
+		return err
+	}
+	for _, text := range strings.Split(b.text, "\n") {
+		// Keep the indentation, which richtext would drop.
+		text = strings.ReplaceAll(text, "\t", "    ")
+		indent := len(text) - len(strings.TrimLeft(text, " "))
+		lineOpts := opts
+		lineOpts.FirstIndent = float64(indent) * r.charWidth(r.opts.CodeFamily, size)
+		lineOpts.Indent = lineOpts.FirstIndent
+		lines, err := richtext.Layout(r.reg, []richtext.Run{{Text: text}}, x+pad, 0, lineOpts)
+		if err != nil {
+			return err
+		}
+		for _, line := range lines {
+			if err := r.line(line, lineOpts, func(top, h float64) { r.rect(x, top, w, h, codeBackground) }); err != nil {
+				return err
+			}
+		}
+	}
+	return r.fill(x, w, pad)
+}
+
+// fill adds h of space filled with the code background.
+func (r *renderer) fill(x, w, h float64) error {
+	if _, err := r.ensure(h); err != nil {
+		return err
+	}
+	r.rect(x, r.f.Y(), w, h, codeBackground)
+	r.advance(h)
+	return nil
+}
+
+func (r *renderer) quote(b *block, x, w float64) error {
+	r.bars = append(r.bars, x+4)
+	defer func() { r.bars = r.bars[:len(r.bars)-1] }()
+	// Indented from both margins, like a block quote in a book.
+	return r.blocks(b.children, x+18, w-36, style{color: quoteColor}, false)
+}
+
+func (r *renderer) list(b *block, x, w float64, st style) error {
+	const indent = 20
+	bullet := bullets[r.depth%len(bullets)]
+	r.depth++
+	defer func() { r.depth-- }()
+	for i, item := range b.children {
+		if i > 0 && b.loose {
+			r.space(r.opts.Size * 0.6)
+		}
+		text := bullet
+		if b.ordered {
+			text = fmt.Sprintf("%d.", b.start+i)
+		}
+		r.marker = &marker{text: text, right: x + indent - 5}
+		if len(item.children) == 0 {
+			if err := r.text(nil, x+indent, w-indent, r.opts.Size, r.opts.LineHeight); err != nil {
+				return err
+			}
+		}
+		if err := r.blocks(item.children, x+indent, w-indent, st, !b.loose); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *renderer) table(b *block, x, w float64, st style) error {
+	const pad = 4
+	n := len(b.header)
+	cells := func(row []string, style fonts.Style) [][]richtext.Run {
+		out := make([][]richtext.Run, n)
+		for i, c := range row {
+			out[i] = r.runs(r.p.inline(c), st, r.opts.Size, style)
+		}
+		return out
+	}
+	header := cells(b.header, fonts.Bold)
+	rows := make([][][]richtext.Run, len(b.rows))
+	for i, row := range b.rows {
+		rows[i] = cells(row, fonts.Regular)
+	}
+
+	// Columns get their natural width, at least an em inside the padding.
+	// Those wider than that shrink to fit the page, the widest the most; a
+	// table with too many columns for the page runs over its right side.
+	minW := 2*pad + r.opts.Size
+	widths := make([]float64, n)
+	for _, row := range append([][][]richtext.Run{header}, rows...) {
+		for i, runs := range row {
+			lines, err := richtext.Layout(r.reg, runs, 0, 0, richtext.Options{Width: 1e6, Size: r.opts.Size, Family: r.opts.Family})
+			if err != nil {
+				return err
+			}
+			for _, l := range lines {
+				widths[i] = max(widths[i], l.Width+2*pad)
+			}
+		}
+	}
+	total, room := 0.0, 0.0
+	for i := range widths {
+		widths[i] = max(widths[i], minW)
+		total += widths[i]
+		room += widths[i] - minW
+	}
+	if total > w && room > 0 {
+		k := min((total-w)/room, 1)
+		for i := range widths {
+			widths[i] -= (widths[i] - minW) * k
+		}
+	}
+
+	var drawHeader func() error
+	drawRow := func(row [][]richtext.Run, fill color.Color) error {
+		laid := make([][]richtext.Line, n)
+		height := 0.0
+		for i, runs := range row {
+			opts := richtext.Options{Width: widths[i] - 2*pad, LineHeight: r.opts.LineHeight, Size: r.opts.Size, Family: r.opts.Family}
+			lines, err := richtext.Layout(r.reg, runs, 0, 0, opts)
+			if err != nil {
+				return err
+			}
+			laid[i] = lines
+			h := 0.0
+			for _, l := range lines {
+				h += l.Height
+			}
+			height = max(height, h+2*pad)
+		}
+		newPage, err := r.ensure(height)
+		if err != nil {
+			return err
+		}
+		if newPage && fill == nil {
+			// Repeat the header at the top of the new page.
+			if err := drawHeader(); err != nil {
+				return err
+			}
+		}
+		cx := x
+		for i, lines := range laid {
+			if fill != nil {
+				r.rect(cx, r.f.Y(), widths[i], height, fill)
+			}
+			r.pdf.SetStrokeColor(richtext.RGB(borderColor))
+			r.pdf.SetLineWidth(0.5)
+			r.pdf.RectFromUpperLeftWithStyle(cx, r.f.Y(), widths[i], height, "D")
+
+			ly := r.f.Y() + pad
+			for _, l := range lines {
+				shift := 0.0
+				switch b.align[i] {
+				case alignCenter:
+					shift = (widths[i] - 2*pad - l.Width) / 2
+				case alignRight:
+					shift = widths[i] - 2*pad - l.Width
+				}
+				l.Y = ly + l.Height*0.75
+				for f := range l.Fragments {
+					l.Fragments[f].X += cx + pad + shift
+				}
+				if err := r.draw([]richtext.Line{l}, richtext.Options{Color: r.opts.Color}); err != nil {
+					return err
+				}
+				ly += l.Height
+			}
+			cx += widths[i]
+		}
+		r.advance(height)
+		return nil
+	}
+	drawHeader = func() error { return drawRow(header, headerFill) }
+
+	if err := drawHeader(); err != nil {
+		return err
+	}
+	for _, row := range rows {
+		if err := drawRow(row, nil); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// runs converts spans to richtext runs.
+func (r *renderer) runs(spans []span, st style, size float64, base fonts.Style) []richtext.Run {
+	var runs []richtext.Run
+	for _, s := range spans {
+		run := richtext.Run{
+			Text:   s.text,
+			Family: r.opts.Family,
+			Style:  base | s.style,
+			Size:   size,
+			Color:  st.color,
+			Link:   s.link,
+		}
+		if s.code {
+			run.Family = r.opts.CodeFamily
+			run.Size = size * 0.9
+		}
+		if s.link != "" {
+			run.Color = r.opts.LinkColor
+		}
+		if s.image {
+			run.Color = quoteColor
+		}
+		runs = append(runs, run)
+	}
+	return runs
+}
+
+// text wraps runs in a column and draws them line by line.
+func (r *renderer) text(runs []richtext.Run, x, w, size, lineHeight float64) error {
+	opts := richtext.Options{Width: w, LineHeight: lineHeight, Size: size, Family: r.opts.Family, Color: r.opts.Color}
+	lines, err := richtext.Layout(r.reg, runs, x, 0, opts)
+	if err != nil {
+		return err
+	}
+	for _, line := range lines {
+		if err := r.line(line, opts, nil); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// line draws one laid out line below the previous one, on a new page if it
+// does not fit. background, if set, is called first with the line's box.
+func (r *renderer) line(line richtext.Line, opts richtext.Options, background func(top, h float64)) error {
+	if _, err := r.ensure(line.Height); err != nil {
+		return err
+	}
+	top := r.f.Y()
+	if background != nil {
+		background(top, line.Height)
+	}
+	r.drawBars(top, line.Height)
+	line.Y = top + line.Height*0.75
+	if r.marker != nil {
+		m := r.marker
+		r.marker = nil
+		if err := r.reg.SetFont(r.opts.Family, fonts.Regular, r.opts.Size); err != nil {
+			return err
+		}
+		mw, err := r.reg.MeasureTextWidth(m.text)
+		if err != nil {
+			return err
+		}
+		r.pdf.SetTextColor(richtext.RGB(r.opts.Color))
+		r.reg.SetXY(m.right-mw, line.Y)
+		if err := r.missing.Note(r.reg.Text(m.text)); err != nil {
+			return err
+		}
+	}
+	if err := r.draw([]richtext.Line{line}, opts); err != nil {
+		return err
+	}
+	r.f.SetY(top + line.Height)
+	return nil
+}
+
+// draw draws lines with richtext, remembering missing glyph errors.
+func (r *renderer) draw(lines []richtext.Line, opts richtext.Options) error {
+	return r.missing.Note(richtext.Draw(r.reg, lines, opts))
+}
+
+// ensure starts a new page if less than h is left on this one, and reports
+// whether it did. Content too tall for any page is drawn where it is.
+func (r *renderer) ensure(h float64) (bool, error) {
+	newPage, err := r.f.Ensure(h)
+	if errors.Is(err, flow.ErrNoRoom) {
+		err = nil
+	}
+	return newPage, err
+}
+
+// space adds vertical space, continuing the bars of open block quotes.
+func (r *renderer) space(h float64) {
+	if r.f.Y()+h <= r.f.Bottom() {
+		r.drawBars(r.f.Y(), h)
+	}
+	// The space is not needed at the top of the next page.
+	r.f.Space(h)
+}
+
+// advance moves down by h, which ensure has made room for.
+func (r *renderer) advance(h float64) {
+	r.drawBars(r.f.Y(), h)
+	r.f.SetY(r.f.Y() + h)
+}
+
+func (r *renderer) drawBars(top, h float64) {
+	for _, x := range r.bars {
+		r.pdf.SetLineWidth(2)
+		r.pdf.SetStrokeColor(richtext.RGB(quoteBarColor))
+		r.pdf.Line(x, top, x, top+h)
+	}
+}
+
+func (r *renderer) hline(x1, y, x2 float64, c color.Color, width float64) {
+	r.pdf.SetLineWidth(width)
+	r.pdf.SetStrokeColor(richtext.RGB(c))
+	r.pdf.Line(x1, y, x2, y)
+}
+
+func (r *renderer) rect(x, y, w, h float64, c color.Color) {
+	r.pdf.SetFillColor(richtext.RGB(c))
+	r.pdf.RectFromUpperLeftWithStyle(x, y, w, h, "F")
+}
+
+// charWidth returns the width of a space in a family, for indenting code.
+func (r *renderer) charWidth(family string, size float64) float64 {
+	if err := r.reg.SetFont(family, fonts.Regular, size); err != nil {
+		return size * 0.6
+	}
+	w, err := r.reg.MeasureTextWidth(" ")
+	if err != nil {
+		return size * 0.6
+	}
+	return w
+}
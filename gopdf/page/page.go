@@ -0,0 +1,110 @@
+// Package page reports the geometry of the page a gopdf document is drawing
+// on, so helpers can center text or draw headers and footers on any page
+// size instead of assuming A4.
+//
+//	area := page.Content(&pdf)
+//	pdf.Line(area.X, area.Bottom(), area.Right(), area.Bottom())
+//
+// All values are in the document's unit (Config.Unit), like the
+// coordinates given to SetXY.
+package page
+
+import (
+	"reflect"
+
+	"github.com/signintech/gopdf"
+)
+
+// Margins are the distances between the page edges and the content area.
+type Margins struct {
+	Left, Top, Right, Bottom float64
+}
+
+// Box is a rectangle on a page, from its top left corner.
+type Box struct {
+	X, Y, W, H float64
+}
+
+// Right returns the x of the right edge of b.
+func (b Box) Right() float64 { return b.X + b.W }
+
+// Bottom returns the y of the bottom edge of b.
+func (b Box) Bottom() float64 { return b.Y + b.H }
+
+// CenterX returns the x of the middle of b.
+func (b Box) CenterX() float64 { return b.X + b.W/2 }
+
+// Size returns the size of the current page: the size given to
+// AddPageWithOption for it, or else the Config page size. Before the first
+// page is added it is the Config page size.
+//
+// gopdf keeps these sizes to itself, so Size reads them from its internal
+// fields; if a gopdf version changes them, Size returns A4.
+func Size(pdf *gopdf.GoPdf) gopdf.Rect {
+	size := *gopdf.PageSizeA4
+	v := reflect.ValueOf(pdf).Elem()
+	if p := field(v, "curr", "pageSize"); p.IsValid() && p.Kind() == reflect.Pointer && !p.IsNil() {
+		size = rect(p.Elem())
+	} else if c := field(v, "config", "PageSize"); c.IsValid() {
+		size = rect(c)
+	}
+	if size.W <= 0 || size.H <= 0 {
+		size = *gopdf.PageSizeA4
+	}
+	return gopdf.Rect{W: pdf.PointsToUnits(size.W), H: pdf.PointsToUnits(size.H)}
+}
+
+// Landscape reports whether the current page is wider than it is tall.
+func Landscape(pdf *gopdf.GoPdf) bool {
+	size := Size(pdf)
+	return size.W > size.H
+}
+
+// MarginsOf returns the margins set with the document's SetMargins.
+func MarginsOf(pdf *gopdf.GoPdf) Margins {
+	left, top, right, bottom := pdf.Margins()
+	return Margins{left, top, right, bottom}
+}
+
+// Bounds returns the whole current page.
+func Bounds(pdf *gopdf.GoPdf) Box {
+	size := Size(pdf)
+	return Box{0, 0, size.W, size.H}
+}
+
+// Content returns the area of the current page inside the document's
+// margins.
+func Content(pdf *gopdf.GoPdf) Box {
+	return Inside(pdf, MarginsOf(pdf))
+}
+
+// Inside returns the area of the current page inside margins m, e.g. for
+// documents that never call SetMargins.
+func Inside(pdf *gopdf.GoPdf, m Margins) Box {
+	size := Size(pdf)
+	return Box{m.Left, m.Top, size.W - m.Left - m.Right, size.H - m.Top - m.Bottom}
+}
+
+// field returns the nested struct field v.names[0].names[1]..., or the
+// zero Value if there is none.
+func field(v reflect.Value, names ...string) reflect.Value {
+	for _, name := range names {
+		if v.Kind() != reflect.Struct {
+			return reflect.Value{}
+		}
+		v = v.FieldByName(name)
+		if !v.IsValid() {
+			return v
+		}
+	}
+	return v
+}
+
+// rect reads a gopdf.Rect value, which may be an unexported field.
+func rect(v reflect.Value) gopdf.Rect {
+	w, h := field(v, "W"), field(v, "H")
+	if v.Type() != reflect.TypeOf(gopdf.Rect{}) || !w.IsValid() || !h.IsValid() {
+		return gopdf.Rect{}
+	}
+	return gopdf.Rect{W: w.Float(), H: h.Float()}
+}
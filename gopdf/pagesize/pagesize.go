@@ -0,0 +1,322 @@
+// Package pagesize is a catalog of named paper sizes (ISO A, B and C,
+// North American sizes, envelopes and label stock) with a parser for
+// sizes written as text, such as "A5 landscape" or "210x297mm".
+//
+//	pdf.Start(gopdf.Config{PageSize: *pagesize.A4.Rect()})
+//	pdf.AddPageWithOption(gopdf.PageOption{PageSize: pagesize.A4.Landscape().Rect()})
+//
+//	size, err := pagesize.Parse("Letter landscape")
+//
+// A *Size is a flag.Value and an encoding.TextUnmarshaler, so command
+// line tools and config files can take a page size directly:
+//
+//	size := pagesize.A4
+//	flag.Var(&size, "page", `page size, e.g. "A5 landscape" or "6x9in"`)
+//
+// Sizes are in points (1/72 inch), the unit of gopdf.Rect.
+package pagesize
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"pdf-tutorial/gopdf/units"
+
+	"github.com/signintech/gopdf"
+)
+
+// ErrUnknownSize is returned by Parse for text that is neither a size in
+// the catalog nor a width x height.
+var ErrUnknownSize = errors.New("pagesize: unknown page size")
+
+// Orientation is the direction of the long side of a page.
+type Orientation int
+
+const (
+	Portrait  Orientation = iota // taller than wide
+	Landscape                    // wider than tall
+)
+
+func (o Orientation) String() string {
+	if o == Landscape {
+		return "landscape"
+	}
+	return "portrait"
+}
+
+// Size is a page size in points. Name is the catalog name, or "" for a
+// size that is not in the catalog.
+type Size struct {
+	Name string
+	W, H float64
+}
+
+const (
+	mm = float64(units.Mm)
+	in = float64(units.In)
+)
+
+// ISO 216 A series.
+var (
+	A0  = Size{"A0", 841 * mm, 1189 * mm}
+	A1  = Size{"A1", 594 * mm, 841 * mm}
+	A2  = Size{"A2", 420 * mm, 594 * mm}
+	A3  = Size{"A3", 297 * mm, 420 * mm}
+	A4  = Size{"A4", 210 * mm, 297 * mm}
+	A5  = Size{"A5", 148 * mm, 210 * mm}
+	A6  = Size{"A6", 105 * mm, 148 * mm}
+	A7  = Size{"A7", 74 * mm, 105 * mm}
+	A8  = Size{"A8", 52 * mm, 74 * mm}
+	A9  = Size{"A9", 37 * mm, 52 * mm}
+	A10 = Size{"A10", 26 * mm, 37 * mm}
+)
+
+// ISO 216 B series.
+var (
+	B0  = Size{"B0", 1000 * mm, 1414 * mm}
+	B1  = Size{"B1", 707 * mm, 1000 * mm}
+	B2  = Size{"B2", 500 * mm, 707 * mm}
+	B3  = Size{"B3", 353 * mm, 500 * mm}
+	B4  = Size{"B4", 250 * mm, 353 * mm}
+	B5  = Size{"B5", 176 * mm, 250 * mm}
+	B6  = Size{"B6", 125 * mm, 176 * mm}
+	B7  = Size{"B7", 88 * mm, 125 * mm}
+	B8  = Size{"B8", 62 * mm, 88 * mm}
+	B9  = Size{"B9", 44 * mm, 62 * mm}
+	B10 = Size{"B10", 31 * mm, 44 * mm}
+)
+
+// North American sizes. Ledger is Tabloid turned sideways.
+var (
+	Letter    = Size{"Letter", 8.5 * in, 11 * in}
+	Legal     = Size{"Legal", 8.5 * in, 14 * in}
+	Tabloid   = Size{"Tabloid", 11 * in, 17 * in}
+	Ledger    = Size{"Ledger", 17 * in, 11 * in}
+	Executive = Size{"Executive", 7.25 * in, 10.5 * in}
+	Statement = Size{"Statement", 5.5 * in, 8.5 * in}
+)
+
+// Envelopes: ISO 269 C series and DL, and the US #10 and Monarch.
+var (
+	C3         = Size{"C3", 324 * mm, 458 * mm}
+	C4         = Size{"C4", 229 * mm, 324 * mm}
+	C5         = Size{"C5", 162 * mm, 229 * mm}
+	C6         = Size{"C6", 114 * mm, 162 * mm}
+	DL         = Size{"DL", 110 * mm, 220 * mm}
+	Envelope10 = Size{"Envelope10", 4.125 * in, 9.5 * in}
+	Monarch    = Size{"Monarch", 3.875 * in, 7.5 * in}
+)
+
+// Label stock for label printers: the 4x6in and 100x150mm shipping
+// labels and the 62x29mm address label.
+var (
+	Label4x6     = Size{"Label4x6", 4 * in, 6 * in}
+	Label100x150 = Size{"Label100x150", 100 * mm, 150 * mm}
+	Label62x29   = Size{"Label62x29", 62 * mm, 29 * mm}
+)
+
+// Sizes lists the catalog, in the order of the declarations above.
+var Sizes = []Size{
+	A0, A1, A2, A3, A4, A5, A6, A7, A8, A9, A10,
+	B0, B1, B2, B3, B4, B5, B6, B7, B8, B9, B10,
+	Letter, Legal, Tabloid, Ledger, Executive, Statement,
+	C3, C4, C5, C6, DL, Envelope10, Monarch,
+	Label4x6, Label100x150, Label62x29,
+}
+
+// aliases are other names Lookup accepts, in the normalized form of key.
+var aliases = map[string]string{
+	"usletter":   "Letter",
+	"uslegal":    "Legal",
+	"halfletter": "Statement",
+	"no10":       "Envelope10",
+	"com10":      "Envelope10",
+	"#10":        "Envelope10",
+}
+
+// Lookup returns the catalog size called name. Case, spaces, "-" and "_"
+// are ignored, so "a4", "US Letter" and "label-4x6" are all found.
+func Lookup(name string) (Size, bool) {
+	k := key(name)
+	if alias, ok := aliases[k]; ok {
+		k = key(alias)
+	}
+	for _, s := range Sizes {
+		if key(s.Name) == k {
+			return s, true
+		}
+	}
+	return Size{}, false
+}
+
+// dimensions matches "210x297mm", "8.5 x 11 in", "210mm x 297mm" and
+// "595x842" (points).
+var dimensions = regexp.MustCompile(`^(\d+(?:\.\d+)?)\s*([a-z]*)\s*[x×*]\s*(\d+(?:\.\d+)?)\s*([a-z]*)$`)
+
+// orientations are the words Parse accepts for an orientation.
+var orientations = map[string]Orientation{
+	"portrait": Portrait, "landscape": Landscape,
+}
+
+// Parse reads a page size: a catalog name or a width x height with a unit
+// of mm, cm, in, pt or px (points if there is none, see units.Parse),
+// followed or preceded by an optional "portrait" or "landscape":
+//
+//	A4
+//	A5 landscape
+//	us letter, landscape
+//	210x297mm
+//	landscape 8.5x11in
+//
+// Without an orientation, catalog sizes keep their own and dimensions are
+// taken as given.
+func Parse(text string) (Size, error) {
+	fields := strings.Fields(strings.ToLower(strings.ReplaceAll(text, ",", " ")))
+	orientation := -1
+	for _, i := range []int{len(fields) - 1, 0} {
+		if i < 0 || i >= len(fields) {
+			continue
+		}
+		if o, ok := orientations[fields[i]]; ok {
+			orientation = int(o)
+			fields = append(fields[:i], fields[i+1:]...)
+			break
+		}
+	}
+	if len(fields) == 0 {
+		return Size{}, fmt.Errorf("%w: %q", ErrUnknownSize, text)
+	}
+
+	size, ok := Lookup(strings.Join(fields, " "))
+	if !ok {
+		var err error
+		if size, err = parseDimensions(strings.Join(fields, " ")); err != nil {
+			return Size{}, fmt.Errorf("%w: %q", ErrUnknownSize, text)
+		}
+	}
+	if orientation >= 0 {
+		size = size.Orient(Orientation(orientation))
+	}
+	return size, nil
+}
+
+// parseDimensions reads a width x height. A single unit applies to both.
+func parseDimensions(text string) (Size, error) {
+	m := dimensions.FindStringSubmatch(text)
+	if m == nil {
+		return Size{}, ErrUnknownSize
+	}
+	wUnit, hUnit := m[2], m[4]
+	if wUnit == "" {
+		wUnit = hUnit
+	}
+	if hUnit == "" {
+		hUnit = wUnit
+	}
+	if wUnit == "" {
+		wUnit, hUnit = "pt", "pt"
+	}
+	wScale, ok1 := units.Unit(wUnit)
+	hScale, ok2 := units.Unit(hUnit)
+	if !ok1 || !ok2 {
+		return Size{}, ErrUnknownSize
+	}
+	w, _ := strconv.ParseFloat(m[1], 64)
+	h, _ := strconv.ParseFloat(m[3], 64)
+	if w <= 0 || h <= 0 {
+		return Size{}, ErrUnknownSize
+	}
+	return Size{W: w * float64(wScale), H: h * float64(hScale)}, nil
+}
+
+// MustParse is like Parse but panics on an error. It is meant for sizes
+// written in the program, not for user input.
+func MustParse(text string) Size {
+	s, err := Parse(text)
+	if err != nil {
+		panic(err)
+	}
+	return s
+}
+
+// Orientation reports whether s is wider than it is tall.
+func (s Size) Orientation() Orientation {
+	if s.W > s.H {
+		return Landscape
+	}
+	return Portrait
+}
+
+// Orient returns s turned to orientation o.
+func (s Size) Orient(o Orientation) Size {
+	if s.Orientation() != o {
+		s.W, s.H = s.H, s.W
+	}
+	return s
+}
+
+// Portrait returns s with its long side vertical.
+func (s Size) Portrait() Size { return s.Orient(Portrait) }
+
+// Landscape returns s with its long side horizontal.
+func (s Size) Landscape() Size { return s.Orient(Landscape) }
+
+// Rect returns s as a gopdf.Rect, for Config.PageSize (*s.Rect()) and
+// PageOption.PageSize.
+func (s Size) Rect() *gopdf.Rect {
+	return &gopdf.Rect{W: s.W, H: s.H}
+}
+
+// String returns s in a form Parse reads back: the catalog name, with the
+// orientation if s is turned, or else the width x height in points.
+func (s Size) String() string {
+	if base, ok := Lookup(s.Name); ok {
+		switch {
+		case base.W == s.W && base.H == s.H:
+			return base.Name
+		case base.W == s.H && base.H == s.W:
+			return base.Name + " " + s.Orientation().String()
+		}
+	}
+	return fmt.Sprintf("%gx%gpt", round(s.W), round(s.H))
+}
+
+// Set parses text into s, for flag.Var.
+func (s *Size) Set(text string) error {
+	size, err := Parse(text)
+	if err != nil {
+		return err
+	}
+	*s = size
+	return nil
+}
+
+// UnmarshalText parses text into s, for encoding/json and other decoders.
+func (s *Size) UnmarshalText(text []byte) error {
+	return s.Set(string(text))
+}
+
+// MarshalText returns s.String().
+func (s Size) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+// key normalizes a size name for Lookup.
+func key(name string) string {
+	return strings.Map(func(r rune) rune {
+		switch r {
+		case ' ', '\t', '-', '_':
+			return -1
+		}
+		return r
+	}, strings.ToLower(name))
+}
+
+// round rounds a size in points to 1/100 point.
+func round(v float64) float64 {
+	return math.Round(v*100) / 100
+}
@@ -2,9 +2,15 @@ package main
 
 import (
 	"fmt"
-	_ "log"
+	"log"
 	_ "strings"
 
+	"pdf-tutorial/gopdf/flow"
+	"pdf-tutorial/gopdf/fonts"
+	"pdf-tutorial/gopdf/page"
+	"pdf-tutorial/gopdf/pagesize"
+	"pdf-tutorial/gopdf/textlayout"
+
 	"github.com/signintech/gopdf"
 )
 
@@ -43,16 +49,13 @@ func createHelloWorldPDF() {
 	// Add a page
 	pdf.AddPage()
 
-	// Add font with proper fallback
+	// Add font: fonts.Sans is Liberation Sans, which is embedded in the
+	// program (no font files needed) and has the same metrics as Arial
 	fontName := "arial"
-	err := pdf.AddTTFFont(fontName, "./fonts/arial.ttf")
+	err := fonts.Register(&pdf, fontName, fonts.Sans, "Regular")
 	if err != nil {
-		// Try system font on Windows
-		err = pdf.AddTTFFont(fontName, "C:/Windows/Fonts/arial.ttf")
-		if err != nil {
-			// Use built-in PostScript font as final fallback
-			fontName = "Times-Roman"
-		}
+		log.Println(err)
+		return
 	}
 
 	// Set font
@@ -73,10 +76,11 @@ func createPageSetupPDF() {
 
 	pdf := gopdf.GoPdf{}
 
-	// Different page configurations
+	// Different page configurations. The pagesize package has the usual
+	// paper sizes (A and B series, Letter, Legal, envelopes, labels...)
 	config := gopdf.Config{
-		PageSize: *gopdf.PageSizeA4, // A4 size: 595.28 x 841.89 points
-		Unit:     gopdf.UnitPT,      // Points unit
+		PageSize: *pagesize.A4.Rect(), // A4 size: 595.28 x 841.89 points
+		Unit:     gopdf.UnitPT,        // Points unit
 	}
 
 	pdf.Start(config)
@@ -89,16 +93,9 @@ func createPageSetupPDF() {
 
 	// Add font (with proper error handling)
 	fontName := "arial"
-	err := pdf.AddTTFFont(fontName, "./fonts/arial.ttf")
-	if err != nil {
-		// Fallback: try to add a built-in or system font
-		fmt.Printf("Custom font not found, trying built-in font\n")
-		err = pdf.AddTTFFont(fontName, "C:/Windows/Fonts/arial.ttf") // Windows system font path
-		if err != nil {
-			// Final fallback - use a basic font name that gopdf might have built-in
-			fontName = "Times-Roman" // Basic PostScript font
-			fmt.Printf("Using fallback font: %s\n", fontName)
-		}
+	if err := setupFont(&pdf, fontName, fonts.Sans, "Regular"); err != nil {
+		log.Println(err)
+		return
 	}
 
 	pdf.SetFont(fontName, "", 16)
@@ -108,26 +105,36 @@ func createPageSetupPDF() {
 	pdf.Text("Page Configuration Demo")
 
 	pdf.SetXY(50, 80)
-	pdf.Text(fmt.Sprintf("Page Size: A4 (%.2f x %.2f points)",
-		gopdf.PageSizeA4.W, gopdf.PageSizeA4.H))
+	pdf.Text(fmt.Sprintf("Page Size: %s (%.2f x %.2f points)",
+		pagesize.A4, pagesize.A4.W, pagesize.A4.H))
 
 	pdf.SetXY(50, 110)
 	pdf.Text("Margins: 50pt on all sides")
 
 	// Page 2: Landscape orientation
 	pdf.AddPageWithOption(gopdf.PageOption{
-		PageSize: &gopdf.Rect{W: gopdf.PageSizeA4.H, H: gopdf.PageSizeA4.W}, // Swap W and H for landscape
+		PageSize: pagesize.A4.Landscape().Rect(),
 	})
 
 	pdf.SetXY(50, 50)
 	pdf.Text("Landscape Page Example")
 
+	// page.Size reports the size of the page being drawn on, so the
+	// numbers below are those of this landscape page
+	size := page.Size(&pdf)
 	pdf.SetXY(50, 80)
-	pdf.Text("This page is in landscape orientation")
+	pdf.Text(fmt.Sprintf("This page is in landscape orientation (%.2f x %.2f points)",
+		size.W, size.H))
 
-	// Page 3: Custom page size
-	customSize := &gopdf.Rect{W: 400, H: 600} // Custom dimensions
-	pdf.AddPageWithOption(gopdf.PageOption{PageSize: customSize})
+	// Page 3: Custom page size. Parse reads sizes written as text, e.g.
+	// "A5 landscape", "Letter" or a width x height in mm, cm, in or pt,
+	// which is handy for sizes coming from a command line or config file.
+	customSize, err := pagesize.Parse("6x9in") // A common book size
+	if err != nil {
+		log.Println(err)
+		return
+	}
+	pdf.AddPageWithOption(gopdf.PageOption{PageSize: customSize.Rect()})
 
 	pdf.SetXY(50, 50)
 	pdf.Text("Custom Page Size")
@@ -135,28 +142,25 @@ func createPageSetupPDF() {
 	pdf.SetXY(50, 80)
 	pdf.Text(fmt.Sprintf("Size: %.0f x %.0f points", customSize.W, customSize.H))
 
+	// Center a line on the custom page: its content area is the page
+	// minus the 50pt margins set above
+	note := "Centered on this page"
+	noteWidth, _ := pdf.MeasureTextWidth(note)
+	area := page.Content(&pdf)
+	pdf.SetXY(area.CenterX()-noteWidth/2, 110)
+	pdf.Text(note)
+
 	pdf.WritePdf(goPdfFolder + pdfCreation + "page-setup-example.pdf")
 	fmt.Println("Created: page-setup-example.pdf to ", goPdfFolder+pdfCreation, "folder")
 }
 
-// Helper function to safely set up font
-func setupFont(pdf *gopdf.GoPdf, fontName string) string {
-	// Try local fonts directory first
-	err := pdf.AddTTFFont(fontName, "./fonts/"+fontName+".ttf")
-	if err != nil {
-		// Try Windows system fonts
-		err = pdf.AddTTFFont(fontName, "C:/Windows/Fonts/"+fontName+".ttf")
-		if err != nil {
-			// Try alternative Windows system fonts
-			err = pdf.AddTTFFont(fontName, "C:/Windows/Fonts/calibri.ttf")
-			if err != nil {
-				// Final fallback - return a basic font name
-				fmt.Printf("All font attempts failed, using Times-Roman\n")
-				return "Times-Roman"
-			}
-		}
-	}
-	return fontName
+// Helper function to safely set up font.
+// It registers the font matching family and style (e.g. "Arial", "Bold")
+// under fontName and returns an error when no such font is installed.
+// The embedded families fonts.Sans, fonts.Serif and fonts.Mono always work
+// and give the same output on every machine.
+func setupFont(pdf *gopdf.GoPdf, fontName, family, style string) error {
+	return fonts.Register(pdf, fontName, family, style)
 }
 
 // Example 3: Basic text placement and formatting
@@ -168,11 +172,16 @@ func createTextFormattingPDF() {
 	pdf.AddPage()
 
 	// Setup fonts safely
-	regularFont := setupFont(&pdf, "arial")
-	boldFont := setupFont(&pdf, "arial-bold")
+	regularFont := "arial"
+	if err := setupFont(&pdf, regularFont, fonts.Sans, "Regular"); err != nil {
+		log.Println(err)
+		return
+	}
 
 	// If bold font failed, use regular font
-	if boldFont == "Times-Roman" {
+	boldFont := "arial-bold"
+	if err := setupFont(&pdf, boldFont, fonts.Sans, "Bold"); err != nil {
+		log.Println(err)
 		boldFont = regularFont
 	}
 
@@ -202,7 +211,7 @@ func createTextFormattingPDF() {
 	// Center aligned text (manual calculation)
 	text := "Center aligned text"
 	textWidth, _ := pdf.MeasureTextWidth(text)
-	pageWidth := gopdf.PageSizeA4.W
+	pageWidth := page.Size(&pdf).W
 	centerX := (pageWidth - textWidth) / 2
 	pdf.SetXY(centerX, yPos+50)
 	pdf.Text(text)
@@ -255,100 +264,90 @@ func createMultiPagePDF() {
 	pdf := gopdf.GoPdf{}
 	pdf.Start(gopdf.Config{PageSize: *gopdf.PageSizeA4})
 
-	// Setup font safely
-	fontName := setupFont(&pdf, "arial")
-
-	// Create 5 pages with different content
-	for pageNum := 1; pageNum <= 5; pageNum++ {
-		pdf.AddPage()
-
-		// Page header
-		pdf.SetFont(fontName, "", 16)
-		pdf.SetXY(50, 50)
-		pdf.Text(fmt.Sprintf("Page %d of 5", pageNum))
-
-		// Page content
-		pdf.SetFont(fontName, "", 12)
-		pdf.SetXY(50, 100)
-		pdf.Text(fmt.Sprintf("This is the content for page number %d.", pageNum))
-
-		// Add some dynamic content based on page number
-		yPos := 130.0
-		for i := 1; i <= pageNum*3; i++ {
-			pdf.SetXY(50, yPos)
-			pdf.Text(fmt.Sprintf("Content line %d on page %d", i, pageNum))
-			yPos += 20
-		}
+	// Fonts come from a registry, so the flow can give the content its
+	// font back after drawing a header or footer in another one
+	reg := fonts.NewRegistry(&pdf)
 
-		// Page footer
-		pdf.SetFont(fontName, "", 10)
-		pdf.SetXY(50, 800) // Near bottom of A4 page
-		pdf.Text(fmt.Sprintf("Footer - Page %d", pageNum))
+	// A flow tracks the y position for us: every line reserves its height,
+	// and when a line would run off the page, the flow adds a new page and
+	// draws its header and footer before drawing the line there.
+	f := flow.New(&pdf, flow.Options{
+		Margins: flow.Margins{Left: 50, Top: 30, Right: 50, Bottom: 30},
+		Drawer:  reg,
+	})
+
+	// Page header, drawn on every page the flow adds. The content starts
+	// below the 30pt band it gets.
+	f.SetHeader(30, func(f *flow.Flow, b flow.Band) error {
+		reg.SetFont(fonts.Sans, fonts.Regular, 10)
+		reg.SetXY(b.X, b.Y+12)
+		return reg.Text("Multiple Pages Example")
+	})
 
-		// Page break demonstration
-		if pageNum < 5 {
-			pdf.SetXY(50, 750)
-			pdf.Text("(Page break after this content)")
+	// Page footer. The total number of pages is not known yet when the
+	// first footers are drawn, so the text holds the flow.TotalPages
+	// placeholder, which is filled in when the document is written.
+	f.SetFooter(30, func(f *flow.Flow, b flow.Band) error {
+		reg.SetFont(fonts.Sans, fonts.Regular, 10)
+		text := fmt.Sprintf("Page %d of %s", f.Page(), flow.TotalPages)
+		return f.PageText(text, b.X, b.Y+b.H-8, b.W, gopdf.Right)
+	})
+
+	// Five sections with more and more content. Nothing here checks how
+	// much room is left: long sections simply continue on the next page.
+	for section := 1; section <= 5; section++ {
+		reg.SetFont(fonts.Sans, fonts.Regular, 16)
+		if err := f.Text(fmt.Sprintf("Section %d", section), 30); err != nil {
+			log.Println(err)
+			return
+		}
+
+		reg.SetFont(fonts.Sans, fonts.Regular, 12)
+		f.Text(fmt.Sprintf("This is the content for section number %d.", section), 30)
+		for i := 1; i <= section*8; i++ {
+			f.Text(fmt.Sprintf("Content line %d of section %d", i, section), 20)
 		}
+		f.Space(20)
 	}
 
 	// Add a final summary page
-	pdf.AddPage()
-	pdf.SetFont(fontName, "", 14)
-	pdf.SetXY(50, 50)
-	pdf.Text("Summary Page")
+	f.NewPage()
+	reg.SetFont(fonts.Sans, fonts.Regular, 14)
+	f.Text("Summary Page", 30)
 
-	pdf.SetFont(fontName, "", 12)
-	pdf.SetXY(50, 80)
-	pdf.Text("This document demonstrates:")
+	reg.SetFont(fonts.Sans, fonts.Regular, 12)
+	f.Text("This document demonstrates:", 30)
 
 	summaryItems := []string{
 		"• Multiple page creation",
 		"• Dynamic content generation",
 		"• Page numbering",
 		"• Headers and footers",
-		"• Content flow across pages",
+		"• Content flow across pages (automatic page breaks)",
 	}
 
-	yPos := 110.0
 	for _, item := range summaryItems {
-		pdf.SetXY(50, yPos)
-		pdf.Text(item)
-		yPos += 25
+		f.Text(item, 25)
 	}
 
-	pdf.WritePdf(goPdfFolder + "multi-page-example.pdf")
+	// Write through the flow, which fills in the page totals first
+	if err := f.WritePdf(goPdfFolder + "multi-page-example.pdf"); err != nil {
+		log.Println(err)
+		return
+	}
 	fmt.Println("Created: multi-page-example.pdf to", goPdfFolder, "folder")
 }
 
 // Helper function to demonstrate advanced text wrapping (bonus)
+// textlayout.Paragraph breaks text at spaces and newlines, splits words
+// that are wider than maxWidth, and returns the y below the last line.
 func wrapText(pdf *gopdf.GoPdf, text string, x, y, maxWidth float64) float64 {
-	words := []string{"This", "is", "a", "long", "text", "that", "needs", "to", "be", "wrapped", "across", "multiple", "lines", "to", "demonstrate", "text", "wrapping", "functionality"}
-
-	currentY := y
-	currentLine := ""
-
-	for _, word := range words {
-		testLine := currentLine + word + " "
-		textWidth, _ := pdf.MeasureTextWidth(testLine)
-
-		if textWidth > maxWidth && currentLine != "" {
-			// Print current line and start new one
-			pdf.SetXY(x, currentY)
-			pdf.Text(currentLine)
-			currentY += 15 // Line height
-			currentLine = word + " "
-		} else {
-			currentLine = testLine
-		}
-	}
-
-	// Print last line
-	if currentLine != "" {
-		pdf.SetXY(x, currentY)
-		pdf.Text(currentLine)
-		currentY += 15
+	nextY, err := textlayout.Paragraph(pdf, text, x, y, textlayout.Options{
+		Width:      maxWidth,
+		LineHeight: 15,
+	})
+	if err != nil {
+		log.Println(err)
 	}
-
-	return currentY
+	return nextY
 }
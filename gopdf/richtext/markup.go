@@ -0,0 +1,170 @@
+package richtext
+
+import (
+	"errors"
+	"fmt"
+	"image/color"
+	"strconv"
+	"strings"
+
+	"pdf-tutorial/gopdf/fonts"
+)
+
+// ErrSyntax is wrapped by the errors Parse returns.
+var ErrSyntax = errors.New("richtext: invalid markup")
+
+// Parse converts markup into runs. The markup is plain text with:
+//
+//	**bold**          bold text
+//	*italic*          italic text (both can be combined and nested)
+//	{color:#c00}...{} text in a color: #rgb, #rrggbb or a name such as red
+//	{size:14}...{}    text in another size, in points
+//	{font:mono}...{}  text in another registry family
+//	{link:URL}...{}   text that links to URL, e.g. {link:https://go.dev}Go{}
+//	\*, \{, \\        a literal *, { or \
+//
+// Settings can be combined, as in {color:navy; size:14}, and {} ends the
+// innermost one. Fields a run does not set are left zero, so they take the
+// Options value when drawn.
+func Parse(markup string) ([]Run, error) {
+	var (
+		runs  []Run
+		text  strings.Builder
+		style fonts.Style
+		spans = []Run{{}} // Settings of the open {...} spans; spans[0] is the default
+	)
+	flush := func() {
+		if text.Len() == 0 {
+			return
+		}
+		run := spans[len(spans)-1]
+		run.Text = text.String()
+		run.Style = style
+		runs = append(runs, run)
+		text.Reset()
+	}
+
+	for i := 0; i < len(markup); i++ {
+		switch c := markup[i]; c {
+		case '\\':
+			if i+1 < len(markup) {
+				i++
+			}
+			text.WriteByte(markup[i])
+		case '*':
+			flush()
+			if strings.HasPrefix(markup[i:], "**") {
+				style ^= fonts.Bold
+				i++
+			} else {
+				style ^= fonts.Italic
+			}
+		case '{':
+			end := strings.IndexByte(markup[i:], '}')
+			if end < 0 {
+				return nil, fmt.Errorf("%w: unclosed { at offset %d", ErrSyntax, i)
+			}
+			flush()
+			settings := markup[i+1 : i+end]
+			if strings.TrimSpace(settings) == "" {
+				if len(spans) == 1 {
+					return nil, fmt.Errorf("%w: {} at offset %d closes nothing", ErrSyntax, i)
+				}
+				spans = spans[:len(spans)-1]
+			} else {
+				span, err := parseSettings(spans[len(spans)-1], settings)
+				if err != nil {
+					return nil, fmt.Errorf("%w at offset %d", err, i)
+				}
+				spans = append(spans, span)
+			}
+			i += end
+		default:
+			text.WriteByte(c)
+		}
+	}
+	flush()
+
+	switch {
+	case style&fonts.Bold != 0:
+		return nil, fmt.Errorf("%w: unclosed **", ErrSyntax)
+	case style&fonts.Italic != 0:
+		return nil, fmt.Errorf("%w: unclosed *", ErrSyntax)
+	case len(spans) > 1:
+		return nil, fmt.Errorf("%w: unclosed {...}", ErrSyntax)
+	}
+	return runs, nil
+}
+
+// parseSettings applies settings such as "color:#c00; size:14" to run.
+func parseSettings(run Run, settings string) (Run, error) {
+	for _, setting := range strings.Split(settings, ";") {
+		key, value, ok := strings.Cut(setting, ":")
+		key, value = strings.TrimSpace(key), strings.TrimSpace(value)
+		if !ok || value == "" {
+			return run, fmt.Errorf("%w: %q is not key:value", ErrSyntax, setting)
+		}
+		switch key {
+		case "color":
+			c, err := ParseColor(value)
+			if err != nil {
+				return run, err
+			}
+			run.Color = c
+		case "size":
+			size, err := strconv.ParseFloat(strings.TrimSuffix(value, "pt"), 64)
+			if err != nil || size <= 0 {
+				return run, fmt.Errorf("%w: invalid size %q", ErrSyntax, value)
+			}
+			run.Size = size
+		case "font":
+			run.Family = value
+		case "link":
+			run.Link = value
+		default:
+			return run, fmt.Errorf("%w: unknown setting %q", ErrSyntax, key)
+		}
+	}
+	return run, nil
+}
+
+// colorNames are the color names ParseColor accepts.
+var colorNames = map[string]color.RGBA{
+	"black":  {0, 0, 0, 255},
+	"white":  {255, 255, 255, 255},
+	"gray":   {128, 128, 128, 255},
+	"grey":   {128, 128, 128, 255},
+	"red":    {255, 0, 0, 255},
+	"maroon": {128, 0, 0, 255},
+	"orange": {255, 165, 0, 255},
+	"yellow": {255, 255, 0, 255},
+	"green":  {0, 128, 0, 255},
+	"teal":   {0, 128, 128, 255},
+	"blue":   {0, 0, 255, 255},
+	"navy":   {0, 0, 128, 255},
+	"purple": {128, 0, 128, 255},
+}
+
+// ParseColor parses a CSS-style color: "#rgb", "#rrggbb" or one of the
+// basic names (black, white, gray, red, maroon, orange, yellow, green,
+// teal, blue, navy, purple).
+func ParseColor(s string) (color.RGBA, error) {
+	if c, ok := colorNames[strings.ToLower(s)]; ok {
+		return c, nil
+	}
+	hex := strings.TrimPrefix(s, "#")
+	if len(hex) == 3 {
+		hex = string([]byte{hex[0], hex[0], hex[1], hex[1], hex[2], hex[2]})
+	}
+	v, err := strconv.ParseUint(hex, 16, 32)
+	if !strings.HasPrefix(s, "#") || len(hex) != 6 || err != nil {
+		return color.RGBA{}, fmt.Errorf("%w: invalid color %q", ErrSyntax, s)
+	}
+	return color.RGBA{uint8(v >> 16), uint8(v >> 8), uint8(v), 255}, nil
+}
+
+func sameColor(a, b color.Color) bool {
+	ar, ag, ab := RGB(a)
+	br, bg, bb := RGB(b)
+	return ar == br && ag == bg && ab == bb
+}
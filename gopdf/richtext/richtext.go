@@ -0,0 +1,475 @@
+// Package richtext wraps and draws text that mixes fonts, styles, sizes and
+// colors, such as a paragraph with a few bold words in it.
+//
+// Text is given either as styled runs or as a small markup (see Parse):
+//
+//	reg := fonts.NewRegistry(&pdf)
+//	y, err := richtext.Markup(reg, "Totals are **final** unless marked "+
+//		"{color:#c00}*provisional*{}.", 50, 100, richtext.Options{Width: 495})
+//
+// All fragments of a line share one baseline; a line that holds larger text
+// is moved down to make room for it.
+package richtext
+
+import (
+	"errors"
+	"image/color"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"pdf-tutorial/gopdf/fonts"
+)
+
+// ErrInvalidOptions is returned for a non-positive Width.
+var ErrInvalidOptions = errors.New("richtext: width must be positive")
+
+// Run is a piece of text with one style. Zero fields take their value from
+// Options.
+type Run struct {
+	Text   string
+	Family string      // Registry family, e.g. fonts.Serif
+	Style  fonts.Style // Regular, Bold, Italic or BoldItalic
+	Size   float64     // Font size in points
+	Color  color.Color
+	Link   string // URL the text links to, if any
+}
+
+// sameStyle reports whether r and o are drawn with the same font and color
+// and link to the same place.
+func (r Run) sameStyle(o Run) bool {
+	return r.Family == o.Family && r.Style == o.Style && r.Size == o.Size &&
+		sameColor(r.Color, o.Color) && r.Link == o.Link
+}
+
+// Options controls how runs are laid out.
+type Options struct {
+	Width float64 // Maximum line width, including indentation
+
+	// LineHeight is the distance between two baselines of text in the
+	// default Size; lines with larger text get proportionally more room.
+	// Zero means 1.25 times Size.
+	LineHeight float64
+
+	// FirstIndent is the left indent of the first line of every paragraph
+	// (the text after each newline); Indent is the left indent of the
+	// other lines.
+	FirstIndent float64
+	Indent      float64
+
+	// Defaults for runs that leave them unset: fonts.Sans, 12pt and black.
+	Family string
+	Size   float64
+	Color  color.Color
+}
+
+func (o *Options) setDefaults() {
+	if o.Family == "" {
+		o.Family = fonts.Sans
+	}
+	if o.Size <= 0 {
+		o.Size = 12
+	}
+	if o.Color == nil {
+		o.Color = color.Black
+	}
+	if o.LineHeight <= 0 {
+		o.LineHeight = o.Size * 1.25
+	}
+}
+
+// Line is one laid out line.
+type Line struct {
+	Fragments []Fragment
+	X         float64 // Left edge, including indentation
+	Y         float64 // Baseline
+	Width     float64
+	Height    float64 // Distance from the previous baseline
+}
+
+// Fragment is a piece of a line drawn with one style. Its Run has every
+// field set.
+type Fragment struct {
+	Run
+	X     float64 // Left edge, relative to the page
+	Width float64
+}
+
+// Layout breaks runs into lines no wider than opts.Width, with the first
+// baseline at y if the first line holds text of the default size. Lines
+// break at spaces and newlines; words may change style in the middle
+// ("**bold**ness") and are kept together. A word wider than a whole line is
+// split between characters.
+func Layout(reg *fonts.Registry, runs []Run, x, y float64, opts Options) ([]Line, error) {
+	if opts.Width <= 0 {
+		return nil, ErrInvalidOptions
+	}
+	opts.setDefaults()
+
+	face, size := reg.Current()
+	defer func() {
+		if face.Family != "" {
+			reg.SetFont(face.Family, face.Style, size)
+		}
+	}()
+
+	l := &layouter{reg: reg, opts: opts, x: x, y: y - opts.LineHeight}
+	for _, run := range runs {
+		if err := l.run(l.resolve(run)); err != nil {
+			return nil, err
+		}
+	}
+	if err := l.endWord(); err != nil {
+		return nil, err
+	}
+	l.breakLine()
+	return l.lines, nil
+}
+
+// Draw draws lines laid out by Layout, and makes fragments with a Link
+// clickable. Text is drawn even if some runes have no glyph in any font; a
+// *fonts.MissingGlyphsError is then returned. The font and the text color
+// set with reg.SetTextColor are restored afterwards.
+func Draw(reg *fonts.Registry, lines []Line, opts Options) error {
+	opts.setDefaults()
+	pdf := reg.PDF()
+	face, size := reg.Current()
+	cr, cg, cb := reg.TextColor()
+
+	var missing Missing
+	for _, line := range lines {
+		for _, frag := range merge(line.Fragments) {
+			if strings.TrimSpace(frag.Text) == "" {
+				continue
+			}
+			if err := reg.SetFont(frag.Family, frag.Style, frag.Size); err != nil {
+				return err
+			}
+			c := frag.Color
+			if c == nil {
+				c = opts.Color
+			}
+			pdf.SetTextColor(RGB(c))
+			reg.SetXY(frag.X, line.Y)
+			if err := missing.Note(reg.Text(frag.Text)); err != nil {
+				return err
+			}
+			if frag.Link != "" {
+				// The link area covers the line from ascender to descender.
+				pdf.AddExternalLink(frag.Link, frag.X, line.Y-frag.Size*0.8, frag.Width, frag.Size)
+			}
+		}
+	}
+
+	pdf.SetTextColor(cr, cg, cb)
+	if face.Family != "" {
+		if err := reg.SetFont(face.Family, face.Style, size); err != nil {
+			return err
+		}
+	}
+	return missing.Err()
+}
+
+// Missing collects the missing glyphs errors of text drawn in pieces, so
+// that a drawing goes on to the end when some runes have no glyph in any
+// font, which then show as spaces, and reports them once it is done.
+type Missing struct {
+	runes []rune
+	seen  map[rune]bool
+}
+
+// Note records the runes of err if it is a *fonts.MissingGlyphsError and
+// returns nil; it returns any other error.
+func (m *Missing) Note(err error) error {
+	var mg *fonts.MissingGlyphsError
+	if !errors.As(err, &mg) {
+		return err
+	}
+	if m.seen == nil {
+		m.seen = make(map[rune]bool)
+	}
+	for _, r := range mg.Runes {
+		if !m.seen[r] {
+			m.seen[r] = true
+			m.runes = append(m.runes, r)
+		}
+	}
+	return nil
+}
+
+// Err returns a *fonts.MissingGlyphsError with every rune noted, each once
+// in order of appearance, or nil if there is none.
+func (m *Missing) Err() error {
+	if len(m.runes) == 0 {
+		return nil
+	}
+	return &fonts.MissingGlyphsError{Runes: m.runes}
+}
+
+// RGB returns the 8-bit components of c, as gopdf's color setters take
+// them.
+func RGB(c color.Color) (r, g, b uint8) {
+	cr, cg, cb, _ := c.RGBA()
+	return uint8(cr >> 8), uint8(cg >> 8), uint8(cb >> 8)
+}
+
+// Paragraph lays out and draws runs, and returns the y position of the
+// next baseline below them, where the next content can start.
+func Paragraph(reg *fonts.Registry, runs []Run, x, y float64, opts Options) (float64, error) {
+	lines, err := Layout(reg, runs, x, y, opts)
+	if err != nil {
+		return y, err
+	}
+	opts.setDefaults()
+	next := y
+	if len(lines) > 0 {
+		last := lines[len(lines)-1]
+		next = last.Y + opts.LineHeight
+	}
+	return next, Draw(reg, lines, opts)
+}
+
+// Markup parses markup (see Parse) and draws it like Paragraph.
+func Markup(reg *fonts.Registry, markup string, x, y float64, opts Options) (float64, error) {
+	runs, err := Parse(markup)
+	if err != nil {
+		return y, err
+	}
+	return Paragraph(reg, runs, x, y, opts)
+}
+
+// merge joins neighboring fragments of the same style, so each is drawn
+// with one Text call.
+func merge(frags []Fragment) []Fragment {
+	var out []Fragment
+	for _, f := range frags {
+		if n := len(out); n > 0 && out[n-1].sameStyle(f.Run) {
+			out[n-1].Text += f.Text
+			out[n-1].Width += f.Width
+			continue
+		}
+		out = append(out, f)
+	}
+	return out
+}
+
+// piece is a measured part of a word, or of the spaces before it.
+type piece struct {
+	run   Run
+	width float64
+}
+
+// layouter holds the state of Layout.
+type layouter struct {
+	reg   *fonts.Registry
+	opts  Options
+	x, y  float64 // y is the previous baseline
+	lines []Line
+
+	frags   []Fragment // Fragments of the line being built; X relative to the line start
+	cur     float64    // Width of the line being built
+	started bool       // Whether the current paragraph has produced a line yet
+
+	word   []piece // Word being read
+	spaces []piece // Spaces read before it
+}
+
+// resolve fills the unset fields of run from the options.
+func (l *layouter) resolve(run Run) Run {
+	if run.Family == "" {
+		run.Family = l.opts.Family
+	}
+	if run.Size <= 0 {
+		run.Size = l.opts.Size
+	}
+	if run.Color == nil {
+		run.Color = l.opts.Color
+	}
+	return run
+}
+
+func (l *layouter) avail() float64 {
+	if !l.started {
+		return l.opts.Width - l.opts.FirstIndent
+	}
+	return l.opts.Width - l.opts.Indent
+}
+
+// run splits a run into words, spaces and newlines.
+func (l *layouter) run(run Run) error {
+	text := strings.ReplaceAll(run.Text, "\r\n", "\n")
+	for text != "" {
+		r, size := utf8.DecodeRuneInString(text)
+		switch {
+		case r == '\n':
+			if err := l.endWord(); err != nil {
+				return err
+			}
+			l.spaces = nil
+			l.breakLine()
+			l.started = false
+			text = text[size:]
+			continue
+		case unicode.IsSpace(r):
+			if err := l.endWord(); err != nil {
+				return err
+			}
+			end := strings.IndexFunc(text, func(r rune) bool { return !unicode.IsSpace(r) || r == '\n' })
+			if end < 0 {
+				end = len(text)
+			}
+			if err := l.add(&l.spaces, run, strings.Repeat(" ", utf8.RuneCountInString(text[:end]))); err != nil {
+				return err
+			}
+			text = text[end:]
+		default:
+			end := strings.IndexFunc(text, unicode.IsSpace)
+			if end < 0 {
+				end = len(text)
+			}
+			if err := l.add(&l.word, run, text[:end]); err != nil {
+				return err
+			}
+			text = text[end:]
+		}
+	}
+	return nil
+}
+
+// add measures text in the style of run and appends it to pieces.
+func (l *layouter) add(pieces *[]piece, run Run, text string) error {
+	run.Text = text
+	w, err := l.measure(run)
+	if err != nil {
+		return err
+	}
+	*pieces = append(*pieces, piece{run: run, width: w})
+	return nil
+}
+
+func (l *layouter) measure(run Run) (float64, error) {
+	if err := l.reg.SetFont(run.Family, run.Style, run.Size); err != nil {
+		return 0, err
+	}
+	return l.reg.MeasureTextWidth(run.Text)
+}
+
+// endWord places the word read so far, with the spaces before it.
+func (l *layouter) endWord() error {
+	if len(l.word) == 0 {
+		return nil
+	}
+	word, spaces := l.word, l.spaces
+	l.word, l.spaces = nil, nil
+
+	ww, sw := sum(word), sum(spaces)
+	if len(l.frags) > 0 && l.cur+sw+ww > l.avail() {
+		l.breakLine()
+	}
+	if len(l.frags) > 0 {
+		for _, p := range spaces {
+			l.place(p)
+		}
+	}
+	for _, p := range word {
+		for l.cur+p.width > l.avail() && p.run.Text != "" {
+			// Only a word wider than the line gets here: place as much
+			// of this piece as fits and continue on the next line.
+			head, hw, err := l.split(p.run, l.avail()-l.cur)
+			if err != nil {
+				return err
+			}
+			if head != "" {
+				l.place(piece{run: withText(p.run, head), width: hw})
+				p.run.Text = p.run.Text[len(head):]
+				if p.width, err = l.measure(p.run); err != nil {
+					return err
+				}
+			}
+			if p.run.Text != "" {
+				l.breakLine()
+			}
+		}
+		if p.run.Text != "" {
+			l.place(p)
+		}
+	}
+	return nil
+}
+
+// split returns the longest start of run's text that fits in width, and
+// its width. It returns "" if not even one character fits, unless the line
+// is empty, in which case the first character is returned.
+func (l *layouter) split(run Run, width float64) (string, float64, error) {
+	runes := []rune(run.Text)
+	lo, hi := 0, len(runes)-1
+	for lo < hi {
+		mid := (lo + hi + 1) / 2
+		w, err := l.measure(withText(run, string(runes[:mid])))
+		if err != nil {
+			return "", 0, err
+		}
+		if w <= width {
+			lo = mid
+		} else {
+			hi = mid - 1
+		}
+	}
+	if lo == 0 && l.cur == 0 {
+		lo = 1
+	}
+	head := string(runes[:lo])
+	if head == "" {
+		return "", 0, nil
+	}
+	hw, err := l.measure(withText(run, head))
+	return head, hw, err
+}
+
+// place appends a piece to the line being built.
+func (l *layouter) place(p piece) {
+	l.frags = append(l.frags, Fragment{Run: p.run, X: l.cur, Width: p.width})
+	l.cur += p.width
+}
+
+// breakLine finishes the line being built.
+func (l *layouter) breakLine() {
+	indent := l.opts.Indent
+	if !l.started {
+		indent = l.opts.FirstIndent
+	}
+	// Drop spaces at the end of the line.
+	for n := len(l.frags); n > 0 && strings.TrimSpace(l.frags[n-1].Text) == ""; n-- {
+		l.cur -= l.frags[n-1].Width
+		l.frags = l.frags[:n-1]
+	}
+
+	size := l.opts.Size
+	for _, f := range l.frags {
+		size = max(size, f.Size)
+	}
+	height := l.opts.LineHeight * size / l.opts.Size
+	l.y += height
+
+	line := Line{X: l.x + indent, Y: l.y, Width: l.cur, Height: height}
+	for _, f := range l.frags {
+		f.X += line.X
+		line.Fragments = append(line.Fragments, f)
+	}
+	l.lines = append(l.lines, line)
+
+	l.frags, l.cur, l.started = nil, 0, true
+}
+
+func sum(pieces []piece) float64 {
+	total := 0.0
+	for _, p := range pieces {
+		total += p.width
+	}
+	return total
+}
+
+func withText(run Run, text string) Run {
+	run.Text = text
+	return run
+}
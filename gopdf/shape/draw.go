@@ -0,0 +1,313 @@
+package shape
+
+import (
+	"bytes"
+	"compress/zlib"
+	"crypto/sha256"
+	"fmt"
+	"image/color"
+	"io"
+	"math"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"weak"
+
+	"github.com/signintech/gopdf"
+)
+
+// Cap is the shape of the ends of open subpaths and dashes.
+type Cap int
+
+const (
+	ButtCap   Cap = iota // Square end at the end point
+	RoundCap             // Half circle around the end point
+	SquareCap            // Square end half the line width past the end point
+)
+
+// Join is the shape of the corners between segments.
+type Join int
+
+const (
+	MiterJoin Join = iota // Sharp corner, beveled if very sharp
+	RoundJoin
+	BevelJoin
+)
+
+// FillRule decides which areas of a path that crosses itself, or has
+// subpaths inside others, are inside the path.
+type FillRule int
+
+const (
+	// NonZero fills the areas the path winds around, so a subpath inside
+	// another is a hole only if it goes the other way round.
+	NonZero FillRule = iota
+	// EvenOdd fills the areas inside an odd number of subpaths, so any
+	// subpath inside another is a hole.
+	EvenOdd
+)
+
+// Style is how a path is drawn. Zero fields get the default given in
+// their comment.
+type Style struct {
+	// Fill and Stroke are the colors the path is filled and stroked with.
+	// A nil color leaves the path unfilled or unstroked; with no color and
+	// no gradient the path is stroked in black.
+	Fill, Stroke color.Color
+
+	// Gradient fills the path with a gradient instead of Fill, and
+	// StrokeGradient strokes it with one instead of Stroke.
+	Gradient, StrokeGradient *Gradient
+
+	// FillOpacity and StrokeOpacity are how opaque the fill and the stroke
+	// are, more than 0 and at most 1 (1).
+	FillOpacity, StrokeOpacity float64
+
+	FillRule FillRule // (NonZero)
+	Width    float64  // Width of the stroke, in points (1)
+	Cap      Cap      // (ButtCap)
+	Join     Join     // (MiterJoin)
+
+	// Dash is the lengths of the dashes and gaps of the stroke, in points,
+	// e.g. {6, 3} for 6pt dashes 3pt apart; none draws a solid line. The
+	// pattern starts DashPhase points in.
+	Dash      []float64
+	DashPhase float64
+}
+
+func (s *Style) setDefaults() {
+	if s.Fill == nil && s.Gradient == nil && !s.stroked() {
+		s.Stroke = color.Black
+	}
+	if s.FillOpacity <= 0 || s.FillOpacity > 1 {
+		s.FillOpacity = 1
+	}
+	if s.StrokeOpacity <= 0 || s.StrokeOpacity > 1 {
+		s.StrokeOpacity = 1
+	}
+	if s.Width <= 0 {
+		s.Width = 1
+	}
+}
+
+// stroked reports whether s strokes the path.
+func (s *Style) stroked() bool {
+	return s.Stroke != nil || s.StrokeGradient != nil
+}
+
+// miterLimit is the miter limit of every path: corners sharper than about
+// 29° are beveled, so a miter is at most twice the stroke width long.
+const miterLimit = 4
+
+// Draw draws p on the current page of pdf with style s, as one PDF path.
+//
+// gopdf has no way to add a path of its own to a page, so Draw writes the
+// path into a one-page PDF and places that page as a form XObject, the way
+// gopdf imports pages of other PDFs. Identical paths drawn in the same
+// document share one form.
+func (p *Path) Draw(pdf *gopdf.GoPdf, s Style) error {
+	if p.Empty() {
+		return nil
+	}
+	s.setDefaults()
+
+	// The form is the box of the path, in points, with room for the stroke.
+	lo, hi := p.Bounds()
+	// The stroke reaches half its width from the path, times the miter
+	// limit at a miter join, and times √2 at the corners of a square cap.
+	pad := 1.0
+	if s.stroked() {
+		reach := 1.0
+		if s.Cap == SquareCap {
+			reach = math.Sqrt2
+		}
+		if s.Join == MiterJoin {
+			reach = max(reach, miterLimit)
+		}
+		pad += s.Width / 2 * reach
+	}
+	left, top := pdf.UnitsToPoints(lo.X)-pad, pdf.UnitsToPoints(lo.Y)-pad
+	w := pdf.UnitsToPoints(hi.X) + pad - left
+	h := pdf.UnitsToPoints(hi.Y) + pad - top
+
+	content, resources := p.content(pdf, s, left, top, h)
+	tpl, err := documentOf(pdf).template(pdf, onePage(content, resources, w, h))
+	if err != nil {
+		return err
+	}
+	pdf.UseImportedTemplate(tpl,
+		pdf.PointsToUnits(left), pdf.PointsToUnits(top),
+		pdf.PointsToUnits(w), pdf.PointsToUnits(h))
+	return nil
+}
+
+// content returns the content stream that draws p with style s in a form
+// whose top left corner is at (left, top) on the page and which is h
+// points high, and the resources it uses.
+func (p *Path) content(pdf *gopdf.GoPdf, s Style, left, top, h float64) (content, resources string) {
+	pt := func(q Point) string {
+		x := pdf.UnitsToPoints(q.X) - left
+		y := h - (pdf.UnitsToPoints(q.Y) - top)
+		return num(x) + " " + num(y)
+	}
+	var path strings.Builder
+	for _, seg := range p.segs {
+		switch seg.op {
+		case moveTo, lineTo:
+			fmt.Fprintf(&path, "%s %c\n", pt(seg.pts[0]), seg.op)
+		case curveTo:
+			fmt.Fprintf(&path, "%s %s %s c\n", pt(seg.pts[0]), pt(seg.pts[1]), pt(seg.pts[2]))
+		case closeOp:
+			path.WriteString("h\n")
+		}
+	}
+	evenOdd := ""
+	if s.FillRule == EvenOdd {
+		evenOdd = "*"
+	}
+
+	var b, res strings.Builder
+	if s.FillOpacity < 1 || s.StrokeOpacity < 1 {
+		fmt.Fprintf(&res, "/ExtGState << /GS0 << /ca %s /CA %s >> >> ", num(s.FillOpacity), num(s.StrokeOpacity))
+		b.WriteString("/GS0 gs\n")
+	}
+	if s.stroked() {
+		if g := s.StrokeGradient; g != nil {
+			// A shading pattern is the stroke color.
+			fmt.Fprintf(&res, "/Pattern << /P0 << /PatternType 2 /Shading %s >> >> ", g.shading(pt))
+			b.WriteString("/Pattern CS /P0 SCN\n")
+		} else {
+			fmt.Fprintf(&b, "%s RG\n", rgb(s.Stroke))
+		}
+		fmt.Fprintf(&b, "%s w %d J %d j %d M\n", num(s.Width), s.Cap, s.Join, miterLimit)
+		dash := make([]string, len(s.Dash))
+		for i, d := range s.Dash {
+			dash[i] = num(d)
+		}
+		fmt.Fprintf(&b, "[%s] %s d\n", strings.Join(dash, " "), num(s.DashPhase))
+	}
+
+	switch {
+	case s.Gradient != nil:
+		// Clip to the path and paint the gradient over the clip, then stroke.
+		fmt.Fprintf(&res, "/Shading << /Sh0 %s >> ", s.Gradient.shading(pt))
+		fmt.Fprintf(&b, "q\n%sW%s n\n/Sh0 sh\nQ\n", path.String(), evenOdd)
+		if s.stroked() {
+			b.WriteString(path.String() + "S\n")
+		}
+	case s.Fill != nil:
+		fmt.Fprintf(&b, "%s rg\n", rgb(s.Fill))
+		paint := "f"
+		if s.stroked() {
+			paint = "B"
+		}
+		b.WriteString(path.String() + paint + evenOdd + "\n")
+	default:
+		b.WriteString(path.String() + "S\n")
+	}
+	return b.String(), res.String()
+}
+
+// document is what Draw keeps for every document it draws in.
+type document struct {
+	// sources are the one-page PDFs imported into the document. gofpdi
+	// tells its sources apart by their address, so they are kept until
+	// the document is gone: a new source must not reuse the address of
+	// an old one.
+	sources   []*io.ReadSeeker
+	templates map[[32]byte]int // Template of the sha256 of every form
+}
+
+var (
+	mu        sync.Mutex
+	documents = make(map[weak.Pointer[gopdf.GoPdf]]*document)
+)
+
+// documentOf returns the document of pdf, made on first use and dropped
+// when pdf is garbage collected.
+func documentOf(pdf *gopdf.GoPdf) *document {
+	mu.Lock()
+	defer mu.Unlock()
+	key := weak.Make(pdf)
+	d, ok := documents[key]
+	if !ok {
+		d = &document{templates: make(map[[32]byte]int)}
+		documents[key] = d
+		runtime.AddCleanup(pdf, func(key weak.Pointer[gopdf.GoPdf]) {
+			mu.Lock()
+			delete(documents, key)
+			mu.Unlock()
+		}, key)
+	}
+	return d
+}
+
+// template returns the imported template of the page of src, a one-page
+// PDF, importing it into pdf if it is new.
+func (d *document) template(pdf *gopdf.GoPdf, src []byte) (tpl int, err error) {
+	sum := sha256.Sum256(src)
+	mu.Lock()
+	defer mu.Unlock()
+	if tpl, ok := d.templates[sum]; ok {
+		return tpl, nil
+	}
+	// gofpdi panics on a PDF it cannot read.
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("shape: importing path: %v", r)
+		}
+	}()
+	rs := new(io.ReadSeeker)
+	*rs = bytes.NewReader(src)
+	d.sources = append(d.sources, rs)
+	tpl = pdf.ImportPageStream(rs, 1, "/MediaBox")
+	d.templates[sum] = tpl
+	return tpl, nil
+}
+
+// onePage returns a PDF of one w by h points page drawn by content, with
+// the resources given, the entries of a resource dictionary.
+func onePage(content, resources string, w, h float64) []byte {
+	var z bytes.Buffer
+	zw := zlib.NewWriter(&z)
+	zw.Write([]byte(content))
+	zw.Close()
+
+	objs := []string{
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		"<< /Type /Pages /Kids [3 0 R] /Count 1 >>",
+		fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %s %s] /Resources << %s>> /Contents 4 0 R >>", num(w), num(h), resources),
+		fmt.Sprintf("<< /Length %d /Filter /FlateDecode >>\nstream\n%s\nendstream", z.Len(), z.Bytes()),
+	}
+	var b bytes.Buffer
+	b.WriteString("%PDF-1.4\n")
+	offsets := make([]int, len(objs))
+	for i, obj := range objs {
+		offsets[i] = b.Len()
+		fmt.Fprintf(&b, "%d 0 obj\n%s\nendobj\n", i+1, obj)
+	}
+	xref := b.Len()
+	fmt.Fprintf(&b, "xref\n0 %d\n0000000000 65535 f \n", len(objs)+1)
+	for _, off := range offsets {
+		fmt.Fprintf(&b, "%010d 00000 n \n", off)
+	}
+	fmt.Fprintf(&b, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(objs)+1, xref)
+	return b.Bytes()
+}
+
+// rgb returns the PDF color operands of c.
+func rgb(c color.Color) string {
+	r, g, b, _ := c.RGBA()
+	return num(float64(r)/0xffff) + " " + num(float64(g)/0xffff) + " " + num(float64(b)/0xffff)
+}
+
+// num writes v with at most three decimals.
+func num(v float64) string {
+	s := strconv.FormatFloat(v, 'f', 3, 64)
+	s = strings.TrimRight(strings.TrimRight(s, "0"), ".")
+	if s == "-0" || s == "" {
+		s = "0"
+	}
+	return s
+}
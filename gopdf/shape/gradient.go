@@ -0,0 +1,90 @@
+package shape
+
+import (
+	"fmt"
+	"image/color"
+	"sort"
+	"strings"
+)
+
+// Gradient is a linear gradient: the colors of Stops blend along the line
+// from (X1, Y1) to (X2, Y2), and stay the color of the first and the last
+// stop before and after it. Its points are on the page, like the points of
+// a path.
+type Gradient struct {
+	X1, Y1, X2, Y2 float64
+	Stops          []Stop
+}
+
+// Stop is the color of a gradient at Offset, from 0 at the start of the
+// gradient line to 1 at its end.
+type Stop struct {
+	Offset float64
+	Color  color.Color
+}
+
+// Transform returns the gradient g transformed by m. Its line is the one
+// that gives every point of the page the color g gives it before m, even
+// if m slants or stretches the page.
+func (g Gradient) Transform(m Matrix) Gradient {
+	// The offset of a point p is (m⁻¹p - P1)·D / |D|², with D = P2 - P1,
+	// which is (p - mP1)·E with E = m⁻ᵀD / |D|²: the gradient from mP1 to
+	// mP1 + E/|E|².
+	dx, dy := g.X2-g.X1, g.Y2-g.Y1
+	det := m[0]*m[3] - m[1]*m[2]
+	d2 := dx*dx + dy*dy
+	p1 := m.Apply(Point{g.X1, g.Y1})
+	if det == 0 || d2 == 0 {
+		p2 := m.Apply(Point{g.X2, g.Y2})
+		g.X1, g.Y1, g.X2, g.Y2 = p1.X, p1.Y, p2.X, p2.Y
+		return g
+	}
+	ex := (m[3]*dx - m[1]*dy) / det / d2
+	ey := (-m[2]*dx + m[0]*dy) / det / d2
+	e2 := ex*ex + ey*ey
+	g.X1, g.Y1 = p1.X, p1.Y
+	g.X2, g.Y2 = p1.X+ex/e2, p1.Y+ey/e2
+	return g
+}
+
+// shading returns the PDF axial shading of g, with its points written by pt.
+func (g Gradient) shading(pt func(Point) string) string {
+	return fmt.Sprintf("<< /ShadingType 2 /ColorSpace /DeviceRGB /Coords [%s %s] /Function %s /Extend [true true] >>",
+		pt(Point{g.X1, g.Y1}), pt(Point{g.X2, g.Y2}), g.function())
+}
+
+// function returns the PDF function of the colors of the stops, for
+// inputs from 0 to 1.
+func (g Gradient) function() string {
+	stops := append([]Stop(nil), g.Stops...)
+	sort.SliceStable(stops, func(i, j int) bool { return stops[i].Offset < stops[j].Offset })
+	if len(stops) == 0 {
+		stops = []Stop{{0, color.Black}}
+	}
+	for i := range stops {
+		stops[i].Offset = min(max(stops[i].Offset, 0), 1)
+	}
+	if first := stops[0]; first.Offset > 0 {
+		stops = append([]Stop{{0, first.Color}}, stops...)
+	}
+	if last := stops[len(stops)-1]; last.Offset < 1 || len(stops) == 1 {
+		stops = append(stops, Stop{1, last.Color})
+	}
+
+	blend := func(a, b color.Color) string {
+		return fmt.Sprintf("<< /FunctionType 2 /Domain [0 1] /C0 [%s] /C1 [%s] /N 1 >>", rgb(a), rgb(b))
+	}
+	if len(stops) == 2 {
+		return blend(stops[0].Color, stops[1].Color)
+	}
+	var funcs, bounds, encode []string
+	for i := range len(stops) - 1 {
+		funcs = append(funcs, blend(stops[i].Color, stops[i+1].Color))
+		encode = append(encode, "0 1")
+		if i > 0 {
+			bounds = append(bounds, num(stops[i].Offset))
+		}
+	}
+	return fmt.Sprintf("<< /FunctionType 3 /Domain [0 1] /Functions [%s] /Bounds [%s] /Encode [%s] >>",
+		strings.Join(funcs, " "), strings.Join(bounds, " "), strings.Join(encode, " "))
+}
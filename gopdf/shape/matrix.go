@@ -0,0 +1,73 @@
+package shape
+
+import "math"
+
+// Matrix is an affine transformation {a, b, c, d, e, f}, as in PDF and
+// SVG: it maps (x, y) to (a*x + c*y + e, b*x + d*y + f).
+type Matrix [6]float64
+
+// Identity is the transformation that changes nothing.
+var Identity = Matrix{1, 0, 0, 1, 0, 0}
+
+// Translate returns a transformation that moves by (x, y).
+func Translate(x, y float64) Matrix {
+	return Matrix{1, 0, 0, 1, x, y}
+}
+
+// Scale returns a transformation that scales by sx across and sy down.
+func Scale(sx, sy float64) Matrix {
+	return Matrix{sx, 0, 0, sy, 0, 0}
+}
+
+// Rotate returns a transformation that turns by angle degrees, clockwise
+// on the page.
+func Rotate(angle float64) Matrix {
+	sin, cos := math.Sincos(angle * math.Pi / 180)
+	return Matrix{cos, sin, -sin, cos, 0, 0}
+}
+
+// SkewX returns a transformation that slants vertical lines by angle
+// degrees.
+func SkewX(angle float64) Matrix {
+	return Matrix{1, 0, math.Tan(angle * math.Pi / 180), 1, 0, 0}
+}
+
+// SkewY returns a transformation that slants horizontal lines by angle
+// degrees.
+func SkewY(angle float64) Matrix {
+	return Matrix{1, math.Tan(angle * math.Pi / 180), 0, 1, 0, 0}
+}
+
+// Then returns the transformation that applies m, then n.
+func (m Matrix) Then(n Matrix) Matrix {
+	return Matrix{
+		m[0]*n[0] + m[1]*n[2],
+		m[0]*n[1] + m[1]*n[3],
+		m[2]*n[0] + m[3]*n[2],
+		m[2]*n[1] + m[3]*n[3],
+		m[4]*n[0] + m[5]*n[2] + n[4],
+		m[4]*n[1] + m[5]*n[3] + n[5],
+	}
+}
+
+// Apply returns p transformed by m.
+func (m Matrix) Apply(p Point) Point {
+	return Point{m[0]*p.X + m[2]*p.Y + m[4], m[1]*p.X + m[3]*p.Y + m[5]}
+}
+
+// Scaling returns how much m scales lengths, on average over all
+// directions: the factor for the width of a stroke.
+func (m Matrix) Scaling() float64 {
+	return math.Sqrt(math.Abs(m[0]*m[3] - m[1]*m[2]))
+}
+
+// Transform transforms every point of p by m.
+func (p *Path) Transform(m Matrix) *Path {
+	for i := range p.segs {
+		for j := range p.segs[i].pts {
+			p.segs[i].pts[j] = m.Apply(p.segs[i].pts[j])
+		}
+	}
+	p.start, p.current = m.Apply(p.start), m.Apply(p.current)
+	return p
+}
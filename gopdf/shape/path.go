@@ -0,0 +1,240 @@
+// Package shape builds vector paths out of lines, Bézier curves and arcs
+// and draws each one as a single PDF path, so a shape is filled and
+// stroked as one object, with its own fill rule, line caps, joins and
+// dashes.
+//
+//	p := shape.New()
+//	p.MoveTo(100, 100)
+//	p.LineTo(150, 140)
+//	p.QuadTo(200, 60, 250, 140)
+//	p.Close()
+//	err := p.Draw(&pdf, shape.Style{Fill: color.RGBA{200, 200, 255, 255}})
+//
+// Coordinates are in the document's unit (Config.Unit), from the top left
+// corner of the page, like the coordinates given to gopdf. Angles are in
+// degrees, clockwise from the positive x axis, as y grows down the page.
+package shape
+
+import "math"
+
+// Point is a point of a path.
+type Point struct {
+	X, Y float64
+}
+
+// op is a path operator of a segment.
+type op byte
+
+const (
+	moveTo  op = 'm'
+	lineTo  op = 'l'
+	curveTo op = 'c'
+	closeOp op = 'h'
+)
+
+// segment is one operator of a path and its points: one for moveTo and
+// lineTo, the two control points and the end point for curveTo, none for
+// closeOp.
+type segment struct {
+	op  op
+	pts [3]Point
+}
+
+// Path is a list of subpaths, each started by MoveTo. The zero value is an
+// empty path ready to use.
+type Path struct {
+	segs    []segment
+	start   Point // Start of the current subpath
+	current Point
+	open    bool // A subpath has been started
+}
+
+// New returns an empty path.
+func New() *Path {
+	return &Path{}
+}
+
+// Empty reports whether p has no segments.
+func (p *Path) Empty() bool {
+	return len(p.segs) == 0
+}
+
+// Current returns the current point: the end of the last segment.
+func (p *Path) Current() Point {
+	return p.current
+}
+
+// MoveTo starts a new subpath at (x, y).
+func (p *Path) MoveTo(x, y float64) *Path {
+	p.segs = append(p.segs, segment{op: moveTo, pts: [3]Point{{x, y}}})
+	p.start, p.current, p.open = Point{x, y}, Point{x, y}, true
+	return p
+}
+
+// LineTo adds a straight line from the current point to (x, y). Without a
+// current point it starts a subpath at (x, y) instead.
+func (p *Path) LineTo(x, y float64) *Path {
+	if !p.open {
+		return p.MoveTo(x, y)
+	}
+	p.segs = append(p.segs, segment{op: lineTo, pts: [3]Point{{x, y}}})
+	p.current = Point{x, y}
+	return p
+}
+
+// CurveTo adds a cubic Bézier curve from the current point to (x, y), with
+// the control points (x1, y1) and (x2, y2).
+func (p *Path) CurveTo(x1, y1, x2, y2, x, y float64) *Path {
+	if !p.open {
+		p.MoveTo(x1, y1)
+	}
+	p.segs = append(p.segs, segment{op: curveTo, pts: [3]Point{{x1, y1}, {x2, y2}, {x, y}}})
+	p.current = Point{x, y}
+	return p
+}
+
+// QuadTo adds a quadratic Bézier curve from the current point to (x, y),
+// with the control point (cx, cy).
+func (p *Path) QuadTo(cx, cy, x, y float64) *Path {
+	if !p.open {
+		p.MoveTo(cx, cy)
+	}
+	c := p.current
+	return p.CurveTo(
+		c.X+2*(cx-c.X)/3, c.Y+2*(cy-c.Y)/3,
+		x+2*(cx-x)/3, y+2*(cy-y)/3,
+		x, y,
+	)
+}
+
+// Arc adds an arc of the circle of radius r around (cx, cy), from the
+// angle start to the angle end; it goes clockwise if end is greater than
+// start. A line joins the current point to the start of the arc.
+func (p *Path) Arc(cx, cy, r, start, end float64) *Path {
+	return p.EllipticArc(cx, cy, r, r, 0, start, end)
+}
+
+// EllipticArc adds an arc of the ellipse with radii rx and ry around
+// (cx, cy), its x axis turned by rotation, from the angle start to the
+// angle end of the unturned ellipse. A line joins the current point to the
+// start of the arc.
+func (p *Path) EllipticArc(cx, cy, rx, ry, rotation, start, end float64) *Path {
+	sin, cos := math.Sincos(rotation * math.Pi / 180)
+	at := func(a float64) (float64, float64) {
+		x, y := rx*math.Cos(a), ry*math.Sin(a)
+		return cx + x*cos - y*sin, cy + x*sin + y*cos
+	}
+	// Derivative of at, for the control points.
+	tangent := func(a float64) (float64, float64) {
+		x, y := -rx*math.Sin(a), ry*math.Cos(a)
+		return x*cos - y*sin, x*sin + y*cos
+	}
+
+	a0, a1 := start*math.Pi/180, end*math.Pi/180
+	if x, y := at(a0); !p.open {
+		p.MoveTo(x, y)
+	} else if (Point{x, y}) != p.current {
+		p.LineTo(x, y)
+	}
+	if a0 == a1 {
+		return p
+	}
+	// Every part of at most a quarter turn is close to a cubic curve.
+	n := int(math.Ceil(math.Abs(a1-a0) / (math.Pi / 2)))
+	step := (a1 - a0) / float64(n)
+	k := 4.0 / 3 * math.Tan(step/4)
+	for i := range n {
+		b0, b1 := a0+float64(i)*step, a0+float64(i+1)*step
+		x0, y0 := at(b0)
+		x3, y3 := at(b1)
+		dx0, dy0 := tangent(b0)
+		dx1, dy1 := tangent(b1)
+		p.CurveTo(x0+k*dx0, y0+k*dy0, x3-k*dx1, y3-k*dy1, x3, y3)
+	}
+	return p
+}
+
+// Close closes the current subpath with a straight line back to its
+// start.
+func (p *Path) Close() *Path {
+	if !p.open {
+		return p
+	}
+	p.segs = append(p.segs, segment{op: closeOp})
+	p.current, p.open = p.start, false
+	return p
+}
+
+// Rect adds a closed rectangle with its top left corner at (x, y).
+func (p *Path) Rect(x, y, w, h float64) *Path {
+	return p.MoveTo(x, y).LineTo(x+w, y).LineTo(x+w, y+h).LineTo(x, y+h).Close()
+}
+
+// RoundedRect adds a closed rectangle with its top left corner at (x, y)
+// and corners rounded with radius r, at most half the shorter side.
+func (p *Path) RoundedRect(x, y, w, h, r float64) *Path {
+	r = min(r, math.Abs(w)/2, math.Abs(h)/2)
+	if r <= 0 {
+		return p.Rect(x, y, w, h)
+	}
+	p.MoveTo(x+r, y)
+	p.Arc(x+w-r, y+r, r, -90, 0)
+	p.Arc(x+w-r, y+h-r, r, 0, 90)
+	p.Arc(x+r, y+h-r, r, 90, 180)
+	p.Arc(x+r, y+r, r, 180, 270)
+	return p.Close()
+}
+
+// Circle adds a closed circle of radius r around (cx, cy).
+func (p *Path) Circle(cx, cy, r float64) *Path {
+	return p.Ellipse(cx, cy, r, r)
+}
+
+// Ellipse adds a closed ellipse with radii rx and ry around (cx, cy).
+func (p *Path) Ellipse(cx, cy, rx, ry float64) *Path {
+	p.MoveTo(cx+rx, cy)
+	p.EllipticArc(cx, cy, rx, ry, 0, 0, 360)
+	return p.Close()
+}
+
+// Polygon adds a closed subpath through the points.
+func (p *Path) Polygon(points ...Point) *Path {
+	if len(points) == 0 {
+		return p
+	}
+	return p.Polyline(points...).Close()
+}
+
+// Polyline adds an open subpath through the points.
+func (p *Path) Polyline(points ...Point) *Path {
+	for i, pt := range points {
+		if i == 0 {
+			p.MoveTo(pt.X, pt.Y)
+		} else {
+			p.LineTo(pt.X, pt.Y)
+		}
+	}
+	return p
+}
+
+// Bounds returns the top left and bottom right corners of the smallest box
+// holding every point of p. Control points count as points, so the box
+// holds the whole path, though not always tightly.
+func (p *Path) Bounds() (lo, hi Point) {
+	lo = Point{math.Inf(1), math.Inf(1)}
+	hi = Point{math.Inf(-1), math.Inf(-1)}
+	for _, s := range p.segs {
+		n := 1
+		switch s.op {
+		case curveTo:
+			n = 3
+		case closeOp:
+			n = 0
+		}
+		for _, pt := range s.pts[:n] {
+			lo = Point{min(lo.X, pt.X), min(lo.Y, pt.Y)}
+			hi = Point{max(hi.X, pt.X), max(hi.Y, pt.Y)}
+		}
+	}
+	return lo, hi
+}
@@ -0,0 +1,274 @@
+package svg
+
+import (
+	"image/color"
+	"math"
+	"strings"
+
+	"github.com/signintech/gopdf"
+
+	"pdf-tutorial/gopdf/shape"
+)
+
+// drawer draws the elements of an image.
+type drawer struct {
+	img  *Image
+	pdf  *gopdf.GoPdf
+	view [4]float64 // viewBox, for percentages
+}
+
+// group draws n and its children, with the transformation m from the user
+// units of n's parent to the page and n's style s.
+func (d *drawer) group(n *node, m shape.Matrix, s style) error {
+	if s.hidden {
+		return nil
+	}
+	m = transform(n.attrs["transform"]).Then(m)
+	if n.name != "svg" {
+		if path := d.shape(n); path != nil {
+			return d.fill(n, path, m, s)
+		}
+	}
+	switch n.name {
+	case "svg", "g", "a", "switch":
+		for _, c := range n.children {
+			if err := d.group(c, m, inherit(s, c, d.img)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// shape returns the path of a shape element in its user units, or nil if
+// n is not one.
+func (d *drawer) shape(n *node) *shape.Path {
+	vw, vh := d.view[2], d.view[3]
+	diag := math.Hypot(vw, vh) / math.Sqrt2
+	attr := func(name string, ref float64) float64 {
+		return length(n.attrs[name], ref)
+	}
+	p := shape.New()
+	switch n.name {
+	case "path":
+		pathData(p, n.attrs["d"])
+	case "rect":
+		x, y, w, h := attr("x", vw), attr("y", vh), attr("width", vw), attr("height", vh)
+		if w <= 0 || h <= 0 {
+			return nil
+		}
+		rx, ry := attr("rx", vw), attr("ry", vh)
+		if _, ok := n.attrs["rx"]; !ok {
+			rx = ry
+		}
+		if _, ok := n.attrs["ry"]; !ok {
+			ry = rx
+		}
+		rx, ry = min(max(rx, 0), w/2), min(max(ry, 0), h/2)
+		if rx == 0 || ry == 0 {
+			p.Rect(x, y, w, h)
+			break
+		}
+		p.MoveTo(x+rx, y)
+		p.EllipticArc(x+w-rx, y+ry, rx, ry, 0, -90, 0)
+		p.EllipticArc(x+w-rx, y+h-ry, rx, ry, 0, 0, 90)
+		p.EllipticArc(x+rx, y+h-ry, rx, ry, 0, 90, 180)
+		p.EllipticArc(x+rx, y+ry, rx, ry, 0, 180, 270)
+		p.Close()
+	case "circle":
+		if r := attr("r", diag); r > 0 {
+			p.Circle(attr("cx", vw), attr("cy", vh), r)
+		}
+	case "ellipse":
+		if rx, ry := attr("rx", vw), attr("ry", vh); rx > 0 && ry > 0 {
+			p.Ellipse(attr("cx", vw), attr("cy", vh), rx, ry)
+		}
+	case "line":
+		p.MoveTo(attr("x1", vw), attr("y1", vh))
+		p.LineTo(attr("x2", vw), attr("y2", vh))
+	case "polyline", "polygon":
+		nums := numbers(n.attrs["points"])
+		for i := 0; i+1 < len(nums); i += 2 {
+			p.LineTo(nums[i], nums[i+1])
+		}
+		if n.name == "polygon" {
+			p.Close()
+		}
+	default:
+		return nil
+	}
+	return p
+}
+
+// fill draws the path of a shape element n, in its user units, with the
+// transformation m to the page and style s.
+func (d *drawer) fill(n *node, path *shape.Path, m shape.Matrix, s style) error {
+	if path.Empty() {
+		return nil
+	}
+	lo, hi := path.Bounds()
+	var st shape.Style
+	if n.name != "line" {
+		st.Fill, st.Gradient = d.paint(s.fill, s.color, lo, hi, m)
+	}
+	st.FillOpacity = s.fillOpacity * s.opacity
+	if st.FillOpacity <= 0 {
+		st.Fill, st.Gradient = nil, nil
+	}
+
+	// Stroke widths are in points, and scale with the image.
+	scale := m.Scaling() * d.pdf.UnitsToPoints(1)
+	if s.width > 0 {
+		st.Stroke, st.StrokeGradient = d.paint(s.stroke, s.color, lo, hi, m)
+	}
+	st.StrokeOpacity = s.strokeOpacity * s.opacity
+	if st.StrokeOpacity <= 0 || s.width*scale <= 0 {
+		st.Stroke, st.StrokeGradient = nil, nil
+	}
+	if st.Fill == nil && st.Gradient == nil && st.Stroke == nil && st.StrokeGradient == nil {
+		return nil
+	}
+	st.Width = s.width * scale
+	st.FillRule, st.Cap, st.Join = s.fillRule, s.cap, s.join
+	for _, v := range s.dash {
+		st.Dash = append(st.Dash, v*scale)
+	}
+	st.DashPhase = s.dashOffset * scale
+
+	return path.Transform(m).Draw(d.pdf, st)
+}
+
+// paint returns the color or the gradient of p for a shape whose box in
+// its user units is from lo to hi, drawn with the transformation m.
+func (d *drawer) paint(p paint, current color.Color, lo, hi shape.Point, m shape.Matrix) (color.Color, *shape.Gradient) {
+	switch {
+	case p.none && p.url == "":
+		return nil, nil
+	case p.current:
+		return current, nil
+	case p.url != "":
+		if g, ok := d.gradient(p.url, lo, hi, m); ok {
+			return g.color, g.gradient
+		}
+		if p.none {
+			return nil, nil
+		}
+	}
+	return p.color, nil
+}
+
+// gradientPaint is a gradient, or the color of a gradient drawn as one.
+type gradientPaint struct {
+	color    color.Color
+	gradient *shape.Gradient
+}
+
+// gradient returns the gradient with the id for a shape whose box in its
+// user units is from lo to hi, drawn with the transformation m.
+func (d *drawer) gradient(id string, lo, hi shape.Point, m shape.Matrix) (gradientPaint, bool) {
+	n := d.img.ids[id]
+	if n == nil || (n.name != "linearGradient" && n.name != "radialGradient") {
+		return gradientPaint{}, false
+	}
+	// Attributes and stops left out are those of the gradient it refers to.
+	attrs := make(map[string]string)
+	var stops []*node
+	for g, seen := n, 0; g != nil && seen < 10; seen++ {
+		for k, v := range g.attrs {
+			if _, ok := attrs[k]; !ok {
+				attrs[k] = v
+			}
+		}
+		if stops == nil {
+			for _, c := range g.children {
+				if c.name == "stop" {
+					stops = append(stops, c)
+				}
+			}
+		}
+		g = d.img.ids[strings.TrimPrefix(g.attrs["href"], "#")]
+	}
+
+	var g shape.Gradient
+	for _, stop := range stops {
+		props := declarations(stop.attrs["style"])
+		c, ok := parseColor(props["stop-color"])
+		if !ok {
+			if c, ok = parseColor(stop.attrs["stop-color"]); !ok {
+				c = color.Black
+			}
+		}
+		offset := length(stop.attrs["offset"], 1)
+		if len(g.Stops) > 0 {
+			offset = max(offset, g.Stops[len(g.Stops)-1].Offset)
+		}
+		g.Stops = append(g.Stops, shape.Stop{Offset: offset, Color: c})
+	}
+	switch {
+	case len(g.Stops) == 0:
+		return gradientPaint{}, true
+	case len(g.Stops) == 1 || n.name == "radialGradient":
+		return gradientPaint{color: g.Stops[len(g.Stops)-1].Color}, true
+	}
+
+	// The line of the gradient is in the box of the shape, from 0 to 1,
+	// unless its units are the user units.
+	units := shape.Scale(hi.X-lo.X, hi.Y-lo.Y).Then(shape.Translate(lo.X, lo.Y))
+	vw, vh := 1.0, 1.0
+	if attrs["gradientUnits"] == "userSpaceOnUse" {
+		units, vw, vh = shape.Identity, d.view[2], d.view[3]
+	}
+	x2 := "100%"
+	if v, ok := attrs["x2"]; ok {
+		x2 = v
+	}
+	g.X1, g.Y1 = length(attrs["x1"], vw), length(attrs["y1"], vh)
+	g.X2, g.Y2 = length(x2, vw), length(attrs["y2"], vh)
+	g = g.Transform(transform(attrs["gradientTransform"]).Then(units).Then(m))
+	return gradientPaint{gradient: &g}, true
+}
+
+// transform returns the transformation of a transform attribute, such as
+// "translate(10 20) rotate(45)".
+func transform(s string) shape.Matrix {
+	m := shape.Identity
+	for {
+		name, rest, ok := strings.Cut(s, "(")
+		if !ok {
+			return m
+		}
+		args, after, _ := strings.Cut(rest, ")")
+		s = after
+		a := numbers(args)
+		arg := func(i int, def float64) float64 {
+			if i < len(a) {
+				return a[i]
+			}
+			return def
+		}
+
+		var t shape.Matrix
+		switch strings.Trim(strings.TrimSpace(name), ",") {
+		case "matrix":
+			if len(a) != 6 {
+				continue
+			}
+			t = shape.Matrix(a)
+		case "translate":
+			t = shape.Translate(arg(0, 0), arg(1, 0))
+		case "scale":
+			t = shape.Scale(arg(0, 1), arg(1, arg(0, 1)))
+		case "rotate":
+			cx, cy := arg(1, 0), arg(2, 0)
+			t = shape.Translate(-cx, -cy).Then(shape.Rotate(arg(0, 0))).Then(shape.Translate(cx, cy))
+		case "skewX":
+			t = shape.SkewX(arg(0, 0))
+		case "skewY":
+			t = shape.SkewY(arg(0, 0))
+		default:
+			continue
+		}
+		// The transformations of a list apply from the last to the first.
+		m = t.Then(m)
+	}
+}
@@ -0,0 +1,259 @@
+package svg
+
+import (
+	"encoding/xml"
+	"errors"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// node is an element of an SVG document.
+type node struct {
+	name     string
+	attrs    map[string]string
+	children []*node
+	text     string // Text of the element, for <style>
+}
+
+// parseXML returns the root element of an XML document.
+func parseXML(r io.Reader) (*node, error) {
+	dec := xml.NewDecoder(r)
+	dec.Strict = false
+	dec.Entity = xml.HTMLEntity
+	var root *node
+	var stack []*node
+	for {
+		tok, err := dec.Token()
+		if errors.Is(err, io.EOF) {
+			return root, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			n := &node{name: t.Name.Local, attrs: make(map[string]string, len(t.Attr))}
+			for _, a := range t.Attr {
+				n.attrs[a.Name.Local] = a.Value
+			}
+			if len(stack) > 0 {
+				parent := stack[len(stack)-1]
+				parent.children = append(parent.children, n)
+			} else if root == nil {
+				root = n
+			}
+			stack = append(stack, n)
+		case xml.EndElement:
+			if len(stack) > 0 {
+				stack = stack[:len(stack)-1]
+			}
+		case xml.CharData:
+			if len(stack) > 0 {
+				stack[len(stack)-1].text += string(t)
+			}
+		}
+	}
+}
+
+// rule is a rule of a <style> element with a single simple selector, such
+// as "path", ".logo" or "#mark".
+type rule struct {
+	tag, class, id string
+	decls          map[string]string
+}
+
+// matches reports whether r applies to n.
+func (r rule) matches(n *node) bool {
+	if r.tag != "" && r.tag != "*" && r.tag != n.name {
+		return false
+	}
+	if r.id != "" && r.id != n.attrs["id"] {
+		return false
+	}
+	if r.class != "" {
+		for _, c := range strings.Fields(n.attrs["class"]) {
+			if c == r.class {
+				return true
+			}
+		}
+		return false
+	}
+	return true
+}
+
+// parseCSS returns the rules of a style sheet. Selectors other than a tag,
+// a class or an id, or a tag with a class or an id, are left out.
+func parseCSS(css string) []rule {
+	for {
+		i := strings.Index(css, "/*")
+		if i < 0 {
+			break
+		}
+		j := strings.Index(css[i+2:], "*/")
+		if j < 0 {
+			css = css[:i]
+			break
+		}
+		css = css[:i] + css[i+2+j+2:]
+	}
+
+	var rules []rule
+	for _, block := range strings.Split(css, "}") {
+		selectors, body, ok := strings.Cut(block, "{")
+		if !ok {
+			continue
+		}
+		decls := declarations(body)
+		for _, sel := range strings.Split(selectors, ",") {
+			sel = strings.TrimSpace(sel)
+			if sel == "" || strings.ContainsAny(sel, " >+~:[") {
+				continue
+			}
+			r := rule{decls: decls}
+			if i := strings.IndexAny(sel, ".#"); i >= 0 {
+				r.tag = sel[:i]
+				if sel[i] == '.' {
+					r.class = sel[i+1:]
+				} else {
+					r.id = sel[i+1:]
+				}
+			} else {
+				r.tag = sel
+			}
+			rules = append(rules, r)
+		}
+	}
+	return rules
+}
+
+// declarations returns the properties of a style attribute or a CSS rule,
+// such as "fill: red; stroke-width: 2".
+func declarations(s string) map[string]string {
+	decls := make(map[string]string)
+	for _, d := range strings.Split(s, ";") {
+		name, value, ok := strings.Cut(d, ":")
+		if !ok {
+			continue
+		}
+		value = strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(value), "!important"))
+		decls[strings.TrimSpace(name)] = value
+	}
+	return decls
+}
+
+// numbers returns the numbers of a list such as "0 0 100 50" or
+// "10,20 30,40".
+func numbers(s string) []float64 {
+	sc := scanner{s: s}
+	var nums []float64
+	for {
+		sc.skip()
+		v, ok := sc.number()
+		if !ok {
+			return nums
+		}
+		nums = append(nums, v)
+	}
+}
+
+// length returns the length s, such as "12", "4mm" or "50%", in user
+// units, with percentages of ref; 0 if s is not a length.
+func length(s string, ref float64) float64 {
+	sc := scanner{s: strings.TrimSpace(s)}
+	v, ok := sc.number()
+	if !ok {
+		return 0
+	}
+	switch strings.TrimSpace(sc.s[sc.pos:]) {
+	case "pt":
+		v *= 96.0 / 72
+	case "pc":
+		v *= 16
+	case "mm":
+		v *= 96 / 25.4
+	case "cm":
+		v *= 96 / 2.54
+	case "in":
+		v *= 96
+	case "em":
+		v *= 16
+	case "ex":
+		v *= 8
+	case "%":
+		v *= ref / 100
+	}
+	return v
+}
+
+// scanner reads the numbers and commands of path data and number lists.
+type scanner struct {
+	s   string
+	pos int
+}
+
+// skip skips white space and a comma.
+func (sc *scanner) skip() {
+	comma := false
+	for sc.pos < len(sc.s) {
+		switch c := sc.s[sc.pos]; {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+		case c == ',' && !comma:
+			comma = true
+		default:
+			return
+		}
+		sc.pos++
+	}
+}
+
+// number reads a number, such as "-1.5", ".5" or "1e-3"; in "1.5.5" it
+// reads 1.5, then .5.
+func (sc *scanner) number() (float64, bool) {
+	start, i := sc.pos, sc.pos
+	s := sc.s
+	if i < len(s) && (s[i] == '+' || s[i] == '-') {
+		i++
+	}
+	digits := false
+	for i < len(s) && s[i] >= '0' && s[i] <= '9' {
+		i, digits = i+1, true
+	}
+	if i < len(s) && s[i] == '.' {
+		i++
+		for i < len(s) && s[i] >= '0' && s[i] <= '9' {
+			i, digits = i+1, true
+		}
+	}
+	if !digits {
+		return 0, false
+	}
+	if i < len(s) && (s[i] == 'e' || s[i] == 'E') {
+		j := i + 1
+		if j < len(s) && (s[j] == '+' || s[j] == '-') {
+			j++
+		}
+		if j < len(s) && s[j] >= '0' && s[j] <= '9' {
+			for j < len(s) && s[j] >= '0' && s[j] <= '9' {
+				j++
+			}
+			i = j
+		}
+	}
+	v, err := strconv.ParseFloat(s[start:i], 64)
+	if err != nil || math.IsInf(v, 0) {
+		return 0, false
+	}
+	sc.pos = i
+	return v, true
+}
+
+// flag reads an arc flag, 0 or 1, which needs no space after it.
+func (sc *scanner) flag() (bool, bool) {
+	if sc.pos < len(sc.s) && (sc.s[sc.pos] == '0' || sc.s[sc.pos] == '1') {
+		sc.pos++
+		return sc.s[sc.pos-1] == '1', true
+	}
+	return false, false
+}
@@ -0,0 +1,208 @@
+package svg
+
+import (
+	"math"
+
+	"pdf-tutorial/gopdf/shape"
+)
+
+// pathData adds the path data d, the d attribute of a path, to p. Like a
+// browser, it draws the path up to the first error in d.
+func pathData(p *shape.Path, d string) {
+	sc := scanner{s: d}
+	var cmd byte
+	var ctrl shape.Point // Last control point, for S and T
+	var last byte        // Last command, in upper case
+	closed := false      // The last command was Z
+	for {
+		sc.skip()
+		if sc.pos >= len(sc.s) {
+			return
+		}
+		if c := sc.s[sc.pos]; isCommand(c) {
+			cmd = c
+			sc.pos++
+		} else if cmd == 0 {
+			return
+		}
+
+		cur := p.Current()
+		rel := cmd >= 'a'
+		// at reads a point, relative to the current point for a lower case
+		// command.
+		at := func() (shape.Point, bool) {
+			sc.skip()
+			x, ok := sc.number()
+			sc.skip()
+			y, ok2 := sc.number()
+			if rel {
+				x, y = x+cur.X, y+cur.Y
+			}
+			return shape.Point{X: x, Y: y}, ok && ok2
+		}
+		coord := func(base float64) (float64, bool) {
+			sc.skip()
+			v, ok := sc.number()
+			if rel {
+				v += base
+			}
+			return v, ok
+		}
+		// reopen starts a subpath at the start of the one Z closed, for a
+		// drawing command after Z.
+		reopen := func() {
+			if closed {
+				p.MoveTo(cur.X, cur.Y)
+			}
+		}
+
+		upper := cmd &^ 0x20
+		switch upper {
+		case 'M':
+			pt, ok := at()
+			if !ok {
+				return
+			}
+			p.MoveTo(pt.X, pt.Y)
+			// Points after a moveto are linetos.
+			cmd = 'L' | cmd&0x20
+		case 'L':
+			pt, ok := at()
+			if !ok {
+				return
+			}
+			reopen()
+			p.LineTo(pt.X, pt.Y)
+		case 'H':
+			x, ok := coord(cur.X)
+			if !ok {
+				return
+			}
+			reopen()
+			p.LineTo(x, cur.Y)
+		case 'V':
+			y, ok := coord(cur.Y)
+			if !ok {
+				return
+			}
+			reopen()
+			p.LineTo(cur.X, y)
+		case 'C', 'S':
+			c1 := cur
+			if upper == 'C' {
+				var ok bool
+				if c1, ok = at(); !ok {
+					return
+				}
+			} else if last == 'C' || last == 'S' {
+				c1 = shape.Point{X: 2*cur.X - ctrl.X, Y: 2*cur.Y - ctrl.Y}
+			}
+			c2, ok := at()
+			end, ok2 := at()
+			if !ok || !ok2 {
+				return
+			}
+			reopen()
+			p.CurveTo(c1.X, c1.Y, c2.X, c2.Y, end.X, end.Y)
+			ctrl = c2
+		case 'Q', 'T':
+			c := cur
+			if upper == 'Q' {
+				var ok bool
+				if c, ok = at(); !ok {
+					return
+				}
+			} else if last == 'Q' || last == 'T' {
+				c = shape.Point{X: 2*cur.X - ctrl.X, Y: 2*cur.Y - ctrl.Y}
+			}
+			end, ok := at()
+			if !ok {
+				return
+			}
+			reopen()
+			p.QuadTo(c.X, c.Y, end.X, end.Y)
+			ctrl = c
+		case 'A':
+			sc.skip()
+			rx, ok1 := sc.number()
+			sc.skip()
+			ry, ok2 := sc.number()
+			sc.skip()
+			rot, ok3 := sc.number()
+			sc.skip()
+			large, ok4 := sc.flag()
+			sc.skip()
+			sweep, ok5 := sc.flag()
+			end, ok6 := at()
+			if !(ok1 && ok2 && ok3 && ok4 && ok5 && ok6) {
+				return
+			}
+			reopen()
+			arc(p, cur, end, rx, ry, rot, large, sweep)
+		case 'Z':
+			// The next command starts at the start of the subpath, which
+			// is the current point after Close, and a number after Z is an
+			// error.
+			p.Close()
+			cmd = 0
+		default:
+			return
+		}
+		last = upper
+		closed = upper == 'Z'
+	}
+}
+
+// isCommand reports whether c is a command letter of path data.
+func isCommand(c byte) bool {
+	switch c &^ 0x20 {
+	case 'M', 'L', 'H', 'V', 'C', 'S', 'Q', 'T', 'A', 'Z':
+		return true
+	}
+	return false
+}
+
+// arc adds an SVG arc from p0 to p1 to p: a part of the ellipse with
+// radii rx and ry turned by rot degrees, the larger or the smaller part,
+// going clockwise (sweep) or not. It finds the center of the ellipse as in
+// the implementation notes of SVG 1.1.
+func arc(p *shape.Path, p0, p1 shape.Point, rx, ry, rot float64, large, sweep bool) {
+	rx, ry = math.Abs(rx), math.Abs(ry)
+	if p0 == p1 {
+		return
+	}
+	if rx == 0 || ry == 0 {
+		p.LineTo(p1.X, p1.Y)
+		return
+	}
+	sin, cos := math.Sincos(rot * math.Pi / 180)
+	dx, dy := (p0.X-p1.X)/2, (p0.Y-p1.Y)/2
+	x1 := cos*dx + sin*dy
+	y1 := -sin*dx + cos*dy
+
+	// Radii too small to reach are scaled up until they do.
+	if l := x1*x1/(rx*rx) + y1*y1/(ry*ry); l > 1 {
+		rx, ry = rx*math.Sqrt(l), ry*math.Sqrt(l)
+	}
+	num := rx*rx*ry*ry - rx*rx*y1*y1 - ry*ry*x1*x1
+	den := rx*rx*y1*y1 + ry*ry*x1*x1
+	k := math.Sqrt(max(num/den, 0))
+	if large == sweep {
+		k = -k
+	}
+	cx1, cy1 := k*rx*y1/ry, -k*ry*x1/rx
+	cx := cos*cx1 - sin*cy1 + (p0.X+p1.X)/2
+	cy := sin*cx1 + cos*cy1 + (p0.Y+p1.Y)/2
+
+	angle := func(ux, uy float64) float64 {
+		return math.Atan2(uy, ux) * 180 / math.Pi
+	}
+	start := angle((x1-cx1)/rx, (y1-cy1)/ry)
+	delta := angle((-x1-cx1)/rx, (-y1-cy1)/ry) - start
+	if sweep && delta < 0 {
+		delta += 360
+	} else if !sweep && delta > 0 {
+		delta -= 360
+	}
+	p.EllipticArc(cx, cy, rx, ry, rot, start, start+delta)
+}
@@ -0,0 +1,223 @@
+package svg
+
+import (
+	"image/color"
+	"strconv"
+	"strings"
+
+	"pdf-tutorial/gopdf/shape"
+)
+
+// paint is the value of fill or stroke.
+type paint struct {
+	none    bool
+	color   color.Color
+	current bool   // currentColor
+	url     string // Id of a gradient, with color as the fallback
+}
+
+// style is the computed style of an element.
+type style struct {
+	fill, stroke  paint
+	fillOpacity   float64
+	strokeOpacity float64
+	opacity       float64 // Opacity of the element and its groups, multiplied
+	width         float64
+	fillRule      shape.FillRule
+	cap           shape.Cap
+	join          shape.Join
+	dash          []float64
+	dashOffset    float64
+	color         color.Color
+	hidden        bool // display: none
+}
+
+// defaultStyle returns the style of the root of an image before its own
+// properties: black fill, no stroke.
+func defaultStyle() style {
+	return style{
+		fill:          paint{color: color.Black},
+		stroke:        paint{none: true},
+		fillOpacity:   1,
+		strokeOpacity: 1,
+		opacity:       1,
+		width:         1,
+		color:         color.Black,
+	}
+}
+
+// properties are the presentation attributes drawn by the package.
+var properties = []string{
+	"fill", "stroke", "fill-opacity", "stroke-opacity", "opacity",
+	"stroke-width", "fill-rule", "stroke-linecap", "stroke-linejoin",
+	"stroke-dasharray", "stroke-dashoffset", "color", "display",
+}
+
+// inherit returns the style of n, whose parent has the style parent: the
+// properties of n, from its attributes, the rules of the image and its
+// style attribute, in that order of precedence, or else those of parent.
+func inherit(parent style, n *node, img *Image) style {
+	props := make(map[string]string)
+	for _, p := range properties {
+		if v, ok := n.attrs[p]; ok {
+			props[p] = v
+		}
+	}
+	for _, r := range img.rules {
+		if r.matches(n) {
+			for k, v := range r.decls {
+				props[k] = v
+			}
+		}
+	}
+	for k, v := range declarations(n.attrs["style"]) {
+		props[k] = v
+	}
+
+	s := parent
+	s.hidden = false
+	if v, ok := props["color"]; ok && v != "inherit" {
+		if c, ok := parseColor(v); ok {
+			s.color = c
+		}
+	}
+	for name, v := range props {
+		v = strings.TrimSpace(v)
+		if v == "inherit" {
+			continue
+		}
+		switch name {
+		case "fill":
+			s.fill = parsePaint(v, parent.fill)
+		case "stroke":
+			s.stroke = parsePaint(v, parent.stroke)
+		case "fill-opacity":
+			s.fillOpacity = opacity(v, parent.fillOpacity)
+		case "stroke-opacity":
+			s.strokeOpacity = opacity(v, parent.strokeOpacity)
+		case "opacity":
+			s.opacity = parent.opacity * opacity(v, 1)
+		case "stroke-width":
+			s.width = length(v, 0)
+		case "fill-rule":
+			s.fillRule = shape.NonZero
+			if v == "evenodd" {
+				s.fillRule = shape.EvenOdd
+			}
+		case "stroke-linecap":
+			s.cap = map[string]shape.Cap{"round": shape.RoundCap, "square": shape.SquareCap}[v]
+		case "stroke-linejoin":
+			s.join = map[string]shape.Join{"round": shape.RoundJoin, "bevel": shape.BevelJoin}[v]
+		case "stroke-dasharray":
+			s.dash = nil
+			if v != "none" {
+				s.dash = numbers(strings.ReplaceAll(v, "px", ""))
+				if len(s.dash)%2 == 1 {
+					s.dash = append(s.dash, s.dash...)
+				}
+			}
+		case "stroke-dashoffset":
+			s.dashOffset = length(v, 0)
+		case "display":
+			s.hidden = v == "none"
+		}
+	}
+	return s
+}
+
+// parsePaint returns the paint of a fill or stroke value, such as "none",
+// "#f80", "currentColor" or "url(#fade) red"; an invalid value leaves the
+// parent's paint.
+func parsePaint(v string, parent paint) paint {
+	switch v {
+	case "none", "transparent":
+		return paint{none: true}
+	case "currentColor":
+		return paint{current: true}
+	}
+	if rest, ok := strings.CutPrefix(v, "url("); ok {
+		id, fallback, _ := strings.Cut(rest, ")")
+		id = strings.Trim(strings.TrimSpace(id), `'"`)
+		p := paint{url: strings.TrimPrefix(id, "#")}
+		fallback = strings.TrimSpace(fallback)
+		if c, ok := parseColor(fallback); ok {
+			p.color = c
+		} else if fallback == "" || fallback == "none" {
+			p.none = true
+		}
+		return p
+	}
+	if c, ok := parseColor(v); ok {
+		return paint{color: c}
+	}
+	return parent
+}
+
+// opacity returns the opacity v, a number or a percentage, clamped to 0
+// to 1, or def if v is not one.
+func opacity(v string, def float64) float64 {
+	if _, ok := (&scanner{s: strings.TrimSpace(v)}).number(); !ok {
+		return def
+	}
+	return min(max(length(v, 1), 0), 1)
+}
+
+// parseColor returns the color c, such as "#f80", "#ff8800",
+// "rgb(255, 136, 0)", "rgb(100%, 50%, 0%)" or "orange".
+func parseColor(c string) (color.Color, bool) {
+	c = strings.ToLower(strings.TrimSpace(c))
+	if hex, ok := strings.CutPrefix(c, "#"); ok {
+		if len(hex) == 3 || len(hex) == 4 {
+			hex = string([]byte{hex[0], hex[0], hex[1], hex[1], hex[2], hex[2]})
+		}
+		if len(hex) == 8 {
+			hex = hex[:6]
+		}
+		v, err := strconv.ParseUint(hex, 16, 32)
+		if len(hex) != 6 || err != nil {
+			return nil, false
+		}
+		return color.RGBA{uint8(v >> 16), uint8(v >> 8), uint8(v), 255}, true
+	}
+	if args, ok := strings.CutPrefix(c, "rgb"); ok {
+		args = strings.TrimPrefix(args, "a")
+		args = strings.TrimSuffix(strings.TrimPrefix(strings.TrimSpace(args), "("), ")")
+		parts := strings.FieldsFunc(args, func(r rune) bool { return r == ',' || r == ' ' || r == '/' })
+		if len(parts) < 3 {
+			return nil, false
+		}
+		var rgb [3]uint8
+		for i, p := range parts[:3] {
+			rgb[i] = uint8(min(max(length(p, 255), 0), 255) + 0.5)
+		}
+		return color.RGBA{rgb[0], rgb[1], rgb[2], 255}, true
+	}
+	if v, ok := namedColors[c]; ok {
+		return color.RGBA{uint8(v >> 16), uint8(v >> 8), uint8(v), 255}, true
+	}
+	return nil, false
+}
+
+// namedColors are the usual CSS color names.
+var namedColors = map[string]uint32{
+	"black": 0x000000, "white": 0xffffff, "red": 0xff0000, "lime": 0x00ff00,
+	"blue": 0x0000ff, "yellow": 0xffff00, "cyan": 0x00ffff, "aqua": 0x00ffff,
+	"magenta": 0xff00ff, "fuchsia": 0xff00ff, "silver": 0xc0c0c0, "gray": 0x808080,
+	"grey": 0x808080, "maroon": 0x800000, "olive": 0x808000, "green": 0x008000,
+	"purple": 0x800080, "teal": 0x008080, "navy": 0x000080, "orange": 0xffa500,
+	"gold": 0xffd700, "pink": 0xffc0cb, "brown": 0xa52a2a, "coral": 0xff7f50,
+	"crimson": 0xdc143c, "darkblue": 0x00008b, "darkgray": 0xa9a9a9,
+	"darkgrey": 0xa9a9a9, "darkgreen": 0x006400, "darkred": 0x8b0000,
+	"darkorange": 0xff8c00, "dimgray": 0x696969, "dimgrey": 0x696969,
+	"gainsboro": 0xdcdcdc, "indigo": 0x4b0082, "ivory": 0xfffff0,
+	"khaki": 0xf0e68c, "lavender": 0xe6e6fa, "lightblue": 0xadd8e6,
+	"lightgray": 0xd3d3d3, "lightgrey": 0xd3d3d3, "lightgreen": 0x90ee90,
+	"limegreen": 0x32cd32, "orangered": 0xff4500, "royalblue": 0x4169e1,
+	"salmon": 0xfa8072, "skyblue": 0x87ceeb, "slategray": 0x708090,
+	"slategrey": 0x708090, "steelblue": 0x4682b4, "tomato": 0xff6347,
+	"turquoise": 0x40e0d0, "violet": 0xee82ee, "whitesmoke": 0xf5f5f5,
+	"beige": 0xf5f5dc, "chocolate": 0xd2691e, "firebrick": 0xb22222,
+	"forestgreen": 0x228b22, "goldenrod": 0xdaa520, "hotpink": 0xff69b4,
+	"midnightblue": 0x191970, "seagreen": 0x2e8b57, "sienna": 0xa0522d,
+	"tan": 0xd2b48c, "wheat": 0xf5deb3,
+}
@@ -0,0 +1,165 @@
+// Package svg draws SVG images, such as logos and icons, on a gopdf page
+// as vector content, so they stay sharp at any zoom and print size.
+//
+//	img, err := svg.ParseFile("images/logo.svg")
+//	...
+//	err = img.Draw(&pdf, page.Box{X: 50, Y: 50, W: 120})
+//
+// It draws a practical subset of SVG 1.1: the elements path, rect, circle,
+// ellipse, line, polyline, polygon and g, transforms, fill and stroke with
+// their opacity, fill rule, line caps, joins and dashes, and linear
+// gradients. Styles may be given as attributes, in a style attribute, or
+// in a <style> element with rules for tags, classes and ids. Text, images,
+// <use>, clipping, masks and filters are left out; a radial gradient
+// paints its last color.
+package svg
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/signintech/gopdf"
+
+	"pdf-tutorial/gopdf/page"
+	"pdf-tutorial/gopdf/shape"
+)
+
+var (
+	// ErrNotSVG is returned by Parse for XML that is not an SVG image.
+	ErrNotSVG = errors.New("svg: not an SVG image")
+
+	// ErrNoSize is returned by Draw for an image with neither a viewBox nor
+	// a width and a height, as it has no size to fit into the box.
+	ErrNoSize = errors.New("svg: image has no viewBox, width or height")
+)
+
+// Image is a parsed SVG image.
+type Image struct {
+	root    *node
+	width   float64 // Width of the image, in user units, 0 if not given
+	height  float64
+	viewBox *[4]float64 // min x, min y, width and height
+	align   string      // preserveAspectRatio
+
+	ids   map[string]*node
+	rules []rule // Rules of the <style> elements
+}
+
+// ParseFile reads an SVG image from the file name.
+func ParseFile(name string) (*Image, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, fmt.Errorf("svg: %w", err)
+	}
+	defer f.Close()
+	return Parse(f)
+}
+
+// Parse reads an SVG image.
+func Parse(r io.Reader) (*Image, error) {
+	root, err := parseXML(r)
+	if err != nil {
+		return nil, fmt.Errorf("svg: %w", err)
+	}
+	if root == nil || root.name != "svg" {
+		return nil, ErrNotSVG
+	}
+
+	img := &Image{root: root, ids: make(map[string]*node)}
+	var walk func(n *node)
+	walk = func(n *node) {
+		if id := n.attrs["id"]; id != "" {
+			img.ids[id] = n
+		}
+		if n.name == "style" {
+			img.rules = append(img.rules, parseCSS(n.text)...)
+		}
+		for _, c := range n.children {
+			walk(c)
+		}
+	}
+	walk(root)
+
+	if vb := numbers(root.attrs["viewBox"]); len(vb) == 4 && vb[2] > 0 && vb[3] > 0 {
+		img.viewBox = &[4]float64{vb[0], vb[1], vb[2], vb[3]}
+	}
+	img.width = length(root.attrs["width"], 0)
+	img.height = length(root.attrs["height"], 0)
+	img.align = root.attrs["preserveAspectRatio"]
+	return img, nil
+}
+
+// Size returns the width and height of img in user units, which are CSS
+// pixels, 1/96 inch: the width and height attributes of the image, or the
+// size of its viewBox. It is 0, 0 if the image has neither.
+func (img *Image) Size() (w, h float64) {
+	w, h = img.width, img.height
+	if vb := img.viewBox; vb != nil {
+		switch {
+		case w == 0 && h == 0:
+			w, h = vb[2], vb[3]
+		case w == 0:
+			w = h * vb[2] / vb[3]
+		case h == 0:
+			h = w * vb[3] / vb[2]
+		}
+	}
+	return w, h
+}
+
+// Draw draws img into box on the current page of pdf, scaled to fit and
+// centered, unless the image's preserveAspectRatio says otherwise. If box
+// has no width or no height, it gets the one that keeps the proportions of
+// the image; if it has neither, it is the size of the image, at 96 user
+// units an inch.
+func (img *Image) Draw(pdf *gopdf.GoPdf, box page.Box) error {
+	w, h := img.Size()
+	if w <= 0 || h <= 0 {
+		return ErrNoSize
+	}
+	switch {
+	case box.W <= 0 && box.H <= 0:
+		box.W, box.H = pdf.PointsToUnits(w*0.75), pdf.PointsToUnits(h*0.75)
+	case box.W <= 0:
+		box.W = box.H * w / h
+	case box.H <= 0:
+		box.H = box.W * h / w
+	}
+
+	// The viewBox, or else the size of the image, fills the box.
+	view := [4]float64{0, 0, w, h}
+	if img.viewBox != nil {
+		view = *img.viewBox
+	}
+	m := shape.Translate(-view[0], -view[1]).Then(fit(view[2], view[3], box, img.align))
+
+	d := &drawer{img: img, pdf: pdf, view: view}
+	return d.group(img.root, m, inherit(defaultStyle(), img.root, img))
+}
+
+// fit returns the transformation of a w by h view with its top left corner
+// at 0, 0 into box, aligned as preserveAspectRatio says.
+func fit(w, h float64, box page.Box, preserve string) shape.Matrix {
+	sx, sy := box.W/w, box.H/h
+	align, _, _ := strings.Cut(strings.TrimSpace(preserve), " ")
+	if align == "none" {
+		return shape.Scale(sx, sy).Then(shape.Translate(box.X, box.Y))
+	}
+	s := min(sx, sy)
+	dx, dy := box.W-w*s, box.H-h*s
+	x, y := box.X+dx/2, box.Y+dy/2
+	if strings.HasPrefix(align, "xMin") {
+		x = box.X
+	} else if strings.HasPrefix(align, "xMax") {
+		x = box.X + dx
+	}
+	if strings.HasSuffix(align, "YMin") {
+		y = box.Y
+	} else if strings.HasSuffix(align, "YMax") {
+		y = box.Y + dy
+	}
+	return shape.Scale(s, s).Then(shape.Translate(x, y))
+}
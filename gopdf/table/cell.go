@@ -0,0 +1,74 @@
+package table
+
+import (
+	"image/color"
+
+	"pdf-tutorial/gopdf/fonts"
+)
+
+// Cell is a table cell with its own layout and style. Zero fields take
+// their value from the cell's column or from the table's Options.
+type Cell struct {
+	Text string
+
+	ColSpan int // Number of columns the cell covers (1)
+	RowSpan int // Number of rows the cell covers (1)
+
+	Align   Align    // Horizontal alignment of the lines (the column's)
+	VAlign  VAlign   // Vertical alignment in the row (Top)
+	Padding *Padding // Space around the text (Options.Padding on every side)
+	Border  Border   // Sides with a border (Options.Border)
+	Fill    color.Color
+
+	// Font of the text. Style is added to the style of the row, so a
+	// header cell is always bold.
+	Family string
+	Style  fonts.Style
+	Size   float64
+	Color  color.Color
+}
+
+// Align is the horizontal alignment of the text in a cell.
+type Align int
+
+const (
+	AlignDefault Align = iota // The column's alignment, or Left
+	Left
+	Center
+	Right
+)
+
+// VAlign is the vertical alignment of the text in a cell that is taller
+// than its text.
+type VAlign int
+
+const (
+	Top VAlign = iota
+	Middle
+	Bottom
+)
+
+// Border is a set of sides of a cell that get a border.
+type Border int
+
+const (
+	BorderTop Border = 1 << iota
+	BorderRight
+	BorderBottom
+	BorderLeft
+
+	// BorderNone draws no border. (The zero Border is the default.)
+	BorderNone
+
+	BorderAll = BorderTop | BorderRight | BorderBottom | BorderLeft
+)
+
+// Padding is the space between the edges of a cell and its text.
+type Padding struct {
+	Top, Right, Bottom, Left float64
+}
+
+// Pad returns a Padding of p on every side.
+func Pad(p float64) *Padding {
+	return &Padding{p, p, p, p}
+}
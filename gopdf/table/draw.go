@@ -0,0 +1,529 @@
+package table
+
+import (
+	"errors"
+	"image/color"
+	"slices"
+
+	"pdf-tutorial/gopdf/flow"
+	"pdf-tutorial/gopdf/fonts"
+	"pdf-tutorial/gopdf/richtext"
+
+	"github.com/signintech/gopdf"
+)
+
+// Draw draws the table at the cursor of f, from its left edge, and leaves
+// the cursor below the table. Rows joined by a cell spanning them are kept
+// on one page if they fit on one; otherwise the table continues on a new
+// page, below a repeated header, and a row taller than a whole page is
+// split between its lines. Missing glyphs are reported as by
+// richtext.Missing.
+func (t *Table) Draw(f *flow.Flow, reg *fonts.Registry) error {
+	head, nh := place(t.header)
+	body, nb := place(t.rows)
+	n := max(nh, nb)
+	if n == 0 {
+		return nil
+	}
+	opts := t.opts
+	opts.setDefaults()
+	d := &drawer{t: t, f: f, reg: reg, pdf: reg.PDF(), opts: opts, x: f.X()}
+	for _, p := range head {
+		p.header = true
+	}
+
+	// Size the columns from the content: cells in one column first, then
+	// cells spanning several widen those columns if they need more room.
+	natural, word := make([]float64, n), make([]float64, n)
+	cells := slices.Concat(head, body)
+	slices.SortStableFunc(cells, func(a, b *placed) int { return a.cols - b.cols })
+	for _, p := range cells {
+		p.pad = d.padding(p)
+		line, w, err := measure(reg, p.Text, d.run(p))
+		if err != nil {
+			return err
+		}
+		pads := p.pad.Left + p.pad.Right
+		spread(natural[p.col:p.col+p.cols], line+pads)
+		spread(word[p.col:p.col+p.cols], w+pads)
+	}
+	d.widths = t.widths(natural, word, f.Width())
+
+	var err error
+	if d.head, err = d.section(head, len(t.header), true); err != nil {
+		return err
+	}
+	if d.body, err = d.section(body, len(t.rows), false); err != nil {
+		return err
+	}
+
+	err = d.drawRows()
+	d.pdf.SetStrokeColor(0, 0, 0)
+	d.pdf.SetFillColor(0, 0, 0)
+	if err == nil {
+		err = d.missing.Err()
+	}
+	return err
+}
+
+// placed is a cell at its place in the grid of the header or the body.
+type placed struct {
+	Cell
+	row, col   int // First row and column covered
+	rows, cols int // Number of rows and columns covered
+	header     bool
+	pad        Padding
+	lines      []richtext.Line // Laid out at x 0 and y 0
+	next       int             // First line not drawn yet
+}
+
+// place gives every cell of rows its place in a grid, as in HTML: each
+// cell goes in the first column of its row that is not covered by a cell
+// spanning down from a row above. It returns the cells and the number of
+// columns covered.
+func place(rows [][]Cell) ([]*placed, int) {
+	var cells []*placed
+	taken := make(map[[2]int]bool)
+	n := 0
+	for r, row := range rows {
+		c := 0
+		for _, cell := range row {
+			for taken[[2]int{r, c}] {
+				c++
+			}
+			p := &placed{Cell: cell, row: r, col: c, rows: max(cell.RowSpan, 1), cols: max(cell.ColSpan, 1)}
+			p.rows = min(p.rows, len(rows)-r)
+			for dr := range p.rows {
+				for dc := range p.cols {
+					taken[[2]int{r + dr, c + dc}] = true
+				}
+			}
+			cells = append(cells, p)
+			c += p.cols
+			n = max(n, c)
+		}
+	}
+	return cells, n
+}
+
+// spread widens widths equally until they add up to at least need.
+func spread(widths []float64, need float64) {
+	if have := sum(widths); need > have {
+		for i := range widths {
+			widths[i] += (need - have) / float64(len(widths))
+		}
+	}
+}
+
+// section is the laid out header or body of a table.
+type section struct {
+	cells   []*placed
+	heights []float64     // Height of every row
+	fills   []color.Color // Background of every row, nil for none
+	started []bool        // Rows split between pages
+}
+
+// band is the part of a row drawn on the current page.
+type band struct {
+	row   int
+	y, h  float64
+	end   bool // The rest of the row is in this band
+	force bool // Split at the top of a page: draw at least one line
+}
+
+// drawer draws one table into a flow.
+type drawer struct {
+	t          *Table
+	f          *flow.Flow
+	reg        *fonts.Registry
+	pdf        *gopdf.GoPdf
+	opts       Options
+	x          float64   // Left edge of the table
+	widths     []float64 // Column widths
+	head, body *section
+	bands      []band // Bands of the body on the current page
+	missing    richtext.Missing
+}
+
+// padding returns the padding of p.
+func (d *drawer) padding(p *placed) Padding {
+	if p.Padding != nil {
+		return *p.Padding
+	}
+	return *Pad(d.opts.Padding)
+}
+
+// run returns the font and color of the text of p.
+func (d *drawer) run(p *placed) richtext.Run {
+	run := richtext.Run{Text: p.Text, Family: p.Family, Style: p.Style, Size: p.Size, Color: p.Color}
+	if run.Family == "" {
+		run.Family = d.opts.Family
+	}
+	if run.Size <= 0 {
+		run.Size = d.opts.Size
+	}
+	if p.header {
+		run.Style |= fonts.Bold
+	}
+	if run.Color == nil {
+		run.Color = d.opts.Color
+		if p.header {
+			run.Color = d.opts.HeaderColor
+		}
+	}
+	return run
+}
+
+// width returns the width of the columns p covers.
+func (d *drawer) width(p *placed) float64 {
+	return sum(d.widths[p.col : p.col+p.cols])
+}
+
+// section lays out the cells of the header or the body, n rows, and sizes
+// the rows.
+func (d *drawer) section(cells []*placed, n int, header bool) (*section, error) {
+	s := &section{cells: cells, heights: make([]float64, n), fills: make([]color.Color, n), started: make([]bool, n)}
+	for r := range s.fills {
+		switch {
+		case header:
+			s.fills[r] = d.opts.HeaderFill
+		case r%2 == 1:
+			s.fills[r] = d.opts.Stripe
+		}
+	}
+
+	for _, p := range cells {
+		if p.Text == "" {
+			continue
+		}
+		// The widths were summed from the measured text, so allow for
+		// rounding: text as wide as its column must not wrap.
+		run := d.run(p)
+		opts := richtext.Options{
+			Width:      max(d.width(p)-p.pad.Left-p.pad.Right, 1) + 1e-6,
+			LineHeight: d.opts.LineHeight * run.Size / d.opts.Size,
+			Family:     run.Family,
+			Size:       run.Size,
+		}
+		lines, err := richtext.Layout(d.reg, []richtext.Run{run}, 0, 0, opts)
+		if err != nil {
+			return nil, err
+		}
+		p.lines = lines
+	}
+
+	// A row is as tall as its tallest cell, and a cell spanning rows that
+	// are not tall enough for it makes the last of them taller.
+	byRows := slices.Clone(cells)
+	slices.SortStableFunc(byRows, func(a, b *placed) int { return a.rows - b.rows })
+	for _, p := range byRows {
+		need := linesHeight(p.lines) + p.pad.Top + p.pad.Bottom
+		if have := sum(s.heights[p.row : p.row+p.rows]); need > have {
+			s.heights[p.row+p.rows-1] += need - have
+		}
+	}
+	return s, nil
+}
+
+// group returns the end of the rows from r on that are joined by cells
+// spanning them: the index after the last of them.
+func (s *section) group(r int) int {
+	end := r + 1
+	for row := r; row < end; row++ {
+		for _, p := range s.cells {
+			if p.row == row {
+				end = max(end, p.row+p.rows)
+			}
+		}
+	}
+	return end
+}
+
+// rest returns the height of what is left of row r: all of it, or, for a
+// row split between pages, the lines not drawn yet of the cells ending in
+// it.
+func (s *section) rest(r int) float64 {
+	if !s.started[r] {
+		return s.heights[r]
+	}
+	h := 0.0
+	for _, p := range s.cells {
+		if p.row+p.rows-1 == r {
+			h = max(h, linesHeight(p.lines[p.next:])+p.pad.Top+p.pad.Bottom)
+		}
+	}
+	return h
+}
+
+// drawRows draws the header and the body, one page at a time.
+func (d *drawer) drawRows() error {
+	s := d.body
+	// Keep the header with the first rows.
+	h := sum(d.head.heights)
+	if len(s.heights) > 0 {
+		h += sum(s.heights[:s.group(0)])
+	}
+	if _, err := d.f.Ensure(h); err != nil && !errors.Is(err, flow.ErrNoRoom) {
+		return err
+	}
+	if err := d.header(); err != nil {
+		return err
+	}
+
+	for r := 0; r < len(s.heights); {
+		end := s.group(r)
+		if h := sum(s.heights[r:end]); h > d.f.Remaining() && h <= d.pageRoom() && !d.atTop() {
+			if err := d.newPage(); err != nil {
+				return err
+			}
+		}
+		for ; r < end; r++ {
+			for {
+				h, room := s.rest(r), d.f.Remaining()
+				if h <= room {
+					d.add(band{row: r, h: h, end: true})
+					break
+				}
+				if !d.atTop() && h <= d.pageRoom() {
+					if err := d.newPage(); err != nil {
+						return err
+					}
+					continue
+				}
+				// The row does not fit on a whole page: draw what fits
+				// and continue on the next.
+				s.started[r] = true
+				d.add(band{row: r, h: room, force: d.atTop()})
+				if err := d.newPage(); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return d.flush(s, d.bands)
+}
+
+// add adds a band of the body at the cursor and moves the cursor below it.
+// The bands of a page are drawn together by newPage or at the end, once
+// the height of every cell on the page is known.
+func (d *drawer) add(b band) {
+	b.y = d.f.Y()
+	d.bands = append(d.bands, b)
+	d.f.SetY(b.y + b.h)
+}
+
+// atTop reports whether nothing but the header has been drawn on this
+// page, so that a new page would not have more room.
+func (d *drawer) atTop() bool {
+	return d.f.Y() <= d.f.Top()+sum(d.head.heights)+1e-6
+}
+
+// pageRoom returns the height a page has for the body.
+func (d *drawer) pageRoom() float64 {
+	return d.f.Bottom() - d.f.Top() - sum(d.head.heights)
+}
+
+// newPage draws the body on this page and continues the table on a new
+// page, below the header.
+func (d *drawer) newPage() error {
+	if err := d.flush(d.body, d.bands); err != nil {
+		return err
+	}
+	d.bands = d.bands[:0]
+	if err := d.f.NewPage(); err != nil {
+		return err
+	}
+	return d.header()
+}
+
+// header draws the header rows at the cursor.
+func (d *drawer) header() error {
+	s := d.head
+	bands := make([]band, len(s.heights))
+	y := d.f.Y()
+	for r, h := range s.heights {
+		bands[r] = band{row: r, y: y, h: h, end: true}
+		y += h
+	}
+	for _, p := range s.cells {
+		p.next = 0
+	}
+	d.f.SetY(y)
+	return d.flush(s, bands)
+}
+
+// segment is the part of a cell on the current page.
+type segment struct {
+	p     *placed
+	x, y  float64
+	w, h  float64
+	last  bool // The rest of the cell is in this segment
+	force bool
+}
+
+// flush draws the cells of s in bands: all backgrounds first, so that the
+// fill of a cell never covers the border of its neighbor, then the
+// borders, then the text.
+func (d *drawer) flush(s *section, bands []band) error {
+	if len(bands) == 0 {
+		return nil
+	}
+	byRow := make(map[int]band, len(bands))
+	for _, b := range bands {
+		byRow[b.row] = b
+	}
+
+	var segs []segment
+	for _, p := range s.cells {
+		var first, last band
+		found := false
+		for r := p.row; r < p.row+p.rows; r++ {
+			if b, ok := byRow[r]; ok {
+				if !found {
+					first, found = b, true
+				}
+				last = b
+			}
+		}
+		if !found {
+			continue
+		}
+		end, ok := byRow[p.row+p.rows-1]
+		segs = append(segs, segment{
+			p: p,
+			x: d.x + sum(d.widths[:p.col]), y: first.y,
+			w: d.width(p), h: last.y + last.h - first.y,
+			last:  ok && end.end,
+			force: ok && end.force,
+		})
+	}
+
+	for _, sg := range segs {
+		fill := sg.p.Fill
+		if fill == nil {
+			fill = s.fills[sg.p.row]
+		}
+		if fill != nil {
+			d.pdf.SetFillColor(richtext.RGB(fill))
+			d.pdf.RectFromUpperLeftWithStyle(sg.x, sg.y, sg.w, sg.h, "F")
+		}
+	}
+	d.pdf.SetStrokeColor(richtext.RGB(d.opts.BorderColor))
+	d.pdf.SetLineWidth(d.opts.BorderWidth)
+	for _, sg := range segs {
+		d.border(sg)
+	}
+	for _, sg := range segs {
+		if err := d.text(sg); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// border draws the borders of a segment on the sides its cell has them.
+func (d *drawer) border(sg segment) {
+	sides := sg.p.Border
+	if sides == 0 {
+		sides = d.opts.Border
+	}
+	if sides&BorderNone != 0 {
+		return
+	}
+	if sides == BorderAll {
+		d.pdf.RectFromUpperLeftWithStyle(sg.x, sg.y, sg.w, sg.h, "D")
+		return
+	}
+	x0, y0, x1, y1 := sg.x, sg.y, sg.x+sg.w, sg.y+sg.h
+	if sides&BorderTop != 0 {
+		d.pdf.Line(x0, y0, x1, y0)
+	}
+	if sides&BorderRight != 0 {
+		d.pdf.Line(x1, y0, x1, y1)
+	}
+	if sides&BorderBottom != 0 {
+		d.pdf.Line(x0, y1, x1, y1)
+	}
+	if sides&BorderLeft != 0 {
+		d.pdf.Line(x0, y0, x0, y1)
+	}
+}
+
+// text draws the lines of the cell of a segment that fit in it, or all
+// its lines left if the rest of the cell is in the segment, aligned as the
+// cell or its column asks. Vertical alignment applies to the last segment
+// of a cell; the others are filled from the top.
+func (d *drawer) text(sg segment) error {
+	p := sg.p
+	room := sg.h - p.pad.Top - p.pad.Bottom
+	lines := p.lines[p.next:]
+	if !sg.last {
+		n, used := 0, 0.0
+		for n < len(lines) && used+lines[n].Height <= room {
+			used += lines[n].Height
+			n++
+		}
+		if n == 0 && sg.force && len(lines) > 0 {
+			n = 1
+		}
+		lines = lines[:n]
+	}
+	p.next += len(lines)
+
+	y := sg.y + p.pad.Top
+	if sg.last {
+		switch p.VAlign {
+		case Middle:
+			y += (room - linesHeight(lines)) / 2
+		case Bottom:
+			y += room - linesHeight(lines)
+		}
+	}
+	align := p.Align
+	if align == AlignDefault {
+		align = d.t.column(p.col).Align
+	}
+	inner := sg.w - p.pad.Left - p.pad.Right
+	for _, l := range lines {
+		x := sg.x + p.pad.Left
+		switch align {
+		case Center:
+			x += (inner - l.Width) / 2
+		case Right:
+			x += inner - l.Width
+		}
+		l.Y = y + l.Height*0.75
+		l.Fragments = slices.Clone(l.Fragments)
+		for f := range l.Fragments {
+			l.Fragments[f].X += x
+		}
+		if err := d.draw([]richtext.Line{l}); err != nil {
+			return err
+		}
+		y += l.Height
+	}
+	return nil
+}
+
+// draw draws lines and records a missing glyphs error.
+func (d *drawer) draw(lines []richtext.Line) error {
+	return d.missing.Note(richtext.Draw(d.reg, lines, richtext.Options{Color: d.opts.Color}))
+}
+
+// linesHeight returns the height of laid out lines.
+func linesHeight(lines []richtext.Line) float64 {
+	h := 0.0
+	for _, l := range lines {
+		h += l.Height
+	}
+	return h
+}
+
+// sum returns the sum of values.
+func sum(values []float64) float64 {
+	total := 0.0
+	for _, v := range values {
+		total += v
+	}
+	return total
+}
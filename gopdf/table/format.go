@@ -0,0 +1,77 @@
+package table
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// FormatNumber formats v with a pattern in the style of spreadsheets:
+// digits are written as "0" (always shown) and "#" (shown if not zero),
+// a "," in the digits groups thousands and anything before or after the
+// digits is kept, so
+//
+//	FormatNumber(1234.5, "$#,##0.00") // "$1,234.50"
+//	FormatNumber(12.345, "0.# %")     // "12.3 %"
+//	FormatNumber(-12, "#,##0 kg")     // "-12 kg"
+//
+// A pattern with a fmt verb is a fmt format instead, e.g. "%.1f%%", and an
+// empty pattern writes v with as many digits as it needs.
+func FormatNumber(v float64, pattern string) string {
+	if pattern == "" {
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	}
+	if verb.MatchString(pattern) {
+		return fmt.Sprintf(pattern, v)
+	}
+	first := strings.IndexAny(pattern, "0#")
+	if first < 0 {
+		return pattern
+	}
+	last := strings.LastIndexAny(pattern, "0#")
+	prefix, digits, suffix := pattern[:first], pattern[first:last+1], pattern[last+1:]
+
+	intPart, frac, _ := strings.Cut(digits, ".")
+	minDec := strings.Count(frac, "0")
+	maxDec := minDec + strings.Count(frac, "#")
+
+	s := strconv.FormatFloat(math.Abs(v), 'f', maxDec, 64)
+	whole, dec, _ := strings.Cut(s, ".")
+	for len(dec) > minDec && strings.HasSuffix(dec, "0") {
+		dec = dec[:len(dec)-1]
+	}
+	if minInt := strings.Count(intPart, "0"); whole == "0" && minInt == 0 {
+		whole = ""
+	}
+	if strings.Contains(intPart, ",") {
+		whole = group(whole)
+	}
+	s = whole
+	if dec != "" {
+		s += "." + dec
+	}
+	if s == "" {
+		s = "0"
+	}
+	if v < 0 && strings.Trim(s, "0.,") != "" {
+		prefix = "-" + prefix
+	}
+	return prefix + s + suffix
+}
+
+// verb matches a fmt verb for a number.
+var verb = regexp.MustCompile(`%[-+# 0]*\d*(\.\d*)?[bdeEfFgGvxX]`)
+
+// group puts a comma between every three digits of whole, from the right.
+func group(whole string) string {
+	var b strings.Builder
+	for i, r := range whole {
+		if i > 0 && (len(whole)-i)%3 == 0 {
+			b.WriteByte(',')
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
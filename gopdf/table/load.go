@@ -0,0 +1,387 @@
+package table
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"pdf-tutorial/gopdf/fonts"
+)
+
+// ErrUnknownField is returned by the loaders for a Field whose Name is
+// not in the data.
+var ErrUnknownField = errors.New("table: unknown field")
+
+// Field is a column of a table filled by a loader.
+type Field struct {
+	Name   string // CSV column title, JSON key or struct field name
+	Header string // Header text (Name)
+
+	// Format is the format of numbers, a FormatNumber pattern such as
+	// "$#,##0.00", or the layout of a time.Time (time.DateOnly). Numbers
+	// without a Format are written as they are in the data.
+	Format string
+
+	// Align is the alignment of the column. Columns of numbers are
+	// aligned right unless Align is set.
+	Align Align
+
+	// Total adds the column up in a row below the data. Without a Format
+	// the total has as many decimals as the number with the most in the
+	// column, which hides the rounding errors of the sum.
+	Total bool
+}
+
+// LoadCSV adds a row for every record of CSV data, whose first record
+// titles the columns. With no fields, every column is loaded, with its
+// title as header. See LoadStructs for the header and the total row.
+func (t *Table) LoadCSV(r io.Reader, fields ...Field) error {
+	records, err := csv.NewReader(r).ReadAll()
+	if err != nil {
+		return fmt.Errorf("table: reading CSV: %w", err)
+	}
+	if len(records) == 0 {
+		return nil
+	}
+	names := records[0]
+	rows := make([]map[string]any, len(records)-1)
+	for i, record := range records[1:] {
+		rows[i] = make(map[string]any, len(names))
+		for c, name := range names {
+			if c < len(record) {
+				rows[i][name] = record[c]
+			}
+		}
+	}
+	return t.load(names, rows, fields)
+}
+
+// LoadJSON adds a row for every object of a JSON array. With no fields,
+// every key is loaded, in the order the keys first appear, with the key as
+// header. Values that are objects or arrays are written as JSON. See
+// LoadStructs for the header and the total row.
+func (t *Table) LoadJSON(r io.Reader, fields ...Field) error {
+	var items []json.RawMessage
+	if err := json.NewDecoder(r).Decode(&items); err != nil {
+		return fmt.Errorf("table: reading JSON: %w", err)
+	}
+	var names []string
+	seen := make(map[string]bool)
+	rows := make([]map[string]any, len(items))
+	for i, item := range items {
+		keys, values, err := object(item)
+		if err != nil {
+			return fmt.Errorf("table: reading JSON: item %d: %w", i, err)
+		}
+		for _, k := range keys {
+			if !seen[k] {
+				seen[k] = true
+				names = append(names, k)
+			}
+		}
+		rows[i] = values
+	}
+	return t.load(names, rows, fields)
+}
+
+// object decodes a JSON object, keeping the order of its keys.
+func object(data []byte) ([]string, map[string]any, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	if tok, err := dec.Token(); err != nil {
+		return nil, nil, err
+	} else if tok != json.Delim('{') {
+		return nil, nil, errors.New("not an object")
+	}
+	var keys []string
+	values := make(map[string]any)
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return nil, nil, err
+		}
+		key := tok.(string)
+		var v any
+		if err := dec.Decode(&v); err != nil {
+			return nil, nil, err
+		}
+		switch v.(type) {
+		case map[string]any, []any:
+			raw, _ := json.Marshal(v)
+			v = string(raw)
+		}
+		keys = append(keys, key)
+		values[key] = v
+	}
+	return keys, values, nil
+}
+
+// LoadStructs adds a row for every element of items, a slice of structs
+// or of pointers to structs. The columns are the exported fields, set up
+// by struct tags:
+//
+//	type line struct {
+//		SKU   string    `table:"Item"`
+//		Date  time.Time `format:"02 Jan 2006"`
+//		Qty   int       `table:",center"`
+//		Price float64   `table:"Unit Price" format:"$#,##0.00"`
+//		Total float64   `table:",total" format:"$#,##0.00"`
+//		Notes string    `table:"-"`
+//	}
+//
+// The table tag is the header text (the field name if empty), followed by
+// the options left, center, right and total; "-" leaves the field out.
+// The format tag is the Field's Format. Fields given to LoadStructs are
+// used instead of the tags; their Name is the name of the struct field.
+//
+// The loaders set the header if the table has none, and if any field has
+// a Total, add a row of totals, labelled Options.TotalLabel, below the
+// rows.
+func (t *Table) LoadStructs(items any, fields ...Field) error {
+	v := reflect.Indirect(reflect.ValueOf(items))
+	if v.Kind() != reflect.Slice && v.Kind() != reflect.Array {
+		return fmt.Errorf("table: LoadStructs needs a slice of structs, not %T", items)
+	}
+	typ := v.Type().Elem()
+	for typ.Kind() == reflect.Pointer {
+		typ = typ.Elem()
+	}
+	if typ.Kind() != reflect.Struct {
+		return fmt.Errorf("table: LoadStructs needs a slice of structs, not %T", items)
+	}
+
+	var names []string
+	var index [][]int
+	var tagged []Field
+	for _, sf := range reflect.VisibleFields(typ) {
+		if !sf.IsExported() || sf.Anonymous {
+			continue
+		}
+		names = append(names, sf.Name)
+		index = append(index, sf.Index)
+		if f, ok := tagField(sf); ok {
+			tagged = append(tagged, f)
+		}
+	}
+	if len(fields) == 0 {
+		fields = tagged
+	}
+
+	rows := make([]map[string]any, v.Len())
+	for i := range rows {
+		item := v.Index(i)
+		for item.Kind() == reflect.Pointer {
+			item = item.Elem()
+		}
+		rows[i] = make(map[string]any, len(names))
+		if !item.IsValid() {
+			continue
+		}
+		for j, name := range names {
+			// A field promoted through a nil embedded pointer is left
+			// empty.
+			if fv, err := item.FieldByIndexErr(index[j]); err == nil {
+				rows[i][name] = fv.Interface()
+			}
+		}
+	}
+	return t.load(names, rows, fields)
+}
+
+// tagField returns the Field of a struct field from its tags, or false
+// for a field tagged "-".
+func tagField(sf reflect.StructField) (Field, bool) {
+	tag := sf.Tag.Get("table")
+	if tag == "-" {
+		return Field{}, false
+	}
+	header, opts, _ := strings.Cut(tag, ",")
+	f := Field{Name: sf.Name, Header: header, Format: sf.Tag.Get("format")}
+	for _, opt := range strings.Split(opts, ",") {
+		switch strings.TrimSpace(opt) {
+		case "left":
+			f.Align = Left
+		case "center":
+			f.Align = Center
+		case "right":
+			f.Align = Right
+		case "total":
+			f.Total = true
+		}
+	}
+	return f, true
+}
+
+// load adds rows of values by name, the columns given by fields, or all
+// names if there are none.
+func (t *Table) load(names []string, rows []map[string]any, fields []Field) error {
+	if len(fields) == 0 {
+		fields = make([]Field, len(names))
+		for i, name := range names {
+			fields[i].Name = name
+		}
+	}
+	for _, f := range fields {
+		found := false
+		for _, name := range names {
+			found = found || name == f.Name
+		}
+		if !found && len(rows) > 0 {
+			return fmt.Errorf("%w: %q", ErrUnknownField, f.Name)
+		}
+	}
+
+	if len(t.header) == 0 {
+		header := make([]string, len(fields))
+		for i, f := range fields {
+			header[i] = f.Header
+			if header[i] == "" {
+				header[i] = f.Name
+			}
+		}
+		t.SetHeader(header...)
+	}
+
+	totals := make([]float64, len(fields))
+	decimals := make([]int, len(fields)) // Most decimals in the column
+	numeric := make([]bool, len(fields)) // Only numbers in the column
+	for i := range numeric {
+		numeric[i] = len(rows) > 0
+	}
+	for _, row := range rows {
+		cells := make([]string, len(fields))
+		for i, f := range fields {
+			text, n, isNum := value(row[f.Name], f.Format)
+			cells[i] = text
+			if isNum {
+				totals[i] += n
+				decimals[i] = max(decimals[i], decimalsOf(text, n))
+			} else if text != "" {
+				numeric[i] = false
+			}
+		}
+		t.AddRow(cells...)
+	}
+
+	for i, f := range fields {
+		align := f.Align
+		if align == AlignDefault && numeric[i] {
+			align = Right
+		}
+		if c := t.column(i); c.Align == AlignDefault && align != AlignDefault {
+			c.Align = align
+			t.SetColumn(i, c)
+		}
+	}
+	t.addTotals(fields, totals, decimals)
+	return nil
+}
+
+// addTotals adds the row of totals of the fields with a Total: the label
+// spans the columns before the first total. decimals are the most decimals
+// of each column, for the totals without a Format.
+func (t *Table) addTotals(fields []Field, totals []float64, decimals []int) {
+	first := -1
+	for i, f := range fields {
+		if f.Total {
+			first = i
+			break
+		}
+	}
+	if first < 0 {
+		return
+	}
+	opts := t.opts
+	opts.setDefaults()
+	style := Cell{Style: fonts.Bold, Fill: opts.HeaderFill, Color: opts.HeaderColor}
+
+	var cells []Cell
+	if first > 0 {
+		label := style
+		label.Text, label.ColSpan, label.Align = opts.TotalLabel, first, Right
+		cells = append(cells, label)
+	}
+	for i, f := range fields[first:] {
+		c := style
+		switch {
+		case f.Total && f.Format == "":
+			c.Text = strconv.FormatFloat(totals[first+i], 'f', decimals[first+i], 64)
+		case f.Total:
+			c.Text = FormatNumber(totals[first+i], f.Format)
+		}
+		cells = append(cells, c)
+	}
+	t.AddCells(cells...)
+}
+
+// value returns the text of a value in the data, formatted with format,
+// and its number if it is one.
+func value(v any, format string) (text string, n float64, isNum bool) {
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Pointer || rv.Kind() == reflect.Interface {
+		if rv.IsNil() {
+			return "", 0, false
+		}
+		rv = rv.Elem()
+	}
+	if !rv.IsValid() {
+		return "", 0, false
+	}
+
+	switch v := rv.Interface().(type) {
+	case time.Time:
+		if format == "" {
+			format = time.DateOnly
+		}
+		return v.Format(format), 0, false
+	case json.Number:
+		return number(v.String(), format)
+	case string:
+		return number(v, format)
+	case fmt.Stringer:
+		return v.String(), 0, false
+	}
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n = float64(rv.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		n = float64(rv.Uint())
+	case reflect.Float32, reflect.Float64:
+		n = rv.Float()
+	default:
+		return fmt.Sprint(rv.Interface()), 0, false
+	}
+	return FormatNumber(n, format), n, true
+}
+
+// decimalsOf returns the number of decimals of the number n written as
+// text, or written as briefly as possible if text has an exponent.
+func decimalsOf(text string, n float64) int {
+	if _, frac, ok := strings.Cut(strings.TrimSpace(text), "."); ok && strings.Trim(frac, "0123456789") == "" {
+		return len(frac)
+	}
+	_, frac, _ := strings.Cut(strconv.FormatFloat(n, 'f', -1, 64), ".")
+	return len(frac)
+}
+
+// number returns the text of a value read as text, reformatted with
+// format if it is a number.
+func number(s, format string) (string, float64, bool) {
+	n, err := strconv.ParseFloat(strings.TrimSpace(s), 64)
+	// ParseFloat also reads "NaN" and "Inf", which are words here.
+	if err != nil || math.IsNaN(n) || math.IsInf(n, 0) {
+		return s, 0, false
+	}
+	if format != "" {
+		s = FormatNumber(n, format)
+	}
+	return s, n, true
+}
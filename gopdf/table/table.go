@@ -0,0 +1,310 @@
+// Package table draws tables into a flow.Flow. Columns are sized from
+// their content, cell text wraps onto as many lines as it needs, and a
+// table longer than the page continues on the next one, below a repeated
+// header.
+//
+//	t := table.New(table.Options{Stripe: color.Gray{Y: 240}})
+//	t.SetHeader("ID", "Product", "Price")
+//	t.AddRow("1", "Laptop", "$800")
+//	t.AddRow("2", "Mouse", "$25")
+//	t.SetColumn(1, table.Column{Min: 150})
+//	t.SetColumn(2, table.Column{Align: table.Right})
+//	err := t.Draw(f, reg)
+//
+// Cells given as Cell values can span several columns or rows and have
+// their own alignment, padding, borders, background and font:
+//
+//	t.AddHeaderCells(table.Cell{Text: "Sales", ColSpan: 2, Align: table.Center})
+//	t.AddCells(table.Cell{Text: "Total", ColSpan: 2, Style: fonts.Bold},
+//		table.Cell{Text: "$925", Fill: color.Gray{Y: 220}})
+//
+// Tables can also be filled from CSV, from a JSON array of objects or from
+// a slice of structs, with numbers formatted, aligned right and added up
+// in a row of totals:
+//
+//	err := t.LoadCSV(file,
+//		table.Field{Name: "sku", Header: "SKU"},
+//		table.Field{Name: "amount", Header: "Amount", Format: "#,##0.00", Total: true})
+package table
+
+import (
+	"image/color"
+	"strings"
+
+	"pdf-tutorial/gopdf/fonts"
+	"pdf-tutorial/gopdf/richtext"
+)
+
+// Options controls how a table looks. Zero fields get the default given in
+// their comment.
+type Options struct {
+	Family     string      // Font family (fonts.Sans)
+	Size       float64     // Font size (10)
+	LineHeight float64     // Distance between baselines in a cell (1.25 times Size)
+	Padding    float64     // Space between the border of a cell and its text (4)
+	Color      color.Color // Text color (black)
+
+	HeaderColor color.Color // Text color of the header row (Color)
+	HeaderFill  color.Color // Background of the header row (light gray)
+
+	// Stripe is the background of every other data row, starting with
+	// the second. Nil leaves all rows unfilled.
+	Stripe color.Color
+
+	Border      Border      // Sides of the cells with a border (BorderAll)
+	BorderColor color.Color // Color of the cell borders (gray)
+	BorderWidth float64     // Width of the cell borders (0.5)
+
+	// Width is the width of the table. Zero means the natural width of
+	// its columns, but no wider than the flow.
+	Width float64
+
+	// TotalLabel is the first cell of the row of totals the loaders add
+	// ("Total").
+	TotalLabel string
+}
+
+func (o *Options) setDefaults() {
+	if o.Family == "" {
+		o.Family = fonts.Sans
+	}
+	if o.Size <= 0 {
+		o.Size = 10
+	}
+	if o.LineHeight <= 0 {
+		o.LineHeight = o.Size * 1.25
+	}
+	if o.Padding <= 0 {
+		o.Padding = 4
+	}
+	if o.Color == nil {
+		o.Color = color.Black
+	}
+	if o.HeaderColor == nil {
+		o.HeaderColor = o.Color
+	}
+	if o.HeaderFill == nil {
+		o.HeaderFill = color.RGBA{235, 235, 235, 255}
+	}
+	if o.Border == 0 {
+		o.Border = BorderAll
+	}
+	if o.BorderColor == nil {
+		o.BorderColor = color.RGBA{160, 160, 160, 255}
+	}
+	if o.BorderWidth <= 0 {
+		o.BorderWidth = 0.5
+	}
+	if o.TotalLabel == "" {
+		o.TotalLabel = "Total"
+	}
+}
+
+// Column sets how the width of a column is chosen. Widths include the
+// padding of the cells.
+type Column struct {
+	// Width is a fixed width. Zero sizes the column from its content:
+	// the widest cell if the table has room for it, and less, down to
+	// the widest word, if it has not.
+	Width float64
+
+	Min float64 // Smallest width of a sized column (none)
+	Max float64 // Largest width of a sized column (none)
+
+	Align Align // Alignment of the cells of the column (Left)
+}
+
+// Table is header rows and data rows, drawn by Draw.
+type Table struct {
+	opts    Options
+	header  [][]Cell
+	rows    [][]Cell
+	columns []Column
+}
+
+// New returns an empty table.
+func New(opts Options) *Table {
+	return &Table{opts: opts}
+}
+
+// SetHeader sets the header row, which is drawn above the data rows in
+// bold and repeated at the top of every page the table continues on.
+func (t *Table) SetHeader(cells ...string) {
+	t.header = [][]Cell{textCells(cells)}
+}
+
+// AddHeaderCells adds a header row, below those set or added before, e.g.
+// a row of column titles below a row of cells that group them.
+func (t *Table) AddHeaderCells(cells ...Cell) {
+	t.header = append(t.header, cells)
+}
+
+// AddRow adds a data row. Rows may have fewer cells than the table has
+// columns; the missing cells are empty.
+func (t *Table) AddRow(cells ...string) {
+	t.rows = append(t.rows, textCells(cells))
+}
+
+// AddCells adds a data row of cells. As in HTML, a cell goes in the first
+// column that is not covered by a cell spanning down from a row above.
+func (t *Table) AddCells(cells ...Cell) {
+	t.rows = append(t.rows, cells)
+}
+
+// SetColumn sets the sizing of column i, counting from 0.
+func (t *Table) SetColumn(i int, c Column) {
+	for len(t.columns) <= i {
+		t.columns = append(t.columns, Column{})
+	}
+	t.columns[i] = c
+}
+
+// Columns returns the number of columns: the number of columns covered
+// by the widest row.
+func (t *Table) Columns() int {
+	_, nh := place(t.header)
+	_, nr := place(t.rows)
+	return max(nh, nr)
+}
+
+// Rows returns the number of data rows.
+func (t *Table) Rows() int {
+	return len(t.rows)
+}
+
+// textCells returns cells holding texts.
+func textCells(texts []string) []Cell {
+	cells := make([]Cell, len(texts))
+	for i, text := range texts {
+		cells[i].Text = text
+	}
+	return cells
+}
+
+// column returns the sizing of column i.
+func (t *Table) column(i int) Column {
+	if i < len(t.columns) {
+		return t.columns[i]
+	}
+	return Column{}
+}
+
+// widths sizes the columns of a table drawn in at most avail. natural and
+// word are the widths of the widest cell and the widest word of every
+// column, with padding.
+func (t *Table) widths(natural, word []float64, avail float64) []float64 {
+	n := len(natural)
+	widths := make([]float64, n)
+	lo := make([]float64, n) // Width a sized column can shrink to
+	flex := make([]bool, n)  // Sized from the content
+	fixed, total := 0.0, 0.0
+	for i := range widths {
+		c := t.column(i)
+		if c.Width > 0 {
+			widths[i], lo[i] = c.Width, c.Width
+			fixed += c.Width
+			total += c.Width
+			continue
+		}
+		flex[i] = true
+		w, l := natural[i], max(word[i], c.Min)
+		if c.Max > 0 {
+			w, l = min(w, c.Max), min(l, c.Max)
+		}
+		widths[i], lo[i] = max(w, l), l
+		total += widths[i]
+	}
+
+	target := min(total, avail)
+	if t.opts.Width > 0 {
+		target = min(t.opts.Width, avail)
+	}
+	switch {
+	case total < target:
+		t.grow(widths, flex, target-total)
+	case total > target:
+		// Take the room from the columns that can wrap first, in
+		// proportion to how much they can give.
+		need, slack := total-target, 0.0
+		for i := range widths {
+			if flex[i] {
+				slack += widths[i] - lo[i]
+			}
+		}
+		if slack >= need {
+			for i := range widths {
+				if flex[i] {
+					widths[i] -= (widths[i] - lo[i]) * need / slack
+				}
+			}
+			break
+		}
+		// Even the widest words do not fit: scale the sized columns down
+		// (their words will be split), or all of them if the fixed
+		// columns alone are too wide.
+		sized := total - fixed - slack
+		if total-slack <= 0 {
+			break
+		}
+		all := target <= fixed || sized <= 0
+		scale := (target - fixed) / sized
+		if all {
+			scale = target / (total - slack)
+		}
+		for i := range widths {
+			if all || flex[i] {
+				widths[i] = lo[i] * scale
+			}
+		}
+	}
+	return widths
+}
+
+// grow spreads extra over the sized columns in proportion to their width,
+// without making any of them wider than its Max.
+func (t *Table) grow(widths []float64, flex []bool, extra float64) {
+	for extra > 1e-9 {
+		sum := 0.0
+		for i, w := range widths {
+			if flex[i] && (t.column(i).Max <= 0 || w < t.column(i).Max) {
+				sum += w
+			}
+		}
+		if sum == 0 {
+			return
+		}
+		left := 0.0
+		for i, w := range widths {
+			if !flex[i] || t.column(i).Max > 0 && w >= t.column(i).Max {
+				continue
+			}
+			widths[i] = w + extra*w/sum
+			if m := t.column(i).Max; m > 0 && widths[i] > m {
+				left += widths[i] - m
+				widths[i] = m
+			}
+		}
+		extra = left
+	}
+}
+
+// measure returns the width of the widest line of text and of its widest
+// word, without padding.
+func measure(reg *fonts.Registry, text string, run richtext.Run) (line, word float64, err error) {
+	opts := richtext.Options{Width: 1e6}
+	for i, t := range []string{text, strings.Join(strings.Fields(text), "\n")} {
+		run.Text = t
+		lines, err := richtext.Layout(reg, []richtext.Run{run}, 0, 0, opts)
+		if err != nil {
+			return 0, 0, err
+		}
+		for _, l := range lines {
+			if i == 0 {
+				line = max(line, l.Width)
+			} else {
+				word = max(word, l.Width)
+			}
+		}
+	}
+	return line, word, nil
+}
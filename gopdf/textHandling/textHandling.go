@@ -2,8 +2,18 @@ package main
 
 import (
 	"fmt"
+	"log"
 	"strings"
 
+	"pdf-tutorial/gopdf/flow"
+	"pdf-tutorial/gopdf/fonts"
+	"pdf-tutorial/gopdf/hyphen"
+	"pdf-tutorial/gopdf/markdown"
+	"pdf-tutorial/gopdf/page"
+	"pdf-tutorial/gopdf/richtext"
+	"pdf-tutorial/gopdf/textlayout"
+	"pdf-tutorial/gopdf/units"
+
 	"github.com/signintech/gopdf"
 )
 
@@ -30,6 +40,9 @@ func main() {
 	// Example 4: UTF-8 and special character support
 	utf8Example()
 
+	// Example 5: Rendering a Markdown document
+	markdownExample()
+
 	fmt.Println("\nAll text handling examples completed!")
 }
 
@@ -49,86 +62,114 @@ func fontManagementExample() {
 	// You need actual font files for this to work
 	err := pdf.AddTTFFont("arial", "./fonts/arial.ttf")
 	if err != nil {
-		// Fallback to system fonts (Windows example)
-		err = pdf.AddTTFFont("arial", "C:/Windows/Fonts/arial.ttf")
+		// Fallback: use the embedded Liberation Sans (same metrics as Arial),
+		// which works on every machine without font files
+		err = fonts.Register(&pdf, "arial", fonts.Sans, "Regular")
 		if err != nil {
-			fmt.Println("Arial font not found, using fallback")
+			log.Println(err)
+			return
 		}
 	}
 
-	// Method 2: Adding multiple font styles
-	// Regular, Bold, Italic variants
-	pdf.AddTTFFont("arial-bold", "C:/Windows/Fonts/arialbd.ttf")
-	pdf.AddTTFFont("arial-italic", "C:/Windows/Fonts/ariali.ttf")
+	// Method 2: Adding font families with all their styles
+	// The registry groups Regular, Bold, Italic and Bold Italic files under
+	// one family name, so you ask for (fonts.Sans, fonts.Bold, 12) instead
+	// of remembering a separate name for every file. It comes with the
+	// embedded families fonts.Sans, fonts.Serif and fonts.Mono.
+	reg := fonts.NewRegistry(&pdf)
 
 	// Method 3: Adding different font families
-	pdf.AddTTFFont("times", "C:/Windows/Fonts/times.ttf")
-	pdf.AddTTFFont("courier", "C:/Windows/Fonts/cour.ttf")
+	// Installed fonts can be added by family name; styles that are missing
+	// are synthesized from the regular face, e.g.:
+	//   reg.AddSystemFamily("dejavu", "DejaVu Sans")
+	//   reg.AddFace("brand", fonts.Bold, "./fonts/brand-bold.ttf")
 
 	// ============================================
 	// USING DIFFERENT FONTS
 	// ============================================
 
 	// Title
-	pdf.SetFont("arial-bold", "", 18)
+	reg.SetFont(fonts.Sans, fonts.Bold, 18)
 	pdf.SetXY(50, 50)
-	pdf.Text("Font Management Examples")
+	reg.Text("Font Management Examples")
 
 	yPos := 80.0
 
-	// Example: Arial Regular
-	pdf.SetFont("arial", "", 12)
-	pdf.SetXY(50, yPos)
-	pdf.Text("This is Arial Regular font")
-	yPos += 25
-
-	// Example: Arial Bold
-	pdf.SetFont("arial-bold", "", 12)
-	pdf.SetXY(50, yPos)
-	pdf.Text("This is Arial Bold font")
-	yPos += 25
-
-	// Example: Arial Italic
-	pdf.SetFont("arial-italic", "", 12)
-	pdf.SetXY(50, yPos)
-	pdf.Text("This is Arial Italic font")
-	yPos += 25
+	// Example: Sans Regular, Bold, Italic and Bold Italic
+	samples := []struct {
+		style fonts.Style
+		text  string
+	}{
+		{fonts.Regular, "This is Sans Regular font (Arial metrics)"},
+		{fonts.Bold, "This is Sans Bold font"},
+		{fonts.Italic, "This is Sans Italic font"},
+		{fonts.BoldItalic, "This is Sans Bold Italic font"},
+	}
+	for _, sample := range samples {
+		reg.SetFont(fonts.Sans, sample.style, 12)
+		pdf.SetXY(50, yPos)
+		reg.Text(sample.text)
+		yPos += 25
+	}
 
-	// Example: Times New Roman
-	pdf.SetFont("times", "", 12)
+	// Example: Serif (Times New Roman metrics)
+	reg.SetFont(fonts.Serif, fonts.Regular, 12)
 	pdf.SetXY(50, yPos)
-	pdf.Text("This is Times New Roman font")
+	reg.Text("This is Serif font (Times New Roman metrics)")
 	yPos += 25
 
-	// Example: Courier (monospace)
-	pdf.SetFont("courier", "", 12)
+	// Example: Mono (Courier New metrics)
+	reg.SetFont(fonts.Mono, fonts.Regular, 12)
 	pdf.SetXY(50, yPos)
-	pdf.Text("This is Courier font (monospace)")
+	reg.Text("This is Mono font (monospace)")
 	yPos += 40
 
 	// ============================================
 	// FONT SIZES DEMONSTRATION
 	// ============================================
 
-	pdf.SetFont("arial-bold", "", 14)
-	pdf.SetXY(50, yPos)
-	pdf.Text("Different Font Sizes:")
-	yPos += 30
+	// From here on, a flow keeps track of the y position: every line
+	// reserves its height, and a new page is added when the next line
+	// would run off the bottom of the page.
+	f := flow.New(&pdf, flow.Options{Drawer: reg})
+	f.SetY(yPos - 20)
+
+	reg.SetFont(fonts.Sans, fonts.Bold, 14)
+	f.Text("Different Font Sizes:", 30)
 
 	sizes := []float64{8, 10, 12, 14, 16, 18, 20, 24}
 	for _, size := range sizes {
-		pdf.SetFont("arial", "", size)
-		pdf.SetXY(50, yPos)
-		pdf.Text(fmt.Sprintf("Font size %.0fpt - The quick brown fox", size))
-		yPos += size + 5
+		reg.SetFont(fonts.Sans, fonts.Regular, size)
+		if err := f.Text(fmt.Sprintf("Font size %.0fpt - The quick brown fox", size), size+5); err != nil {
+			log.Println(err)
+		}
+	}
+
+	// ============================================
+	// MIXING STYLES IN ONE PARAGRAPH
+	// ============================================
+
+	// richtext measures and places every fragment, so styles, colors and
+	// sizes can change in the middle of a line and still wrap together
+	f.Space(20)
+	reg.SetFont(fonts.Sans, fonts.Bold, 14)
+	f.Text("Mixing Styles (rich text):", 25)
+
+	markup := "Rich text mixes **bold**, *italic* and ***bold italic*** words, " +
+		"{color:#c00}colored text{}, {size:16}larger text{} and {font:mono}monospace{} " +
+		"in one paragraph. The fragments share a baseline and wrap together, " +
+		"just like plain text."
+	yPos, err = richtext.Markup(reg, markup, f.X(), f.Y()+12, richtext.Options{Width: f.Width(), LineHeight: 15, Size: 12})
+	if err != nil {
+		log.Println(err)
 	}
+	f.SetY(yPos)
 
 	// ============================================
 	// FONT EMBEDDING NOTES
 	// ============================================
 
-	pdf.AddPage()
-	pdf.SetFont("arial", "", 11)
+	f.NewPage()
 
 	notes := []string{
 		"FONT EMBEDDING TIPS:",
@@ -138,30 +179,29 @@ func fontManagementExample() {
 		"3. Embedded fonts increase PDF file size",
 		"4. Always check AddTTFFont() error for missing fonts",
 		"5. Have fallback fonts ready for production",
+		"6. Group font styles in a fonts.Registry and pick them by style",
+		"7. Let a flow.Flow track the y position and add pages for you",
 		"",
 		"Common font locations:",
 		"• Windows: C:/Windows/Fonts/",
 		"• macOS: /Library/Fonts/ or /System/Library/Fonts/",
 		"• Linux: /usr/share/fonts/ or ~/.fonts/",
+		"• Anywhere: folders listed in the GOPDF_FONT_PATH variable",
 	}
 
-	yPos = 50.0
 	for _, note := range notes {
-		pdf.SetXY(50, yPos)
 		if note == "" {
-			yPos += 10
+			f.Space(10)
 			continue
 		}
 
 		// Bold for main heading
 		if note == "FONT EMBEDDING TIPS:" {
-			pdf.SetFont("arial-bold", "", 14)
-			pdf.Text(note)
-			yPos += 25
+			reg.SetFont(fonts.Sans, fonts.Bold, 14)
+			f.Text(note, 25)
 		} else {
-			pdf.SetFont("arial", "", 11)
-			pdf.Text(note)
-			yPos += 18
+			reg.SetFont(fonts.Sans, fonts.Regular, 11)
+			f.Text(note, 18)
 		}
 	}
 
@@ -178,7 +218,10 @@ func textPositioningExample() {
 	pdf.AddPage()
 
 	// Setup font
-	setupFont(&pdf, "arial")
+	if err := setupFont(&pdf, "arial"); err != nil {
+		log.Println(err)
+		return
+	}
 
 	// ============================================
 	// BASIC POSITIONING WITH SetXY
@@ -188,8 +231,9 @@ func textPositioningExample() {
 	pdf.SetXY(50, 50)
 	pdf.Text("Text Positioning and Alignment")
 
-	// Draw reference grid lines (for visualization)
-	drawGrid(&pdf)
+	// Draw reference grid lines (for visualization): every 50pt across and
+	// 30pt down
+	drawGrid(&pdf, 50, 100, 500, 120, 50, 30)
 
 	// Absolute positioning
 	pdf.SetFont("arial", "", 11)
@@ -216,7 +260,7 @@ func textPositioningExample() {
 	// Center alignment
 	text := "CENTER ALIGNED TEXT"
 	textWidth, _ := pdf.MeasureTextWidth(text)
-	pageWidth := gopdf.PageSizeA4.W
+	pageWidth := page.Size(&pdf).W
 	centerX := (pageWidth - textWidth) / 2
 	pdf.SetXY(centerX, yPos)
 	pdf.Text(text)
@@ -238,8 +282,10 @@ func textPositioningExample() {
 	pdf.Text("Justified Text Example (stretched to fit width):")
 	yPos += 25
 
-	justifiedText := "This text is justified across the page"
-	justifyText(&pdf, justifiedText, 50, yPos, 495) // 495 = A4 width - margins
+	justifiedText := "This text is justified across the page. Every line except the last " +
+		"is stretched to the full width, and the line breaks are chosen for the " +
+		"whole paragraph at once, so the spaces stay about the same size on every line."
+	justifyText(&pdf, justifiedText, 50, units.FromUnits(&pdf, yPos), 495, 15) // 495 = A4 width - margins
 
 	// ============================================
 	// HELPER FUNCTIONS FOR ALIGNMENT
@@ -285,7 +331,10 @@ func lineSpacingExample() {
 	pdf.Start(gopdf.Config{PageSize: *gopdf.PageSizeA4})
 	pdf.AddPage()
 
-	setupFont(&pdf, "arial")
+	if err := setupFont(&pdf, "arial"); err != nil {
+		log.Println(err)
+		return
+	}
 
 	// ============================================
 	// LINE SPACING EXAMPLES
@@ -340,58 +389,65 @@ func lineSpacingExample() {
 	// PARAGRAPH FORMATTING
 	// ============================================
 
+	// The next pages are laid out in millimetres, the way a designer would
+	// specify them. The helpers take units.Lengths and convert them to the
+	// unit of the document, so the layout does not depend on Config.Unit.
+	margin := 18 * units.Mm
+	width := units.FromUnits(&pdf, page.Size(&pdf).W) - 2*margin // 174mm on A4
+	leading := 5.3 * units.Mm                                     // About 15pt
+	gap := 7 * units.Mm
+
+	// heading writes a paragraph heading at y and returns the y below it
+	heading := func(y units.Length, text string) units.Length {
+		pdf.SetXY(margin.Units(&pdf), y.Units(&pdf))
+		pdf.Text(text)
+		return y + gap
+	}
+
 	pdf.AddPage()
 	pdf.SetFont("arial", "", 14)
-	pdf.SetXY(50, 50)
-	pdf.Text("Paragraph Formatting Examples")
+	heading(18*units.Mm, "Paragraph Formatting Examples")
 
-	yPos = 80.0
+	y := 28 * units.Mm
 
 	// Paragraph 1: No indentation
 	pdf.SetFont("arial", "", 11)
-	pdf.SetXY(50, yPos)
-	pdf.Text("Paragraph 1 (No Indentation):")
-	yPos += 20
+	y = heading(y, "Paragraph 1 (No Indentation):")
 
 	paragraph1 := "This is the first paragraph with no indentation. It starts at the left margin and continues normally. This demonstrates basic paragraph formatting without any special indentation."
-	yPos = wrapTextWithSpacing(&pdf, paragraph1, 50, yPos, 495, 15)
-	yPos += 20
+	y = wrapTextWithSpacing(&pdf, paragraph1, margin, y, width, leading)
+	y += gap
 
 	// Paragraph 2: First-line indentation
-	pdf.SetXY(50, yPos)
-	pdf.Text("Paragraph 2 (First-line Indentation):")
-	yPos += 20
+	y = heading(y, "Paragraph 2 (First-line Indentation):")
 
 	// Add indentation to first line
-	pdf.SetXY(80, yPos) // Indented 30pt
-	pdf.Text("This paragraph has first-line indentation. Notice how the first line")
-	yPos += 15
-
-	paragraph2Rest := "starts further to the right, creating a traditional paragraph style commonly seen in books and formal documents."
-	yPos = wrapTextWithSpacing(&pdf, paragraph2Rest, 50, yPos, 495, 15)
-	yPos += 20
+	paragraph2 := "This paragraph has first-line indentation. Notice how the first line starts further to the right, creating a traditional paragraph style commonly seen in books and formal documents."
+	y = wrapTextWithIndent(&pdf, paragraph2, margin, y, width, leading, 10*units.Mm, 0) // First line indented 10mm
+	y += gap
 
 	// Paragraph 3: Hanging indentation
-	pdf.SetXY(50, yPos)
-	pdf.Text("Paragraph 3 (Hanging Indentation):")
-	yPos += 20
-
-	pdf.SetXY(50, yPos)
-	pdf.Text("1. This is hanging indentation where the first line starts at the margin")
-	yPos += 15
+	y = heading(y, "Paragraph 3 (Hanging Indentation):")
 
-	hangingText := "but subsequent lines are indented. This is commonly used in bibliographies and numbered lists."
-	yPos = wrapTextWithSpacing(&pdf, hangingText, 70, yPos, 475, 15)
-	yPos += 20
+	hangingText := "1. This is hanging indentation where the first line starts at the margin but subsequent lines are indented. This is commonly used in bibliographies and numbered lists."
+	y = wrapTextWithIndent(&pdf, hangingText, margin, y, width, leading, 0, 7*units.Mm) // Other lines indented 7mm
+	y += gap
 
 	// Paragraph 4: Block quote style
-	pdf.SetXY(50, yPos)
-	pdf.Text("Paragraph 4 (Block Quote Style):")
-	yPos += 20
+	y = heading(y, "Paragraph 4 (Block Quote Style):")
 
 	blockQuote := "This entire paragraph is indented from both margins, creating a block quote effect. This style is often used for quotations, examples, or to highlight important text within a document."
-	yPos = wrapTextWithSpacing(&pdf, blockQuote, 80, yPos, 435, 15) // Indented from left, narrower width
-	yPos += 20
+	y = wrapTextWithSpacing(&pdf, blockQuote, margin+10*units.Mm, y, width-20*units.Mm, leading) // Indented 10mm on both sides
+	y += gap
+
+	// Paragraph 5: Narrow columns, without and with hyphenation
+	y = heading(y, "Paragraph 5 (Narrow Columns, Without and With Hyphenation):")
+
+	narrowText := "Narrow columns look uneven when lines can only break at spaces, because long words such as internationalization or responsibilities leave large gaps. Hyphenation breaks them between syllables instead."
+	column := 56 * units.Mm
+	leftY := wrapTextWithSpacing(&pdf, narrowText, margin, y, column, leading)
+	rightY := wrapTextHyphenated(&pdf, narrowText, "en", margin+column+14*units.Mm, y, column, leading)
+	y = max(leftY, rightY) + gap
 
 	// ============================================
 	// SPACING BETWEEN PARAGRAPHS
@@ -399,17 +455,16 @@ func lineSpacingExample() {
 
 	pdf.AddPage()
 	pdf.SetFont("arial", "", 14)
-	pdf.SetXY(50, 50)
-	pdf.Text("Spacing Between Paragraphs")
+	heading(18*units.Mm, "Spacing Between Paragraphs")
 
-	yPos = 80.0
+	y = 28 * units.Mm
 
-	spacings := []float64{10, 20, 30}
+	spacings := []units.Length{3 * units.Mm, 6 * units.Mm, 10 * units.Mm}
 	for i, spacing := range spacings {
 		pdf.SetFont("arial", "", 11)
-		p := fmt.Sprintf("Paragraph %d: This paragraph demonstrates %.0fpt spacing after. Lorem ipsum dolor sit amet, consectetur adipiscing elit.", i+1, spacing)
-		yPos = wrapTextWithSpacing(&pdf, p, 50, yPos, 495, 15)
-		yPos += spacing // Add the specified spacing after paragraph
+		p := fmt.Sprintf("Paragraph %d: This paragraph demonstrates %.0fmm spacing after. Lorem ipsum dolor sit amet, consectetur adipiscing elit.", i+1, spacing.Millimeters())
+		y = wrapTextWithSpacing(&pdf, p, margin, y, width, leading)
+		y += spacing // Add the specified spacing after paragraph
 	}
 
 	pdf.WritePdf(goPdfFolder +  textHandling + "03-line-spacing.pdf")
@@ -426,11 +481,32 @@ func utf8Example() {
 
 	// UTF-8 support requires special font handling
 	// Use a font that supports Unicode characters
-	err := pdf.AddTTFFont("unicode", "C:/Windows/Fonts/arial.ttf")
+	err := fonts.Register(&pdf, "unicode", fonts.Sans, "Regular")
 	if err != nil {
-		fmt.Println("Unicode font not found, some characters may not display")
+		log.Println(err)
+		return
 	}
 
+	// No single font covers every script, so set up a fallback chain:
+	// Latin text first, then a symbol font, then a CJK font. Fonts that
+	// are not installed are simply left out of the chain.
+	reg := fonts.NewRegistry(&pdf)
+	var fallbacks []string
+	for _, f := range []struct{ name, family string }{
+		{"symbols", "DejaVu Sans"},
+		{"cjk", "Droid Sans Fallback"},
+		{"cjk", "Noto Sans SC"},
+		{"cjk", "Unifont"},
+	} {
+		if _, ok := reg.Family(f.name); ok {
+			continue
+		}
+		if err := reg.AddSystemFamily(f.name, f.family); err == nil {
+			fallbacks = append(fallbacks, f.name)
+		}
+	}
+	reg.SetFallbacks(fallbacks...)
+
 	pdf.SetFont("unicode", "", 16)
 	pdf.SetXY(50, 50)
 	pdf.Text("UTF-8 and Special Characters")
@@ -447,17 +523,22 @@ func utf8Example() {
 	yPos += 25
 
 	specialChars := []string{
-		`Quotes: "Hello" 'World' guillemets`,
-		"Currency: $ (euro) (pound) (yen)",
-		"Math: +/- x / approx not-equal less-equal greater-equal",
-		"Symbols: (c) (R) (TM) section paragraph",
-		"Arrows: left right up down double-arrow",
-		"Bullets: dot circle square diamond",
+		"Quotes: “Hello” ‘World’ «guillemets»",
+		"Currency: $ € £ ¥ ₹ ₩",
+		"Math: ± × ÷ ≈ ≠ ≤ ≥ ∞ √ ∑",
+		"Symbols: © ® ™ § ¶ ° †",
+		"Arrows: ← → ↑ ↓ ↔ ⇒",
+		"Bullets: • ◦ ▪ ◆ ★ ✓",
 	}
 
+	// reg.Text switches fonts in the middle of a line when a character is
+	// missing, and reports characters that no font in the chain has
+	reg.SetFont(fonts.Sans, fonts.Regular, 12)
 	for _, chars := range specialChars {
 		pdf.SetXY(50, yPos)
-		pdf.Text(chars)
+		if err := reg.Text(chars); err != nil {
+			fmt.Println(err)
+		}
 		yPos += 20
 	}
 
@@ -471,18 +552,55 @@ func utf8Example() {
 	pdf.Text("Multilingual Support:")
 	yPos += 25
 
-	// Note: These will only work if your font supports these characters
+	// Characters missing from the Latin font come from the fallback fonts
 	languages := []string{
 		"English: Hello World",
-		"Spanish: Hola Mundo (accented characters)",
-		"French: Bonjour le monde (accented characters)",
-		"German: Hallo Welt (umlaut characters)",
-		"Portuguese: Ola Mundo (accented characters)",
+		"Spanish: ¡Hola Mundo! ¿Qué tal? Año, niño",
+		"French: Bonjour le monde, ça va ? Élève, garçon",
+		"German: Hallo Welt, schöne Grüße aus Köln",
+		"Portuguese: Olá Mundo, atenção, coração",
+		"Greek: Γειά σου Κόσμε",
+		"Russian: Привет, мир",
+		"Japanese: こんにちは世界",
+		"Chinese: 你好，世界",
 	}
 
 	for _, lang := range languages {
 		pdf.SetXY(50, yPos)
-		pdf.Text(lang)
+		if err := reg.Text(lang); err != nil {
+			fmt.Println(err)
+		}
+		yPos += 20
+	}
+	yPos += 10
+
+	// ============================================
+	// HYPHENATION
+	// ============================================
+
+	pdf.SetXY(50, yPos)
+	pdf.Text("Hyphenation (embedded TeX patterns):")
+	yPos += 25
+
+	hyphenated := []struct{ lang, word string }{
+		{"en", "internationalization"},
+		{"es", "desarrollo, información"},
+		{"fr", "développement, garçon"},
+		{"de", "Silbentrennung, Grüße"},
+		{"pt", "desenvolvimento, coração"},
+	}
+	for _, h := range hyphenated {
+		hy, err := hyphen.Language(h.lang)
+		if err != nil {
+			fmt.Println(err)
+			continue
+		}
+		var words []string
+		for _, w := range strings.Fields(h.word) {
+			words = append(words, strings.Join(hy.Hyphenate(w), "-"))
+		}
+		pdf.SetXY(50, yPos)
+		pdf.Text(h.lang + ": " + strings.Join(words, " "))
 		yPos += 20
 	}
 
@@ -530,7 +648,7 @@ func utf8Example() {
 		"2. Ensure your Go source files are saved as UTF-8",
 		"3. Test special characters before deploying",
 		"4. Some fonts may not support all Unicode characters",
-		"5. Consider fallback fonts for missing glyphs",
+		"5. Use a fallback chain (Registry.SetFallbacks) for missing glyphs",
 	}
 
 	for _, tip := range tips {
@@ -543,35 +661,117 @@ func utf8Example() {
 	fmt.Println("Created: 04-utf8-characters.pdf to", goPdfFolder +  textHandling,"folder")
 }
 
+// Example 5: Rendering a Markdown Document
+func markdownExample() {
+	fmt.Println("Example 5: Rendering a Markdown Document")
+
+	pdf := gopdf.GoPdf{}
+	pdf.Start(gopdf.Config{PageSize: *gopdf.PageSizeA4})
+	reg := fonts.NewRegistry(&pdf)
+
+	// The document is drawn into a flow, which adds the pages and gives
+	// each one a footer with its page number
+	f := flow.New(&pdf, flow.Options{Drawer: reg})
+	f.SetFooter(20, func(f *flow.Flow, b flow.Band) error {
+		reg.SetFont(fonts.Sans, fonts.Regular, 9)
+		text := fmt.Sprintf("Release Notes - Page %d of %s", f.Page(), flow.TotalPages)
+		return f.PageText(text, b.X, b.Y+b.H, b.W, gopdf.Center)
+	})
+
+	// The markdown package turns headings, paragraphs, lists, quotes, code
+	// and tables into the same kind of layout built by hand in Example 3,
+	// and starts new pages by itself when the text gets long.
+	err := markdown.Draw(f, reg, releaseNotes, markdown.Options{})
+	if err != nil {
+		log.Println(err)
+	}
+
+	// Anything drawn afterwards continues below the document.
+	reg.SetFont(fonts.Sans, fonts.Italic, 9)
+	f.Space(10)
+	f.Text("Rendered from Markdown with the gopdf/markdown package.", 12)
+
+	f.WritePdf(goPdfFolder + textHandling + "05-markdown.pdf")
+	fmt.Println("Created: 05-markdown.pdf to", goPdfFolder+textHandling, "folder")
+}
+
+// releaseNotes is the Markdown document drawn by markdownExample.
+const releaseNotes = `# Release Notes
+
+Version **2.0** of the report generator is a large update. It brings
+*faster* rendering, new page layouts and a cleaner API. Read the
+[migration guide](https://github.com/signintech/gopdf) before upgrading.
+
+## Highlights
+
+- Text is wrapped and justified with the ` + "`textlayout`" + ` package
+- Words are hyphenated in five languages:
+  - English, Spanish and Portuguese
+  - French and German
+- Long documents continue on new pages automatically
+
+## Upgrading
+
+1. Update the module with ` + "`go get`" + `.
+2. Replace calls to the old helpers:
+
+   ` + "```" + `go
+   y, err := markdown.Render(reg, notes, 50, markdown.Options{})
+   if err != nil {
+       log.Fatal(err)
+   }
+   ` + "```" + `
+
+3. Run the examples and compare the output.
+
+> **Note:** Fonts are now embedded in the binary, so the examples look
+> the same on every machine, with or without system fonts.
+
+## Supported Elements
+
+| Element     | Syntax            | Notes                       |
+|:------------|:-----------------:|-----------------------------|
+| Heading     | ` + "`# Title`" + `         | Six levels                  |
+| Emphasis    | ` + "`*a*`, `**b**`" + `    | Can be nested               |
+| List        | ` + "`- item`, `1. item`" + ` | Bulleted or numbered, nested |
+| Code        | Fenced or indented | Drawn in a monospaced font |
+| Table       | Pipes and dashes   | Columns can be aligned     |
+
+---
+
+Questions and bug reports are welcome at <https://github.com/devzeeh/pdf-tutorial-Golang>.
+`
+
 // ============================================
 // HELPER FUNCTIONS
 // ============================================
 
 // setupFont safely loads a font with fallback
-func setupFont(pdf *gopdf.GoPdf, fontName string) {
-	err := pdf.AddTTFFont(fontName, "./fonts/"+fontName+".ttf")
-	if err != nil {
-		err = pdf.AddTTFFont(fontName, "C:/Windows/Fonts/"+fontName+".ttf")
-		if err != nil {
-			pdf.AddTTFFont(fontName, "C:/Windows/Fonts/arial.ttf")
-		}
-	}
+// The font is the embedded Liberation Sans (same metrics as Arial), so the
+// examples look the same on every machine, with or without system fonts.
+func setupFont(pdf *gopdf.GoPdf, fontName string) error {
+	return fonts.Register(pdf, fontName, fonts.Sans, "Regular")
 }
 
-// drawGrid draws a reference grid for visualization
-func drawGrid(pdf *gopdf.GoPdf) {
+// drawGrid draws a reference grid for visualization over the w x h area
+// at x, y, with a vertical line every stepX and a horizontal line every
+// stepY
+func drawGrid(pdf *gopdf.GoPdf, x, y, w, h, stepX, stepY units.Length) {
 	// Draw light gray grid lines
-	pdf.SetLineWidth(0.5)
+	pdf.SetLineWidth((0.5 * units.Pt).Units(pdf))
 	pdf.SetStrokeColor(200, 200, 200)
 
-	// Vertical lines every 50pt
-	for x := 50.0; x < 550; x += 50 {
-		pdf.Line(x, 100, x, 220)
+	top, bottom := y.Units(pdf), (y + h).Units(pdf)
+	left, right := x.Units(pdf), (x + w).Units(pdf)
+
+	// Vertical lines
+	for gx := x; gx < x+w; gx += stepX {
+		pdf.Line(gx.Units(pdf), top, gx.Units(pdf), bottom)
 	}
 
-	// Horizontal lines every 30pt
-	for y := 100.0; y < 220; y += 30 {
-		pdf.Line(50, y, 550, y)
+	// Horizontal lines
+	for gy := y; gy < y+h; gy += stepY {
+		pdf.Line(left, gy.Units(pdf), right, gy.Units(pdf))
 	}
 }
 
@@ -581,84 +781,80 @@ func alignLeft(pdf *gopdf.GoPdf, text string, x, y float64) {
 	pdf.Text(text)
 }
 
-// alignCenter centers text on the page
+// alignCenter centers text on the current page, whatever its size
 func alignCenter(pdf *gopdf.GoPdf, text string, y float64) {
 	textWidth, _ := pdf.MeasureTextWidth(text)
-	pageWidth := gopdf.PageSizeA4.W
+	pageWidth := page.Size(pdf).W
 	centerX := (pageWidth - textWidth) / 2
 	pdf.SetXY(centerX, y)
 	pdf.Text(text)
 }
 
-// alignRight aligns text to the right
+// alignRight aligns text to the right edge of the current page, less
+// rightMargin
 func alignRight(pdf *gopdf.GoPdf, text string, rightMargin, y float64) {
 	textWidth, _ := pdf.MeasureTextWidth(text)
-	pageWidth := gopdf.PageSizeA4.W
+	pageWidth := page.Size(pdf).W
 	rightX := pageWidth - textWidth - rightMargin
 	pdf.SetXY(rightX, y)
 	pdf.Text(text)
 }
 
-// justifyText attempts to justify text by adding space between words
-func justifyText(pdf *gopdf.GoPdf, text string, x, y, width float64) {
-	words := strings.Fields(text)
-	if len(words) <= 1 {
-		pdf.SetXY(x, y)
-		pdf.Text(text)
-		return
-	}
-
-	// Calculate total width of all words
-	totalWordWidth := 0.0
-	for _, word := range words {
-		w, _ := pdf.MeasureTextWidth(word)
-		totalWordWidth += w
-	}
-
-	// Calculate space to distribute between words
-	totalSpaces := float64(len(words) - 1)
-	spaceWidth := (width - totalWordWidth) / totalSpaces
-
-	// Place words with calculated spacing
-	currentX := x
-	for i, word := range words {
-		pdf.SetXY(currentX, y)
-		pdf.Text(word)
-
-		wordWidth, _ := pdf.MeasureTextWidth(word)
-		currentX += wordWidth
-
-		if i < len(words)-1 {
-			currentX += spaceWidth
-		}
+// justifyText justifies a paragraph: every line except the last is
+// stretched to the full width. Line breaks are chosen for the whole
+// paragraph at once (see textlayout.Justify), which keeps the spacing
+// even instead of leaving large gaps on some lines.
+// Like the other layout helpers it takes units.Lengths, so the layout can
+// be given in mm or any other unit whatever the unit of the document, and
+// it returns the y below the paragraph.
+func justifyText(pdf *gopdf.GoPdf, text string, x, y, width, lineHeight units.Length) units.Length {
+	nextY, err := textlayout.JustifyParagraph(pdf, text, x.Units(pdf), y.Units(pdf), textlayout.JustifyOptions{
+		Options: textlayout.Options{Width: width.Units(pdf), LineHeight: lineHeight.Units(pdf)},
+	})
+	if err != nil {
+		log.Println(err)
 	}
+	return units.FromUnits(pdf, nextY)
 }
 
 // wrapTextWithSpacing wraps text with custom line spacing
-func wrapTextWithSpacing(pdf *gopdf.GoPdf, text string, x, y, maxWidth, lineHeight float64) float64 {
-	words := strings.Fields(text)
-	currentY := y
-	currentLine := ""
-
-	for _, word := range words {
-		testLine := currentLine + word + " "
-		textWidth, _ := pdf.MeasureTextWidth(testLine)
-
-		if textWidth > maxWidth && currentLine != "" {
-			pdf.SetXY(x, currentY)
-			pdf.Text(strings.TrimSpace(currentLine))
-			currentY += lineHeight
-			currentLine = word + " "
-		} else {
-			currentLine = testLine
-		}
-	}
+// Newlines start a new line, words longer than maxWidth are split and tabs
+// jump to the next tab stop (see the textlayout package).
+func wrapTextWithSpacing(pdf *gopdf.GoPdf, text string, x, y, maxWidth, lineHeight units.Length) units.Length {
+	return wrapTextWithIndent(pdf, text, x, y, maxWidth, lineHeight, 0, 0)
+}
 
-	if currentLine != "" {
-		pdf.SetXY(x, currentY)
-		pdf.Text(strings.TrimSpace(currentLine))
-		currentY += lineHeight
+// wrapTextHyphenated wraps text like wrapTextWithSpacing, and also breaks
+// words at the end of a line, using the hyphenation patterns of lang
+// ("en", "es", "fr", "de" or "pt")
+func wrapTextHyphenated(pdf *gopdf.GoPdf, text, lang string, x, y, maxWidth, lineHeight units.Length) units.Length {
+	h, err := hyphen.Language(lang)
+	if err != nil {
+		log.Println(err)
+		return wrapTextWithSpacing(pdf, text, x, y, maxWidth, lineHeight)
+	}
+	nextY, err := textlayout.Paragraph(pdf, text, x.Units(pdf), y.Units(pdf), textlayout.Options{
+		Width:      maxWidth.Units(pdf),
+		LineHeight: lineHeight.Units(pdf),
+		Hyphenator: h,
+	})
+	if err != nil {
+		log.Println(err)
 	}
+	return units.FromUnits(pdf, nextY)
+}
 
-	return currentY
+// wrapTextWithIndent wraps text like wrapTextWithSpacing, indenting the
+// first line by firstIndent and the other lines by indent
+func wrapTextWithIndent(pdf *gopdf.GoPdf, text string, x, y, maxWidth, lineHeight, firstIndent, indent units.Length) units.Length {
+	nextY, err := textlayout.Paragraph(pdf, text, x.Units(pdf), y.Units(pdf), textlayout.Options{
+		Width:       maxWidth.Units(pdf),
+		LineHeight:  lineHeight.Units(pdf),
+		FirstIndent: firstIndent.Units(pdf),
+		Indent:      indent.Units(pdf),
+	})
+	if err != nil {
+		log.Println(err)
+	}
+	return units.FromUnits(pdf, nextY)
 }
@@ -0,0 +1,447 @@
+package textlayout
+
+import (
+	"math"
+	"strings"
+	"unicode/utf8"
+)
+
+// JustifyOptions controls Justify. The zero value of every field other than
+// the embedded Options selects the default given in its comment, which are
+// the values TeX uses for interword spaces.
+type JustifyOptions struct {
+	Options
+
+	// Stretch and Shrink are how much an interword space may grow and
+	// shrink, as fractions of its natural width (defaults 1/2 and 1/3).
+	Stretch float64
+	Shrink  float64
+
+	// Tolerance is the largest adjustment ratio a line may have: 1 means
+	// spaces may stretch by up to Stretch times their width (default 2).
+	// When a paragraph cannot be set within the tolerance, it is set again
+	// with no limit, so Justify always succeeds.
+	Tolerance float64
+
+	// LinePenalty is added to the badness of every line; larger values
+	// favor paragraphs with fewer lines (default 10).
+	LinePenalty float64
+
+	// FitnessDemerits is added when a tight line follows a loose one or
+	// the other way round, which keeps spacing even between neighboring
+	// lines (default 100).
+	FitnessDemerits float64
+
+	// HyphenPenalty is the cost of breaking a line at a hyphenation point
+	// found by Options.Hyphenator (default 50). Two hyphenated lines in a
+	// row cost extra.
+	HyphenPenalty float64
+}
+
+func (o *JustifyOptions) setDefaults() {
+	if o.Stretch <= 0 {
+		o.Stretch = 1.0 / 2
+	}
+	if o.Shrink <= 0 {
+		o.Shrink = 1.0 / 3
+	}
+	if o.Tolerance <= 0 {
+		o.Tolerance = 2
+	}
+	if o.LinePenalty <= 0 {
+		o.LinePenalty = 10
+	}
+	if o.FitnessDemerits <= 0 {
+		o.FitnessDemerits = 100
+	}
+	if o.HyphenPenalty <= 0 {
+		o.HyphenPenalty = 50
+	}
+}
+
+// Justify lays out text as justified paragraphs: every line but the last
+// line of each paragraph is stretched or shrunk to exactly opts.Width.
+//
+// Unlike Layout, which fills each line greedily, Justify chooses the breaks
+// of a whole paragraph at once (the Knuth–Plass "total fit" algorithm), so
+// that the spacing is as even as possible across all lines. This avoids the
+// very wide gaps ("rivers") that greedy breaking plus stretching produces.
+//
+// Each word is a Span with its final position, so Draw can draw the result.
+// Tabs count as spaces; spaces are collapsed. Newlines start a new paragraph.
+func Justify(m Measurer, text string, x, y float64, opts JustifyOptions) ([]Line, error) {
+	if opts.Width <= 0 || opts.LineHeight <= 0 {
+		return nil, ErrInvalidOptions
+	}
+	opts.setDefaults()
+
+	var lines []Line
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+	for _, para := range strings.Split(text, "\n") {
+		items, err := paragraphItems(m, para, opts)
+		if err != nil {
+			return nil, err
+		}
+		breaks := totalFit(items, opts, opts.Tolerance)
+		if breaks == nil {
+			breaks = totalFit(items, opts, math.Inf(1))
+		}
+		for _, line := range setLines(items, breaks, opts) {
+			line.X += x
+			line.Y = y + float64(len(lines))*opts.LineHeight
+			for i := range line.Spans {
+				line.Spans[i].X += x
+			}
+			lines = append(lines, line)
+		}
+	}
+	return lines, nil
+}
+
+// JustifyParagraph lays out and draws justified text, and returns the y
+// position one line below the last baseline.
+func JustifyParagraph(d Drawer, text string, x, y float64, opts JustifyOptions) (float64, error) {
+	lines, err := Justify(d, text, x, y, opts)
+	if err != nil {
+		return y, err
+	}
+	if err := Draw(d, lines); err != nil {
+		return y, err
+	}
+	return y + float64(len(lines))*opts.LineHeight, nil
+}
+
+// The paragraph is a list of items, as in TeX: boxes (text), glue (space
+// that can stretch and shrink) and penalties (possible breaks in a word).
+type itemKind int
+
+const (
+	boxItem itemKind = iota
+	glueItem
+	penaltyItem
+)
+
+type item struct {
+	kind                   itemKind
+	text                   string // Box text, or text added at a penalty break ("-")
+	width, stretch, shrink float64
+	penalty                float64 // Cost of breaking at a penalty
+	flagged                bool    // Whether the penalty adds a hyphen
+}
+
+// paragraphItems converts one paragraph into boxes and glue, with a
+// penalty at every hyphenation point. Words wider than a line are split
+// into pieces with a free break between them.
+func paragraphItems(m Measurer, text string, opts JustifyOptions) ([]item, error) {
+	spaceWidth, err := m.MeasureTextWidth(" ")
+	if err != nil {
+		return nil, err
+	}
+	hyphenWidth, err := m.MeasureTextWidth(hyphen)
+	if err != nil {
+		return nil, err
+	}
+	maxWidth := opts.Width - math.Max(opts.FirstIndent, opts.Indent)
+
+	var items []item
+	for _, w := range strings.Fields(text) {
+		if len(items) > 0 {
+			items = append(items, item{
+				kind:    glueItem,
+				width:   spaceWidth,
+				stretch: spaceWidth * opts.Stretch,
+				shrink:  spaceWidth * opts.Shrink,
+			})
+		}
+		var points []int
+		if opts.Hyphenator != nil {
+			points = opts.Hyphenator.Points(w)
+		}
+		last := 0
+		for _, p := range append(points, len(w)) {
+			if last > 0 {
+				items = append(items, item{
+					kind:    penaltyItem,
+					text:    hyphen,
+					width:   hyphenWidth,
+					penalty: opts.HyphenPenalty,
+					flagged: true,
+				})
+			}
+			pieces, err := wordItems(m, w[last:p], maxWidth)
+			if err != nil {
+				return nil, err
+			}
+			items = append(items, pieces...)
+			last = p
+		}
+	}
+	return items, nil
+}
+
+// wordItems returns a box for w, or several boxes separated by zero-cost
+// penalties if w is wider than maxWidth.
+func wordItems(m Measurer, w string, maxWidth float64) ([]item, error) {
+	var items []item
+	l := &layouter{m: m}
+	for {
+		ww, err := m.MeasureTextWidth(w)
+		if err != nil {
+			return nil, err
+		}
+		if ww <= maxWidth || utf8.RuneCountInString(w) < 2 {
+			return append(items, item{kind: boxItem, text: w, width: ww}), nil
+		}
+		head, hw, err := l.splitWord(w, maxWidth)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item{kind: boxItem, text: head, width: hw}, item{kind: penaltyItem})
+		w = w[len(head):]
+	}
+}
+
+// breakNode is a feasible break in the total-fit search.
+type breakNode struct {
+	pos      int // Item index of the break; -1 is the paragraph start
+	fitness  int // Fitness class of the line ending here
+	demerits float64
+	prev     *breakNode
+}
+
+// totalFit finds the breaks (item indexes, the last being len(items)) with
+// the least total demerits, or nil if no set of lines fits tolerance.
+func totalFit(items []item, opts JustifyOptions, tolerance float64) []int {
+	// Prefix sums, so the natural width of any line is a subtraction.
+	n := len(items)
+	sumW := make([]float64, n+1)
+	sumY := make([]float64, n+1)
+	sumZ := make([]float64, n+1)
+	for i, it := range items {
+		sumW[i+1], sumY[i+1], sumZ[i+1] = sumW[i], sumY[i], sumZ[i]
+		if it.kind != penaltyItem {
+			sumW[i+1] += it.width
+		}
+		if it.kind == glueItem {
+			sumY[i+1] += it.stretch
+			sumZ[i+1] += it.shrink
+		}
+	}
+
+	active := []*breakNode{{pos: -1, fitness: 1}}
+	for b := 0; b <= n; b++ {
+		if b < n && !isBreak(items, b) {
+			continue
+		}
+
+		// The best node for each fitness class of the line ending at b.
+		var best [4]*breakNode
+		for _, a := range active {
+			start := lineStart(items, a.pos)
+			avail := opts.Width - opts.Indent
+			if a.pos < 0 {
+				avail = opts.Width - opts.FirstIndent
+			}
+			natural := sumW[b] - sumW[start]
+			if b < n && items[b].kind == penaltyItem {
+				natural += items[b].width
+			}
+			stretch, shrink := sumY[b]-sumY[start], sumZ[b]-sumZ[start]
+
+			r := adjustmentRatio(natural, stretch, shrink, avail, b == n)
+			// Without a tolerance, a single piece wider than the line (one
+			// character in a very narrow column) is allowed to overflow.
+			overfull := r < -1 && !(math.IsInf(tolerance, 1) && b-start == 1)
+			if overfull || r > tolerance {
+				continue
+			}
+			fitness := fitnessClass(r)
+			d := demerits(items, a, b, r, fitness, opts)
+			if best[fitness] == nil || d < best[fitness].demerits {
+				best[fitness] = &breakNode{pos: b, fitness: fitness, demerits: d, prev: a}
+			}
+		}
+
+		// Drop nodes that can no longer start a line: lines from them to
+		// b already overflow even when fully shrunk.
+		kept := active[:0]
+		for _, a := range active {
+			start := lineStart(items, a.pos)
+			if sumW[b]-sumW[start]-(sumZ[b]-sumZ[start]) <= opts.Width || b == n {
+				kept = append(kept, a)
+			}
+		}
+		active = kept
+		for _, node := range best {
+			if node != nil {
+				active = append(active, node)
+			}
+		}
+	}
+
+	var end *breakNode
+	for _, node := range active {
+		if node.pos == n && (end == nil || node.demerits < end.demerits) {
+			end = node
+		}
+	}
+	if end == nil {
+		return nil
+	}
+	var breaks []int
+	for node := end; node.pos >= 0; node = node.prev {
+		breaks = append([]int{node.pos}, breaks...)
+	}
+	return breaks
+}
+
+// isBreak reports whether the paragraph may break at item i: at glue that
+// follows a box, or at a penalty.
+func isBreak(items []item, i int) bool {
+	switch items[i].kind {
+	case glueItem:
+		return i > 0 && items[i-1].kind == boxItem
+	case penaltyItem:
+		return true
+	}
+	return false
+}
+
+// lineStart returns the first item of a line after a break at pos:
+// glue and penalties at the start of a line are dropped.
+func lineStart(items []item, pos int) int {
+	i := pos + 1
+	for i < len(items) && items[i].kind != boxItem {
+		i++
+	}
+	return i
+}
+
+// adjustmentRatio returns how much a line's glue must stretch (r > 0) or
+// shrink (r < 0) to fill avail. The last line of a paragraph is not
+// stretched.
+func adjustmentRatio(natural, stretch, shrink, avail float64, last bool) float64 {
+	switch {
+	case natural < avail && last:
+		return 0
+	case natural < avail && stretch > 0:
+		return (avail - natural) / stretch
+	case natural < avail:
+		return math.Inf(1)
+	case natural > avail && shrink > 0:
+		return (avail - natural) / shrink
+	case natural > avail:
+		return math.Inf(-1)
+	}
+	return 0
+}
+
+// fitnessClass classifies a line as tight (0), decent (1), loose (2) or
+// very loose (3).
+func fitnessClass(r float64) int {
+	switch {
+	case r < -0.5:
+		return 0
+	case r <= 0.5:
+		return 1
+	case r <= 1:
+		return 2
+	}
+	return 3
+}
+
+// demerits returns the total demerits of the paragraph up to a line from
+// node a to break b with adjustment ratio r.
+func demerits(items []item, a *breakNode, b int, r float64, fitness int, opts JustifyOptions) float64 {
+	badness := math.Min(100*math.Pow(math.Abs(r), 3), 10000)
+	d := math.Pow(opts.LinePenalty+badness, 2)
+	if b < len(items) && items[b].kind == penaltyItem {
+		d += items[b].penalty * items[b].penalty
+		if items[b].flagged && a.pos >= 0 && items[a.pos].kind == penaltyItem && items[a.pos].flagged {
+			d += flaggedDemerits
+		}
+	}
+	if a.pos >= 0 && math.Abs(float64(fitness-a.fitness)) > 1 {
+		d += opts.FitnessDemerits
+	}
+	return a.demerits + d
+}
+
+// flaggedDemerits is added for two consecutive lines ending in a hyphen.
+const flaggedDemerits = 3000
+
+// setLines positions the words of each line, relative to the paragraph's
+// left edge, for the given breaks.
+func setLines(items []item, breaks []int, opts JustifyOptions) []Line {
+	var lines []Line
+	prev := -1
+	for i, b := range breaks {
+		start := lineStart(items, prev)
+		avail := opts.Width - opts.Indent
+		indent := opts.Indent
+		if i == 0 {
+			avail = opts.Width - opts.FirstIndent
+			indent = opts.FirstIndent
+		}
+
+		var natural, stretch, shrink float64
+		for _, it := range items[start:b] {
+			if it.kind != penaltyItem {
+				natural += it.width
+			}
+			stretch += it.stretch
+			shrink += it.shrink
+		}
+		if b < len(items) && items[b].kind == penaltyItem {
+			natural += items[b].width
+		}
+		r := adjustmentRatio(natural, stretch, shrink, avail, b == len(items))
+		if math.IsInf(r, 0) {
+			r = 0
+		}
+
+		line := Line{X: indent}
+		x := indent
+		var words []string
+		joined := false // Whether the next box continues the last span
+		for _, it := range items[start:b] {
+			switch it.kind {
+			case boxItem:
+				if joined && len(line.Spans) > 0 {
+					s := &line.Spans[len(line.Spans)-1]
+					s.Text += it.text
+					s.Width += it.width
+					words[len(words)-1] += it.text
+				} else {
+					line.Spans = append(line.Spans, Span{Text: it.text, X: x, Width: it.width})
+					words = append(words, it.text)
+				}
+				x += it.width
+				joined = true
+			case glueItem:
+				if r >= 0 {
+					x += it.width + r*it.stretch
+				} else {
+					x += it.width + r*it.shrink
+				}
+				joined = false
+			}
+		}
+		if b < len(items) && items[b].kind == penaltyItem && items[b].text != "" && len(line.Spans) > 0 {
+			s := &line.Spans[len(line.Spans)-1]
+			s.Text += items[b].text
+			s.Width += items[b].width
+			words[len(words)-1] += items[b].text
+			x += items[b].width
+		}
+
+		line.Text = strings.Join(words, " ")
+		line.Width = x - indent
+		lines = append(lines, line)
+		prev = b
+	}
+	if len(lines) == 0 {
+		lines = append(lines, Line{X: opts.FirstIndent})
+	}
+	return lines
+}
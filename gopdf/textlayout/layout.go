@@ -0,0 +1,384 @@
+// Package textlayout breaks text into lines and draws them with gopdf.
+//
+// Layout only measures: it returns one Line per output line with its text,
+// position and width, so callers can check how much room a paragraph needs
+// before drawing it with Draw. Paragraph does both in one call.
+//
+//	lines, err := textlayout.Layout(&pdf, text, 50, 100, textlayout.Options{
+//		Width:      495,
+//		LineHeight: 15,
+//	})
+//	...
+//	err = textlayout.Draw(&pdf, lines)
+package textlayout
+
+import (
+	"errors"
+	"math"
+	"strings"
+	"unicode/utf8"
+)
+
+// Measurer measures text in the current font. *gopdf.GoPdf and
+// *fonts.Registry implement it.
+type Measurer interface {
+	MeasureTextWidth(text string) (float64, error)
+}
+
+// Drawer draws text with its baseline at the current position.
+// *gopdf.GoPdf and *fonts.Registry implement it.
+type Drawer interface {
+	Measurer
+	SetXY(x, y float64)
+	Text(text string) error
+}
+
+// Hyphenator finds the places where a word may be hyphenated, as byte
+// offsets in the word. *hyphen.Hyphenator implements it.
+type Hyphenator interface {
+	Points(word string) []int
+}
+
+// ErrInvalidOptions is returned for a non-positive Width or LineHeight.
+var ErrInvalidOptions = errors.New("textlayout: width and line height must be positive")
+
+// DefaultTabWidth is the distance between tab stops when Options.TabWidth
+// is zero: half an inch.
+const DefaultTabWidth = 36.0
+
+// Options controls how a paragraph is broken into lines.
+type Options struct {
+	Width      float64 // Maximum line width, including indentation
+	LineHeight float64 // Distance between two baselines
+
+	// FirstIndent is the left indent of the first line of every paragraph
+	// (the text after each newline); Indent is the left indent of the
+	// other lines. A larger Indent gives hanging indentation.
+	FirstIndent float64
+	Indent      float64
+
+	// TabWidth is the distance between tab stops, measured from the
+	// start of the line. Zero means DefaultTabWidth.
+	TabWidth float64
+
+	// Hyphenator, if set, allows words to be broken at the end of a line,
+	// with a hyphen added: see the hyphen package.
+	Hyphenator Hyphenator
+}
+
+// hyphen is the text drawn where a word is hyphenated.
+const hyphen = "-"
+
+// Line is one laid out line of text.
+type Line struct {
+	Text  string  // The line's text, without the spaces it was broken at
+	X     float64 // Left edge, including indentation
+	Y     float64 // Baseline
+	Width float64 // Width of the text
+
+	// Spans are the pieces of the line that are drawn separately: a line
+	// without tabs has one span; every tab starts a new one.
+	Spans []Span
+}
+
+// Span is a run of text within a line.
+type Span struct {
+	Text  string
+	X     float64 // Left edge, relative to the page
+	Width float64
+}
+
+// Layout breaks text into lines no wider than opts.Width, with the first
+// baseline at y. Lines break at spaces, and inside words if opts.Hyphenator
+// is set; a newline always starts a new line and an empty line is kept as an
+// empty Line; a word longer than a whole line is split between characters;
+// a tab moves to the next tab stop.
+// Spaces at the start of a line and at a line break are dropped; use
+// FirstIndent or a tab to indent.
+func Layout(m Measurer, text string, x, y float64, opts Options) ([]Line, error) {
+	if opts.Width <= 0 || opts.LineHeight <= 0 {
+		return nil, ErrInvalidOptions
+	}
+	if opts.TabWidth <= 0 {
+		opts.TabWidth = DefaultTabWidth
+	}
+
+	l := &layouter{m: m, opts: opts, x: x, y: y}
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+	for _, para := range strings.Split(text, "\n") {
+		if err := l.paragraph(para); err != nil {
+			return nil, err
+		}
+	}
+	return l.lines, nil
+}
+
+// Draw draws lines laid out by Layout or Justify.
+func Draw(d Drawer, lines []Line) error {
+	for _, line := range lines {
+		for _, span := range line.Spans {
+			if span.Text == "" {
+				continue
+			}
+			d.SetXY(span.X, line.Y)
+			if err := d.Text(span.Text); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Paragraph lays out and draws text, and returns the y position one line
+// below the last baseline, where the next content can start.
+func Paragraph(d Drawer, text string, x, y float64, opts Options) (float64, error) {
+	lines, err := Layout(d, text, x, y, opts)
+	if err != nil {
+		return y, err
+	}
+	if err := Draw(d, lines); err != nil {
+		return y, err
+	}
+	return y + float64(len(lines))*opts.LineHeight, nil
+}
+
+// token is a word, a run of spaces or a tab.
+type token struct {
+	text string
+	kind tokenKind
+}
+
+type tokenKind int
+
+const (
+	word tokenKind = iota
+	space
+	tab
+)
+
+// tokenize splits one line of text (no newlines) into tokens.
+func tokenize(text string) []token {
+	var tokens []token
+	for len(text) > 0 {
+		r, _ := utf8.DecodeRuneInString(text)
+		var kind tokenKind
+		switch r {
+		case '\t':
+			tokens = append(tokens, token{text: "\t", kind: tab})
+			text = text[1:]
+			continue
+		case ' ':
+			kind = space
+		default:
+			kind = word
+		}
+		end := strings.IndexFunc(text, func(r rune) bool {
+			if kind == space {
+				return r != ' '
+			}
+			return r == ' ' || r == '\t'
+		})
+		if end < 0 {
+			end = len(text)
+		}
+		tokens = append(tokens, token{text: text[:end], kind: kind})
+		text = text[end:]
+	}
+	return tokens
+}
+
+// layouter holds the state of a greedy Layout.
+type layouter struct {
+	m     Measurer
+	opts  Options
+	x, y  float64
+	lines []Line
+
+	spans   []Span  // Spans of the line being built; X relative to the line start
+	text    string  // Text of the line being built
+	cur     float64 // Position after the last word, relative to the line start
+	pending string  // Spaces seen after the last word, placed only if a word follows
+	started bool    // Whether the current paragraph has produced a line yet
+}
+
+// indent returns the indentation of the line being built.
+func (l *layouter) indent() float64 {
+	if !l.started {
+		return l.opts.FirstIndent
+	}
+	return l.opts.Indent
+}
+
+// avail returns the width available for text on the line being built.
+func (l *layouter) avail() float64 {
+	return l.opts.Width - l.indent()
+}
+
+func (l *layouter) paragraph(text string) error {
+	l.started = false
+	l.reset()
+
+	for _, tok := range tokenize(text) {
+		switch tok.kind {
+		case space:
+			l.pending += tok.text
+		case tab:
+			stop := (math.Floor(l.cur/l.opts.TabWidth) + 1) * l.opts.TabWidth
+			if stop > l.avail() && l.text != "" {
+				l.breakLine()
+				continue
+			}
+			l.pending = ""
+			l.cur = math.Min(stop, l.avail())
+			l.spans = append(l.spans, Span{X: l.cur})
+			l.text += "\t"
+		case word:
+			if err := l.word(tok.text); err != nil {
+				return err
+			}
+		}
+	}
+	l.breakLine()
+	return nil
+}
+
+// word places a word on the current line. If the word does not fit, it is
+// hyphenated if possible, or moved to the next line; a word wider than a
+// whole line is split between characters.
+func (l *layouter) word(w string) error {
+	for {
+		ww, err := l.m.MeasureTextWidth(w)
+		if err != nil {
+			return err
+		}
+		sw := 0.0
+		if l.pending != "" && l.spanHasText() {
+			if sw, err = l.m.MeasureTextWidth(l.pending); err != nil {
+				return err
+			}
+		}
+		if l.cur+sw+ww <= l.avail() {
+			l.appendText(w, ww, sw)
+			return nil
+		}
+
+		head, hw, n, err := l.hyphenate(w, l.avail()-l.cur-sw)
+		if err != nil {
+			return err
+		}
+		switch {
+		case n > 0:
+			l.appendText(head, hw, sw)
+		case l.hasWords():
+			l.breakLine()
+			continue
+		case utf8.RuneCountInString(w) > 1:
+			// The word is wider than the line: place as much as fits.
+			if head, hw, err = l.splitWord(w, l.avail()-l.cur); err != nil {
+				return err
+			}
+			n = len(head)
+			l.appendText(head, hw, 0)
+		default:
+			l.appendText(w, ww, sw)
+			return nil
+		}
+		l.breakLine()
+		w = w[n:]
+	}
+}
+
+// hyphenate returns the longest hyphenated start of w, hyphen included,
+// that fits in width, its width and the number of bytes of w it holds.
+// n is zero if w cannot be hyphenated to fit.
+func (l *layouter) hyphenate(w string, width float64) (head string, hw float64, n int, err error) {
+	if l.opts.Hyphenator == nil {
+		return "", 0, 0, nil
+	}
+	points := l.opts.Hyphenator.Points(w)
+	for i := len(points) - 1; i >= 0; i-- {
+		head = w[:points[i]] + hyphen
+		if hw, err = l.m.MeasureTextWidth(head); err != nil {
+			return "", 0, 0, err
+		}
+		if hw <= width {
+			return head, hw, points[i], nil
+		}
+	}
+	return "", 0, 0, nil
+}
+
+// hasWords reports whether the line being built has any text.
+func (l *layouter) hasWords() bool {
+	return strings.Trim(l.text, "\t") != ""
+}
+
+// spanHasText reports whether the last span has text, i.e. whether pending
+// spaces would be drawn between two words.
+func (l *layouter) spanHasText() bool {
+	return len(l.spans) > 0 && l.spans[len(l.spans)-1].Text != ""
+}
+
+// appendText appends the pending spaces (of width sw) and text (of width
+// tw) to the last span.
+func (l *layouter) appendText(text string, tw, sw float64) {
+	if len(l.spans) == 0 {
+		l.spans = append(l.spans, Span{X: l.cur})
+	}
+	s := &l.spans[len(l.spans)-1]
+	if s.Text != "" {
+		s.Text += l.pending
+		s.Width += sw
+		l.text += l.pending
+	}
+	l.pending = ""
+	s.Text += text
+	s.Width += tw
+	l.text += text
+	l.cur = s.X + s.Width
+}
+
+// splitWord returns the longest prefix of w (at least one character) that
+// fits in width, and its width.
+func (l *layouter) splitWord(w string, width float64) (string, float64, error) {
+	runes := []rune(w)
+	lo, hi := 1, len(runes)-1 // The answer is in [1, len-1]
+	for lo < hi {
+		mid := (lo + hi + 1) / 2
+		mw, err := l.m.MeasureTextWidth(string(runes[:mid]))
+		if err != nil {
+			return "", 0, err
+		}
+		if mw <= width {
+			lo = mid
+		} else {
+			hi = mid - 1
+		}
+	}
+	head := string(runes[:lo])
+	hw, err := l.m.MeasureTextWidth(head)
+	return head, hw, err
+}
+
+// breakLine finishes the line being built.
+func (l *layouter) breakLine() {
+	line := Line{
+		Text:  l.text,
+		X:     l.x + l.indent(),
+		Y:     l.y + float64(len(l.lines))*l.opts.LineHeight,
+		Width: l.cur,
+	}
+	for _, s := range l.spans {
+		s.X += line.X
+		line.Spans = append(line.Spans, s)
+	}
+	l.lines = append(l.lines, line)
+
+	l.started = true
+	l.reset()
+}
+
+// reset clears the line being built.
+func (l *layouter) reset() {
+	l.spans, l.text, l.cur, l.pending = nil, "", 0, ""
+}
@@ -0,0 +1,142 @@
+// Package units is a length type for layouts specified in millimetres,
+// centimetres, inches, points or pixels.
+//
+// A Length is a number of points, and the unit constants are Lengths, so
+// lengths are written like time.Durations:
+//
+//	margin := 20 * units.Mm
+//	width := 210*units.Mm - 2*margin
+//	fmt.Println(width.Millimeters()) // 170
+//
+// gopdf's drawing functions take numbers in the unit of the document
+// (Config.Unit). Units converts a Length to it, so the same layout code
+// works for documents in points, millimetres or any other unit:
+//
+//	pdf.SetXY(margin.Units(pdf), (30 * units.Mm).Units(pdf))
+//
+// Lengths can also be parsed from text such as "12.5mm" or "1in", for
+// command line flags and config files.
+package units
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/signintech/gopdf"
+)
+
+// ErrInvalidLength is returned by Parse for text that is not a number
+// followed by a known unit.
+var ErrInvalidLength = errors.New("units: invalid length")
+
+// Length is a distance in points (1/72 inch).
+type Length float64
+
+// The units Parse accepts. A pixel is 1/96 inch, as in CSS.
+const (
+	Pt Length = 1
+	Mm Length = 72 / 25.4
+	Cm Length = 10 * Mm
+	In Length = 72
+	Px Length = 72.0 / 96
+)
+
+// names maps the unit suffixes Parse accepts to their units.
+var names = map[string]Length{
+	"pt": Pt, "mm": Mm, "cm": Cm, "in": In, "px": Px, `"`: In,
+}
+
+// Unit returns the unit called name: "pt", "mm", "cm", "in" or "px", or
+// `"` for inches.
+func Unit(name string) (Length, bool) {
+	u, ok := names[strings.ToLower(strings.TrimSpace(name))]
+	return u, ok
+}
+
+// Parse reads a length: a number followed by a unit, e.g. "12.5mm",
+// "1 in", "0.5cm" or "300px". A number without a unit is in points.
+func Parse(text string) (Length, error) {
+	s := strings.TrimSpace(text)
+	i := strings.LastIndexFunc(s, func(r rune) bool {
+		return r >= '0' && r <= '9' || r == '.'
+	}) + 1
+	unit := Pt
+	if suffix := s[i:]; strings.TrimSpace(suffix) != "" {
+		var ok bool
+		if unit, ok = Unit(suffix); !ok {
+			return 0, fmt.Errorf("%w: %q: unknown unit", ErrInvalidLength, text)
+		}
+	}
+	v, err := strconv.ParseFloat(s[:i], 64)
+	if err != nil {
+		return 0, fmt.Errorf("%w: %q", ErrInvalidLength, text)
+	}
+	return Length(v) * unit, nil
+}
+
+// MustParse is like Parse but panics on an error. It is meant for lengths
+// written in the program, not for user input.
+func MustParse(text string) Length {
+	l, err := Parse(text)
+	if err != nil {
+		panic(err)
+	}
+	return l
+}
+
+// Points returns l in points.
+func (l Length) Points() float64 { return float64(l) }
+
+// Millimeters returns l in millimetres.
+func (l Length) Millimeters() float64 { return l.In(Mm) }
+
+// Centimeters returns l in centimetres.
+func (l Length) Centimeters() float64 { return l.In(Cm) }
+
+// Inches returns l in inches.
+func (l Length) Inches() float64 { return l.In(In) }
+
+// Pixels returns l in CSS pixels (1/96 inch).
+func (l Length) Pixels() float64 { return l.In(Px) }
+
+// In returns l as a number of unit, e.g. l.In(units.Mm).
+func (l Length) In(unit Length) float64 { return float64(l / unit) }
+
+// Units returns l in the unit of the document (Config.Unit), the unit of
+// the coordinates gopdf's drawing functions take.
+func (l Length) Units(pdf *gopdf.GoPdf) float64 {
+	return pdf.PointsToUnits(float64(l))
+}
+
+// FromUnits returns the Length of v, a number in the unit of the document,
+// e.g. a width returned by MeasureTextWidth.
+func FromUnits(pdf *gopdf.GoPdf, v float64) Length {
+	return Length(pdf.UnitsToPoints(v))
+}
+
+// String returns l in points, e.g. "12.5pt", in a form Parse reads back.
+func (l Length) String() string {
+	return strconv.FormatFloat(float64(l), 'f', -1, 64) + "pt"
+}
+
+// Set parses text into l, for flag.Var.
+func (l *Length) Set(text string) error {
+	v, err := Parse(text)
+	if err != nil {
+		return err
+	}
+	*l = v
+	return nil
+}
+
+// UnmarshalText parses text into l, for encoding/json and other decoders.
+func (l *Length) UnmarshalText(text []byte) error {
+	return l.Set(string(text))
+}
+
+// MarshalText returns l.String().
+func (l Length) MarshalText() ([]byte, error) {
+	return []byte(l.String()), nil
+}